@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Mascol9/fuffa/pkg/output"
+)
+
+// runDiff implements the diff subcommand, comparing the results of two output files
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	outputFile := fs.String("o", "", "Write the diff to file")
+	outputFormat := fs.String("of", "json", "Diff output file format. Available formats: json, html, md")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: fuffa diff [-o file] [-of json|html|md] OLD NEW\n\n")
+		fmt.Fprintf(os.Stderr, "Compares the results of two json, ejson or jsonl output files by URL and input values.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+	oldResults, err := output.LoadResultsFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	newResults, err := output.LoadResultsFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	report := output.DiffResults(fs.Arg(0), oldResults, newResults)
+	fmt.Print(report.String())
+	if *outputFile != "" {
+		if err = output.WriteDiff(*outputFile, *outputFormat, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -54,14 +54,14 @@ func Usage() {
 		Description:   "Options controlling the HTTP request and its parts.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"cc", "ck", "H", "X", "b", "d", "r", "u", "raw", "recursion", "recursion-depth", "recursion-strategy", "replay-proxy", "timeout", "ignore-body", "x", "sni", "http2"},
+		ExpectedFlags: []string{"cc", "ck", "correlation-header", "correlation-param", "crawl", "crawl-match", "crawl-mode", "H", "X", "b", "d", "r", "u", "oob-dns", "oob-domain", "oob-http", "oob-host", "oob-wait", "preflight", "preflight-extract", "raw", "recursion", "recursion-depth", "recursion-strategy", "replay-proxy", "session", "sign-aws", "sign-hmac", "sign-hmac-header", "sign-hmac-key", "sign-hmac-template", "timeout", "ignore-body", "x", "sni", "http2"},
 	}
 	u_general := UsageSection{
 		Name:          "GENERAL OPTIONS",
 		Description:   "",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"ac", "acc", "ack", "ach", "acs", "aiuto", "c", "config", "debug-req", "json", "maxtime", "maxtime-job", "noninteractive", "p", "rate", "scraperfile", "scrapers", "search", "s", "sa", "se", "sf", "t", "v", "V"},
+		ExpectedFlags: []string{"ac", "acc", "ack", "ach", "acs", "aiuto", "c", "config", "debug-req", "fmt", "history-max-age", "history-max-entries", "json", "maxtime", "maxtime-job", "noninteractive", "p", "rate", "scraperfile", "scrapers", "search", "s", "sa", "se", "sf", "t", "v", "V"},
 	}
 	u_compat := UsageSection{
 		Name:          "COMPATIBILITY OPTIONS",
@@ -89,14 +89,14 @@ func Usage() {
 		Description:   "Options for input data for fuzzing. Wordlists and input generators.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"D", "enc", "ic", "input-cmd", "input-num", "input-shell", "l", "mode", "request", "request-proto", "S", "vhost", "vhost-domain", "e", "w"},
+		ExpectedFlags: []string{"D", "enc", "ic", "input-cmd", "input-num", "input-shell", "l", "mode", "request", "request-proto", "S", "seed", "vhost", "vhost-domain", "e", "w"},
 	}
 	u_output := UsageSection{
 		Name:          "OUTPUT OPTIONS",
 		Description:   "Options for output. Output file formats, file names and debug file locations.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"audit-log", "debug-log", "o", "of", "od", "or"},
+		ExpectedFlags: []string{"audit-log", "audit-compress", "audit-format", "audit-max-age", "audit-max-size", "baseline", "debug-log", "o", "of", "od", "or", "redact", "redact-field", "redact-header", "redact-regex", "sink", "webhook", "webhook-batch", "webhook-retries", "webhook-severity", "webhook-template"},
 	}
 	sections := []UsageSection{u_http, u_general, u_compat, u_matcher, u_filter, u_input, u_output}
 
@@ -147,6 +147,36 @@ func Usage() {
 	fmt.Printf("  Fuzz multiple locations. Match only responses reflecting the value of \"VAL\" keyword. Colored.\n")
 	fmt.Printf("    fuffa -w params.txt:PARAM -w values.txt:VAL -u https://example.org/?PARAM=VAL -mr \"VAL\" -c\n\n")
 
+	fmt.Printf("  Catch the blind SSRF callbacks of the url parameter with a local out-of-band HTTP listener.\n")
+	fmt.Printf("    fuffa -w params.txt -u 'https://example.org/?FUZZ={{OOB_URL}}' -oob-http 0.0.0.0:8088 -oob-host 203.0.113.7:8088\n\n")
+
+	fmt.Printf("  Fuzz as a logged in user, running the login requests of session.json again when the session expires.\n")
+	fmt.Printf("    fuffa -w wordlist.txt -u https://example.org/account/FUZZ -session session.json\n\n")
+
+	fmt.Printf("  Fuzz a login form that needs a fresh anti-CSRF token for each request.\n")
+	fmt.Printf("    fuffa -w passwords.txt -u https://example.org/login -X POST -d 'csrf=CSRFTOKEN&user=admin&pass=FUZZ' \\\n")
+	fmt.Printf("      -preflight form.txt -preflight-extract 'name=\"csrf\" value=\"([^\"]+)\"'\n\n")
+
+	fmt.Printf("  Fuzz an API Gateway endpoint, signing every request with AWS SigV4 and the credentials of the environment.\n")
+	fmt.Printf("    fuffa -w wordlist.txt -u https://abc123.execute-api.us-east-1.amazonaws.com/prod/FUZZ -sign-aws us-east-1/execute-api\n\n")
+
+	fmt.Printf("  Tag every request with its FUFFAHASH, and find a request back from a line of the server logs.\n")
+	fmt.Printf("    fuffa -w wordlist.txt -u https://example.org/FUZZ -correlation-header X-Fuffa-Id\n")
+	fmt.Printf("    fuffa -search 'X-Fuffa-Id: v2...'\n\n")
+
+	fmt.Printf("  Compare the results of two scans, reporting new, gone and changed results.\n")
+	fmt.Printf("    fuffa diff old.json new.json\n\n")
+
+	fmt.Printf("  Print the stored request and response of a result saved with -od.\n")
+	fmt.Printf("    fuffa show -od results/ 1b2c301\n\n")
+
+	fmt.Printf("  Browse the past scans by hit count, and run one of them again with another matcher.\n")
+	fmt.Printf("    fuffa history list -sort hits\n")
+	fmt.Printf("    fuffa history rerun 7d10def72b50 -mc 200,301\n\n")
+
+	fmt.Printf("  Re-evaluate the matchers and filters of a scan offline, serving the responses from its JSON audit log.\n")
+	fmt.Printf("    fuffa replay-audit audit.jsonl -mc all -fs 4242 -o results.json\n\n")
+
 	fmt.Printf("  More information and examples: https://github.com/ffuf/ffuf\n\n")
 }
 
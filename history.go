@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// historyUsage prints the usage of the history subcommands
+func historyUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: fuffa history COMMAND [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  list                 List the past jobs by date, URL and hit count\n")
+	fmt.Fprintf(os.Stderr, "  show HASH            Print the options, summary and results of a past job\n")
+	fmt.Fprintf(os.Stderr, "  rerun HASH [options] Run a past job again with the same options, overridden by the given ones\n")
+	fmt.Fprintf(os.Stderr, "  prune                Remove the past jobs over the retention limits\n\n")
+	fmt.Fprintf(os.Stderr, "The HASH is the hash of the history entry or a FUFFAHASH of one of its requests.\n")
+}
+
+// runHistory implements the history subcommands other than rerun, which is handled by main
+func runHistory(args []string) int {
+	if len(args) == 0 {
+		historyUsage()
+		return 1
+	}
+	switch args[0] {
+	case "list":
+		return runHistoryList(args[1:])
+	case "show":
+		return runHistoryShow(args[1:])
+	case "prune":
+		return runHistoryPrune(args[1:])
+	}
+	historyUsage()
+	return 1
+}
+
+func runHistoryList(args []string) int {
+	fs := flag.NewFlagSet("history list", flag.ExitOnError)
+	urlFilter := fs.String("url", "", "Show only the jobs whose URL contains this string")
+	sortBy := fs.String("sort", "date", "Sort the jobs by date, url or hits")
+	limit := fs.Int("n", 0, "Show only this number of jobs, 0 for all")
+	jsonOutput := fs.Bool("json", false, "Print the history entries as JSON")
+	fs.Parse(args)
+
+	entries, err := ffuf.ListHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	filtered := make([]ffuf.HistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry.Url(), *urlFilter) {
+			filtered = append(filtered, entry)
+		}
+	}
+	switch *sortBy {
+	case "date":
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Time().After(filtered[j].Time()) })
+	case "url":
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Url() < filtered[j].Url() })
+	case "hits":
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Matches() > filtered[j].Matches() })
+	default:
+		fmt.Fprintf(os.Stderr, "Encountered error(s): unknown sort order %s, use date, url or hits\n", *sortBy)
+		return 1
+	}
+	if *limit > 0 && len(filtered) > *limit {
+		filtered = filtered[:*limit]
+	}
+	if *jsonOutput {
+		for _, entry := range filtered {
+			line, _ := json.Marshal(entry)
+			fmt.Println(string(line))
+		}
+		return 0
+	}
+	fmt.Printf("%-12s  %-19s  %-9s  %8s  %6s  %s\n", "HASH", "DATE", "STATUS", "REQUESTS", "HITS", "URL")
+	for _, entry := range filtered {
+		requests := "-"
+		if entry.Job != nil {
+			requests = fmt.Sprintf("%d", entry.Job.Requests)
+		}
+		fmt.Printf("%-12s  %-19s  %-9s  %8s  %6d  %s\n", shortHash(entry.Hash), entry.Time().Format("2006-01-02 15:04:05"), historyStatus(entry), requests, entry.Matches(), entry.Url())
+	}
+	return 0
+}
+
+// shortHash returns the abbreviated hash of a history entry
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// historyStatus returns how the job of the history entry ended
+func historyStatus(entry ffuf.HistoryEntry) string {
+	if entry.Job == nil {
+		return "unknown"
+	}
+	if entry.Job.Aborted {
+		return "aborted"
+	}
+	return "finished"
+}
+
+// findHistoryEntry returns the single history entry of the hash or FUFFAHASH, printing an error otherwise
+func findHistoryEntry(hash string) (ffuf.HistoryEntry, bool) {
+	if strings.HasPrefix(hash, ffuf.FUFFAHASH_PREFIX) || (len(hash) > 5 && len(hash) < 12) {
+		// a FUFFAHASH starts with the beginning of the entry hash
+		fuffahash, err := ffuf.ParseFuffaHash(hash)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return ffuf.HistoryEntry{}, false
+		}
+		hash = fuffahash.Job
+	}
+	entries, err := ffuf.FindHistory(hash)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return ffuf.HistoryEntry{}, false
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No history entry found for %s\n", hash)
+		return ffuf.HistoryEntry{}, false
+	}
+	if len(entries) > 1 {
+		fmt.Fprintf(os.Stderr, "The hash %s is ambiguous, it matches the history entries:\n", hash)
+		for _, entry := range entries {
+			fmt.Fprintf(os.Stderr, "  %s  %s  %s\n", shortHash(entry.Hash), entry.Time().Format("2006-01-02 15:04:05"), entry.Url())
+		}
+		return ffuf.HistoryEntry{}, false
+	}
+	return entries[0], true
+}
+
+func runHistoryShow(args []string) int {
+	fs := flag.NewFlagSet("history show", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the history entry and its results as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: fuffa history show [-json] HASH\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+	entry, ok := findHistoryEntry(fs.Arg(0))
+	if !ok {
+		return 1
+	}
+	results, err := ffuf.HistoryResults(entry.Hash)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if *jsonOutput {
+		line, _ := json.Marshal(struct {
+			ffuf.HistoryEntry
+			Results []ffuf.HistoryResult `json:"results"`
+		}{entry, results})
+		fmt.Println(string(line))
+		return 0
+	}
+	opts := entry.Options
+	fmt.Printf("Hash:      %s\n", entry.Hash)
+	fmt.Printf("Date:      %s\n", entry.Time().Format("2006-01-02 15:04:05"))
+	fmt.Printf("URL:       %s %s\n", opts.HTTP.Method, entry.Url())
+	for _, w := range opts.Input.Wordlists {
+		fmt.Printf("Wordlist:  %s\n", w)
+	}
+	if opts.Input.InputMode != "" {
+		fmt.Printf("Mode:      %s\n", opts.Input.InputMode)
+	}
+	fmt.Printf("Status:    %s\n", historyStatus(entry))
+	if entry.Job != nil {
+		fmt.Printf("Duration:  %s\n", entry.Job.Finished.Sub(entry.Job.Started).Round(time.Second))
+		fmt.Printf("Requests:  %d, Errors: %d, Hits: %d\n", entry.Job.Requests, entry.Job.Errors, entry.Job.Matches)
+		if entry.Job.Reason != "" {
+			fmt.Printf("Reason:    %s\n", entry.Job.Reason)
+		}
+	}
+	if len(results) > 0 {
+		fmt.Printf("\n%-6s  %8s  %6s  %6s  %-12s  %s\n", "STATUS", "SIZE", "WORDS", "LINES", "FUFFAHASH", "URL")
+	}
+	for _, res := range results {
+		fmt.Printf("%-6d  %8d  %6d  %6d  %-12s  %s\n", res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.FuffaHash, res.Url)
+	}
+	return 0
+}
+
+func runHistoryPrune(args []string) int {
+	// the retention limits default to the ones of the default config file
+	defaults, _ := ffuf.ReadDefaultConfig()
+	fs := flag.NewFlagSet("history prune", flag.ExitOnError)
+	maxAge := fs.String("max-age", defaults.General.HistoryMaxAge, "Remove the entries older than this duration, eg. 720h")
+	maxEntries := fs.Int("max-entries", defaults.General.HistoryMaxEntries, "Keep only this number of the most recent entries")
+	fs.Parse(args)
+
+	var age time.Duration
+	if *maxAge != "" {
+		var err error
+		age, err = time.ParseDuration(*maxAge)
+		if err != nil || age <= 0 {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): -max-age must be a positive duration like 720h: %s\n", *maxAge)
+			return 1
+		}
+	}
+	if age == 0 && *maxEntries <= 0 {
+		fmt.Fprintf(os.Stderr, "No retention limit set, use -max-age or -max-entries\n")
+		return 1
+	}
+	removed, err := ffuf.PruneHistory(age, *maxEntries)
+	for _, entry := range removed {
+		fmt.Printf("Removed %s  %s  %s\n", shortHash(entry.Hash), entry.Time().Format("2006-01-02 15:04:05"), entry.Url())
+	}
+	fmt.Printf("Removed %d history entries\n", len(removed))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// historyRerunOptions returns the options of the history entry to run again, and the remaining arguments
+func historyRerunOptions(args []string) (*ffuf.ConfigOptions, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return nil, nil, fmt.Errorf("usage: fuffa history rerun HASH [options]")
+	}
+	entry, ok := findHistoryEntry(args[0])
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find the history entry %s", args[0])
+	}
+	opts := entry.Options.ConfigOptions
+	// the redacted secrets would be sent to the target as such
+	if redacted := redactedOptions(&opts); len(redacted) > 0 {
+		return nil, nil, fmt.Errorf("the history entry %s was written with redaction and can not be run again, the options %s have %s values", args[0], strings.Join(redacted, ", "), ffuf.REDACTED)
+	}
+	return &opts, args[1:], nil
+}
+
+// redactedOptions returns the names of the options with redacted values, like "http.headers"
+func redactedOptions(opts *ffuf.ConfigOptions) []string {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return []string{}
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{}
+	}
+	redacted := make([]string, 0)
+	var walk func(name string, v interface{})
+	walk = func(name string, v interface{}) {
+		switch d := v.(type) {
+		case map[string]interface{}:
+			for k, child := range d {
+				if name != "" {
+					k = name + "." + k
+				}
+				walk(k, child)
+			}
+		case []interface{}:
+			for _, child := range d {
+				walk(name, child)
+			}
+		case string:
+			if strings.Contains(d, ffuf.REDACTED) {
+				redacted = append(redacted, name)
+			}
+		}
+	}
+	walk("", doc)
+	sort.Strings(redacted)
+	unique := make([]string, 0, len(redacted))
+	for i, name := range redacted {
+		if i == 0 || redacted[i-1] != name {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
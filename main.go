@@ -10,13 +10,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Mascol9/fuffa/pkg/crawler"
 	"github.com/Mascol9/fuffa/pkg/ffuf"
 	"github.com/Mascol9/fuffa/pkg/filter"
 	"github.com/Mascol9/fuffa/pkg/input"
 	"github.com/Mascol9/fuffa/pkg/interactive"
+	"github.com/Mascol9/fuffa/pkg/oob"
 	"github.com/Mascol9/fuffa/pkg/output"
 	"github.com/Mascol9/fuffa/pkg/runner"
 	"github.com/Mascol9/fuffa/pkg/scraper"
+	"github.com/Mascol9/fuffa/pkg/session"
+	"github.com/Mascol9/fuffa/pkg/signing"
 )
 
 type multiStringFlag []string
@@ -53,10 +57,15 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 
 
 
-	var cookies, autocalibrationstrings, autocalibrationstrategies, headers, inputcommands multiStringFlag
+	var cookies, autocalibrationstrings, autocalibrationstrategies, headers, inputcommands, sinks multiStringFlag
+	var redactheaders, redactregexes, redactfields multiStringFlag
 	var wordlists, encoders wordlistFlag
 
 	cookies = opts.HTTP.Cookies
+	sinks = opts.Output.OutputSinks
+	redactheaders = opts.Output.RedactHeaders
+	redactregexes = opts.Output.RedactRegexes
+	redactfields = opts.Output.RedactFields
 	autocalibrationstrings = opts.General.AutoCalibrationStrings
 	headers = opts.HTTP.Headers
 	inputcommands = opts.Input.Inputcommands
@@ -80,6 +89,8 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.BoolVar(&opts.General.StopOnAll, "sa", opts.General.StopOnAll, "Stop on all error cases. Implies -sf and -se.")
 	flag.BoolVar(&opts.General.StopOnErrors, "se", opts.General.StopOnErrors, "Stop on spurious errors")
 	flag.BoolVar(&opts.General.Verbose, "v", opts.General.Verbose, "Verbose output, printing full URL and redirect location (if any) with the results.")
+	flag.BoolVar(&opts.HTTP.Crawl, "crawl", opts.HTTP.Crawl, "Crawl the target for links before fuzzing. URL (-u) has to end in FUZZ keyword.")
+	flag.BoolVar(&opts.HTTP.CrawlOnMatch, "crawl-match", opts.HTTP.CrawlOnMatch, "Extract links from matched responses. URL (-u) has to end in FUZZ keyword.")
 	flag.BoolVar(&opts.HTTP.FollowRedirects, "r", opts.HTTP.FollowRedirects, "Follow redirects")
 	flag.BoolVar(&opts.HTTP.IgnoreBody, "ignore-body", opts.HTTP.IgnoreBody, "Do not fetch the response content.")
 	flag.BoolVar(&opts.HTTP.Raw, "raw", opts.HTTP.Raw, "Do not encode URI")
@@ -87,8 +98,11 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.BoolVar(&opts.HTTP.Http2, "http2", opts.HTTP.Http2, "Use HTTP2 protocol")
 	flag.BoolVar(&opts.Input.DirSearchCompat, "D", opts.Input.DirSearchCompat, "DirSearch wordlist compatibility mode. Used in conjunction with -e flag.")
 	flag.BoolVar(&opts.Input.IgnoreWordlistComments, "ic", opts.Input.IgnoreWordlistComments, "Ignore wordlist comments")
+	flag.BoolVar(&opts.Input.Seed, "seed", opts.Input.Seed, "Prepend the paths found from robots.txt, sitemaps and well-known files to FUZZ input. URL (-u) has to end in FUZZ keyword.")
 	flag.IntVar(&opts.General.MaxTime, "maxtime", opts.General.MaxTime, "Maximum running time in seconds for entire process.")
 	flag.IntVar(&opts.General.MaxTimeJob, "maxtime-job", opts.General.MaxTimeJob, "Maximum running time in seconds per job.")
+	flag.StringVar(&opts.General.HistoryMaxAge, "history-max-age", opts.General.HistoryMaxAge, "Remove the scan history entries older than this duration, eg. 720h")
+	flag.IntVar(&opts.General.HistoryMaxEntries, "history-max-entries", opts.General.HistoryMaxEntries, "Keep only this number of the most recent scan history entries")
 	flag.IntVar(&opts.General.Rate, "rate", opts.General.Rate, "Rate of requests per second")
 	flag.IntVar(&opts.General.Threads, "t", opts.General.Threads, "Number of concurrent threads.")
 	flag.IntVar(&opts.HTTP.RecursionDepth, "recursion-depth", opts.HTTP.RecursionDepth, "Maximum recursion depth.")
@@ -102,6 +116,7 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.StringVar(&opts.HTTP.ClientCert, "cc", "", "Client cert for authentication. Client key needs to be defined as well for this to work")
 	flag.StringVar(&opts.HTTP.ClientKey, "ck", "", "Client key for authentication. Client certificate needs to be defined as well for this to work")
 	flag.StringVar(&opts.General.ConfigFile, "config", "", "Load configuration from a file")
+	flag.StringVar(&opts.General.ResultFormat, "fmt", opts.General.ResultFormat, "Custom single-line result output, eg. '{status} {size} {url} {input.FUZZ}'")
 	flag.StringVar(&opts.General.ScraperFile, "scraperfile", "", "Custom scraper file path")
 	flag.StringVar(&opts.General.Scrapers, "scrapers", opts.General.Scrapers, "Active scraper groups")
 	flag.StringVar(&opts.Filter.Mode, "fmode", opts.Filter.Mode, "Filter set operator. Either of: and, or")
@@ -112,14 +127,30 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.StringVar(&opts.Filter.Time, "ft", opts.Filter.Time, "Filter by number of milliseconds to the first response byte, either greater or less than. EG: >100 or <100")
 	flag.StringVar(&opts.Filter.Words, "fw", opts.Filter.Words, "Filter by amount of words in response. Comma separated list of word counts and ranges")
 	flag.StringVar(&opts.General.Delay, "p", opts.General.Delay, "Seconds of `delay` between requests, or a range of random delay. For example \"0.1\" or \"0.1-2.0\"")
-	flag.StringVar(&opts.General.Searchhash, "search", opts.General.Searchhash, "Search for a FUFFAHASH payload from fuffa history")
+	flag.StringVar(&opts.General.Searchhash, "search", opts.General.Searchhash, "Search for a FUFFAHASH payload from fuffa history, or for the one in a server log line or URL")
+	flag.StringVar(&opts.HTTP.CorrelationHeader, "correlation-header", opts.HTTP.CorrelationHeader, "Add the FUFFAHASH of each request to this header, eg. X-Fuffa-Id, to match the requests with the server logs")
+	flag.StringVar(&opts.HTTP.CorrelationParam, "correlation-param", opts.HTTP.CorrelationParam, "Add the FUFFAHASH of each request to this query parameter, to match the requests with the server logs")
 	flag.StringVar(&opts.HTTP.Data, "d", opts.HTTP.Data, "POST data")
 	flag.StringVar(&opts.HTTP.Data, "data", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Data, "data-ascii", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Data, "data-binary", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Method, "X", opts.HTTP.Method, "HTTP method to use")
 	flag.StringVar(&opts.HTTP.ProxyURL, "x", opts.HTTP.ProxyURL, "Proxy URL (SOCKS5 or HTTP). For example: http://127.0.0.1:8080 or socks5://127.0.0.1:8080")
+	flag.StringVar(&opts.HTTP.Preflight, "preflight", opts.HTTP.Preflight, "Raw request `FILE` sent before each request to fetch a fresh token for the CSRFTOKEN keyword, with a cookie jar per thread")
+	flag.StringVar(&opts.HTTP.PreflightExtract, "preflight-extract", opts.HTTP.PreflightExtract, "Rule extracting the CSRFTOKEN from the pre-flight response: a regexp, or regexp:, jsonpath:, header: or cookie: followed by the rule")
+	flag.StringVar(&opts.HTTP.Session, "session", opts.HTTP.Session, "Session `FILE` with a login macro of raw request files, run before the scan and again when the session expires")
+	flag.StringVar(&opts.HTTP.SignAWS, "sign-aws", opts.HTTP.SignAWS, "Sign the requests with AWS SigV4 for the `REGION/SERVICE`, eg. us-east-1/execute-api, with the credentials of the AWS_* environment variables")
+	flag.StringVar(&opts.HTTP.SignHMAC, "sign-hmac", opts.HTTP.SignHMAC, "Sign the requests with a HMAC `ALGORITHM`: sha1, sha256, sha384 or sha512")
+	flag.StringVar(&opts.HTTP.SignHMACHeader, "sign-hmac-header", opts.HTTP.SignHMACHeader, "Header of the HMAC signature, either a name or \"NAME: VALUE\" with {{SIGNATURE}} or {{SIGNATURE_BASE64}} in the value")
+	flag.StringVar(&opts.HTTP.SignHMACKey, "sign-hmac-key", opts.HTTP.SignHMACKey, "HMAC `KEY`, or env:NAME to read it from an environment variable, or a hex: or base64: encoded key")
+	flag.StringVar(&opts.HTTP.SignHMACTemplate, "sign-hmac-template", opts.HTTP.SignHMACTemplate, "Template of the signed canonical string, eg. \"{{METHOD}}\\n{{PATH}}\\n{{TIMESTAMP}}\\n{{BODY_SHA256}}\"")
 	flag.StringVar(&opts.HTTP.ReplayProxyURL, "replay-proxy", opts.HTTP.ReplayProxyURL, "Replay matched requests using this proxy.")
+	flag.StringVar(&opts.HTTP.OOBDNS, "oob-dns", opts.HTTP.OOBDNS, "Listen for out-of-band DNS callbacks on this `ADDRESS`, eg. 127.0.0.1:5353, answering the queries of -oob-domain")
+	flag.StringVar(&opts.HTTP.OOBDomain, "oob-domain", opts.HTTP.OOBDomain, "Callback domain delegated to the out-of-band DNS listener, {{OOB}} expanding to FUFFAHASH.domain")
+	flag.StringVar(&opts.HTTP.OOBHTTP, "oob-http", opts.HTTP.OOBHTTP, "Listen for out-of-band HTTP callbacks on this `ADDRESS`, eg. 127.0.0.1:8088. {{OOB}} and {{OOB_URL}} expand to a callback host and URL containing the FUFFAHASH")
+	flag.StringVar(&opts.HTTP.OOBHost, "oob-host", opts.HTTP.OOBHost, "Host[:port] the targets reach the out-of-band HTTP listener at, if it differs from -oob-http")
+	flag.IntVar(&opts.HTTP.OOBWait, "oob-wait", opts.HTTP.OOBWait, "Seconds to wait for out-of-band callbacks after the scan")
+	flag.StringVar(&opts.HTTP.CrawlMode, "crawl-mode", opts.HTTP.CrawlMode, "Use of crawled links: \"queue\" to add new directories as queued jobs, \"wordlist\" to add the paths to FUZZ input")
 	flag.StringVar(&opts.HTTP.RecursionStrategy, "recursion-strategy", opts.HTTP.RecursionStrategy, "Recursion strategy: \"default\" for a redirect based, and \"greedy\" to recurse on all matches")
 	flag.StringVar(&opts.HTTP.URL, "u", opts.HTTP.URL, "Target URL")
 	flag.StringVar(&opts.HTTP.SNI, "sni", opts.HTTP.SNI, "Target TLS SNI, does not support FUZZ keyword")
@@ -136,10 +167,21 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.StringVar(&opts.Matcher.Time, "mt", opts.Matcher.Time, "Match how many milliseconds to the first response byte, either greater or less than. EG: >100 or <100")
 	flag.StringVar(&opts.Matcher.Words, "mw", opts.Matcher.Words, "Match amount of words in response")
 	flag.StringVar(&opts.Output.AuditLog, "audit-log", opts.Output.AuditLog, "Write audit log containing all requests, responses and config")
+	flag.StringVar(&opts.Output.AuditLogFormat, "audit-format", opts.Output.AuditLogFormat, "Audit log format: json or warc")
+	flag.BoolVar(&opts.Output.AuditLogCompress, "audit-compress", opts.Output.AuditLogCompress, "Gzip the rotated audit log segments")
+	flag.StringVar(&opts.Output.AuditLogMaxAge, "audit-max-age", opts.Output.AuditLogMaxAge, "Rotate the audit log after this long, eg. 1h or 30m")
+	flag.IntVar(&opts.Output.AuditLogMaxSize, "audit-max-size", opts.Output.AuditLogMaxSize, "Rotate the audit log when it grows over this many megabytes")
+	flag.BoolVar(&opts.Output.Redact, "redact", opts.Output.Redact, "Redact common secrets, like the Authorization and Cookie headers and password and token fields, from the audit log, the history and the output files")
+	flag.StringVar(&opts.Output.Baseline, "baseline", opts.Output.Baseline, "Compare the results to a previous json, ejson or jsonl output file and report new, gone and changed results")
 	flag.StringVar(&opts.Output.DebugLog, "debug-log", opts.Output.DebugLog, "Write all of the internal logging to the specified file.")
-	flag.StringVar(&opts.Output.OutputDirectory, "od", opts.Output.OutputDirectory, "Directory path to store matched results to.")
+	flag.StringVar(&opts.Output.OutputDirectory, "od", opts.Output.OutputDirectory, "Directory path to store matched results to, with an index.jsonl manifest. Print a stored result with: fuffa show -od DIR FUFFAHASH")
 	flag.StringVar(&opts.Output.OutputFile, "o", opts.Output.OutputFile, "Write output to file")
-	flag.StringVar(&opts.Output.OutputFormat, "of", opts.Output.OutputFormat, "Output file format. Available formats: json, ejson, html, md, csv, ecsv (or, 'all' for all formats)")
+	flag.StringVar(&opts.Output.Webhook, "webhook", opts.Output.Webhook, "Send the matches and job events to an HTTP webhook URL as JSON")
+	flag.IntVar(&opts.Output.WebhookBatch, "webhook-batch", opts.Output.WebhookBatch, "Number of notifications sent in a single webhook request")
+	flag.IntVar(&opts.Output.WebhookRetries, "webhook-retries", opts.Output.WebhookRetries, "Number of retries of a failed webhook request")
+	flag.StringVar(&opts.Output.WebhookSeverity, "webhook-severity", opts.Output.WebhookSeverity, "Minimum severity of the webhook notifications: info (all), low (matches), medium (2xx matches, aborted jobs) or high (2xx matches with scraper data, aborted scan)")
+	flag.StringVar(&opts.Output.WebhookTemplate, "webhook-template", opts.Output.WebhookTemplate, "Go text/template file for the webhook request body, executed with .CommandLine, .Version and .Notifications")
+	flag.StringVar(&opts.Output.OutputFormat, "of", opts.Output.OutputFormat, "Output file format. Available formats: json, ejson, html, md, csv, ecsv, jsonl, sqlite, har, warc, report (self-contained interactive HTML), template:/path/to/file.tmpl (or, 'all' for all formats)")
 	flag.Var(&autocalibrationstrings, "acc", "Custom auto-calibration string. Can be used multiple times. Implies -ac")
 	flag.Var(&autocalibrationstrategies, "acs", "Custom auto-calibration strategies. Can be used multiple times. Implies -ac")
 	flag.Var(&cookies, "b", "Cookie data `\"NAME1=VALUE1; NAME2=VALUE2\"` for copy as curl functionality.")
@@ -147,6 +189,10 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	flag.Var(&headers, "H", "Header `\"Name: Value\"`, separated by colon. Multiple -H flags are accepted.")
 	flag.Var(&inputcommands, "input-cmd", "Command producing the input. --input-num is required when using this input method. Overrides -w.")
 	flag.Var(&wordlists, "w", "Wordlist file path and (optional) keyword separated by colon. eg. '/path/to/wordlist:KEYWORD'")
+	flag.Var(&sinks, "sink", "Additional output file `FORMAT=PATH[,verbose]` written simultaneously, eg. 'jsonl=/tmp/results.jsonl'. Verbose sinks log the messages to PATH.log. Multiple -sink flags are accepted.")
+	flag.Var(&redactheaders, "redact-header", "Header `NAME` whose value is redacted from the audit log, the history and the output files. Multiple -redact-header flags are accepted.")
+	flag.Var(&redactregexes, "redact-regex", "Regex `REGEX` whose matches are redacted from the audit log, the history and the output files. Multiple -redact-regex flags are accepted.")
+	flag.Var(&redactfields, "redact-field", "JSON or form field `NAME` whose value is redacted from the audit log, the history and the output files. Multiple -redact-field flags are accepted.")
 	flag.Var(&encoders, "enc", "Encoders for keywords, eg. 'FUZZ:urlencode b64encode'")
 	flag.Usage = Usage
 	flag.Parse()
@@ -163,11 +209,45 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	opts.Input.Inputcommands = inputcommands
 	opts.Input.Wordlists = wordlists
 	opts.Input.Encoders = encoders
+	opts.Output.OutputSinks = sinks
+	opts.Output.RedactHeaders = redactheaders
+	opts.Output.RedactRegexes = redactregexes
+	opts.Output.RedactFields = redactfields
 	return opts
 }
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "show" {
+		os.Exit(runShow(os.Args[2:]))
+	}
+	// history rerun runs a past job again, the other history subcommands only browse the history
+	var rerunOpts *ffuf.ConfigOptions
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		if len(os.Args) < 3 || os.Args[2] != "rerun" {
+			os.Exit(runHistory(os.Args[2:]))
+		}
+		var args []string
+		var err error
+		rerunOpts, args, err = historyRerunOptions(os.Args[3:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			os.Exit(1)
+		}
+		os.Args = append([]string{os.Args[0]}, args...)
+	}
+	// replay-audit runs the scan of an audit log again, serving the responses from the log instead of the network
+	var auditLogFiles []string
+	replayAudit := len(os.Args) > 1 && os.Args[1] == "replay-audit"
+	if replayAudit {
+		var args []string
+		auditLogFiles, args = auditLogArgs(os.Args[2:])
+		os.Args = append([]string{os.Args[0]}, args...)
+	}
+
 	var err, optserr error
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -179,13 +259,16 @@ func main() {
 
 	// Handle searchhash functionality and exit
 	if opts.General.Searchhash != "" {
-		coptions, pos, err := ffuf.SearchHash(opts.General.Searchhash)
+		searchhash := ffuf.ExtractFuffaHash(opts.General.Searchhash)
+		coptions, fuffahash, err := ffuf.SearchHash(searchhash)
 		if err != nil {
 			fmt.Printf("[ERR] %s\n", err)
 			os.Exit(1)
 		}
 		if len(coptions) > 0 {
-			fmt.Printf("Request candidate(s) for hash %s\n", opts.General.Searchhash)
+			fmt.Printf("Request candidate(s) for hash %s\n", searchhash)
+		} else {
+			fmt.Printf("[ERR] No history entry found for hash %s\n", searchhash)
 		}
 		for _, copt := range coptions {
 			conf, err := ffuf.ConfigFromOptions(&copt.ConfigOptions, ctx, cancel)
@@ -194,7 +277,7 @@ func main() {
 			}
 			ok, reason := ffuf.HistoryReplayable(conf)
 			if ok {
-				printSearchResults(conf, pos, copt.Time, opts.General.Searchhash)
+				printSearchResults(conf, fuffahash, copt.Time, copt.Seeds, searchhash)
 			} else {
 				fmt.Printf("[ERR] Hash cannot be mapped back because %s\n", reason)
 			}
@@ -244,16 +327,54 @@ func main() {
 		opts = ParseFlags(opts)
 	}
 
-	// Set up Config struct
+	if rerunOpts != nil {
+		opts = rerunOpts
+		// Re-parse the cli options, so that they override the options of the past job
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		opts = ParseFlags(opts)
+	}
+
+	var auditLog *output.AuditLog
+	// cleanupReplay removes the wordlists of replay-audit, it is called before every exit from here on
+	cleanupReplay := func() {}
+	if replayAudit {
+		auditLogFiles = append(auditLogFiles, flag.Args()...)
+		if len(auditLogFiles) == 0 {
+			fmt.Fprintf(os.Stderr, "Usage: fuffa replay-audit AUDITLOG... [options]\n")
+			os.Exit(1)
+		}
+		auditLog, err = output.ReadAuditLog(auditLogFiles...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			os.Exit(1)
+		}
+		cleanupReplay, err = applyAuditLog(opts, auditLog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			os.Exit(1)
+		}
+		defer cleanupReplay()
+		if auditLogRedacted(auditLog) {
+			fmt.Fprintf(os.Stderr, "Warning: the audit log was written with redaction, the requests with %s values in their URL or body may not be matched and fail with \"request not found in the audit log\"\n", ffuf.REDACTED)
+		}
+		// Re-parse the cli options, so that they override the audited ones
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		opts = ParseFlags(opts)
+	}
 	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		cleanupReplay()
 		os.Exit(1)
 	}
 
 	job, err := prepareJob(conf)
+	if auditLog != nil {
+		job.Runner = runner.NewAuditReplayRunner(conf, auditLog.Responses)
+		job.ReplayRunner = nil
+	}
 
 	if job.AuditLogger != nil {
 		defer job.AuditLogger.Close()
@@ -262,14 +383,29 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		cleanupReplay()
 		os.Exit(1)
 	}
 	if err := SetupFilters(opts, conf); err != nil {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		cleanupReplay()
 		os.Exit(1)
 	}
+	SetupScraperFilters(job.Scraper, conf)
+	for _, warning := range job.Scraper.Warnings() {
+		fmt.Printf("*** Warning: %s\n", warning)
+	}
+
+	// Log in before the scan, the scan is not started without a session
+	if job.Session != nil {
+		if err := job.Session.Login(0); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not log in: %s\n", err)
+			cleanupReplay()
+			os.Exit(1)
+		}
+	}
 
 	if !conf.Noninteractive {
 		go func() {
@@ -298,9 +434,26 @@ func prepareJob(conf *ffuf.Config) (*ffuf.Job, error) {
 	// We only have stdout outputprovider right now
 	job.Output = output.NewOutputProviderByName("stdout", conf)
 
+	// Check that the output template parses before the scan
+	if strings.HasPrefix(conf.OutputFormat, output.TEMPLATE_FORMAT_PREFIX) {
+		if err := output.ValidateTemplate(strings.TrimPrefix(conf.OutputFormat, output.TEMPLATE_FORMAT_PREFIX)); err != nil {
+			errs.Add(fmt.Errorf("Could not parse the output template: %s", err))
+		}
+	}
+
+	if conf.WebhookTemplate != "" {
+		if err := output.ValidateWebhookTemplate(conf.WebhookTemplate); err != nil {
+			errs.Add(fmt.Errorf("Could not parse the webhook template: %s", err))
+		}
+	}
+
 	// Initialize the audit logger if specified
 	if len(conf.AuditLog) > 0 {
-		job.AuditLogger, err = output.NewAuditLogger(conf.AuditLog)
+		if conf.AuditLogFormat == "warc" {
+			job.AuditLogger, err = output.NewWARCLoggerWithOptions(conf.AuditLog, output.AuditLogOptionsFromConfig(conf))
+		} else {
+			job.AuditLogger, err = output.NewAuditLoggerWithOptions(conf.AuditLog, output.AuditLogOptionsFromConfig(conf))
+		}
 		if err != nil {
 			errs.Add(err)
 		} else {
@@ -311,6 +464,51 @@ func prepareJob(conf *ffuf.Config) (*ffuf.Job, error) {
 		}
 	}
 
+	// Initialize the link crawler
+	if conf.Crawl || conf.CrawlOnMatch {
+		job.Crawler = crawler.NewCrawler()
+	}
+
+	// Start the out-of-band interaction listener
+	if conf.OOBHTTP != "" || conf.OOBDNS != "" {
+		listener, err := oob.NewListener(conf)
+		if err != nil {
+			errs.Add(err)
+		} else {
+			job.OOB = listener
+		}
+	}
+
+	// Load the login macro of the session
+	if conf.SessionFile != "" {
+		s, err := session.NewSession(conf)
+		if err != nil {
+			errs.Add(err)
+		} else {
+			job.Session = s
+		}
+	}
+
+	// Prepare the pre-flight requests of the CSRF tokens
+	if conf.PreflightFile != "" {
+		p, err := session.NewPreflight(conf, job.Session)
+		if err != nil {
+			errs.Add(err)
+		} else {
+			job.Preflight = p
+		}
+	}
+
+	// Prepare the request signing
+	if conf.SignAWS != "" || conf.SignHMAC != "" {
+		signer, err := signing.NewSigner(conf)
+		if err != nil {
+			errs.Add(err)
+		} else {
+			job.Signer = signer
+		}
+	}
+
 	// Initialize scraper
 	newscraper, scraper_err := scraper.FromDir(ffuf.SCRAPERDIR, conf.Scrapers)
 	if scraper_err.ErrorOrNil() != nil {
@@ -326,6 +524,16 @@ func prepareJob(conf *ffuf.Config) (*ffuf.Job, error) {
 	return job, errs.ErrorOrNil()
 }
 
+// SetupScraperFilters adds the scraper rules with match and filter actions to the matchers and filters
+func SetupScraperFilters(s ffuf.Scraper, conf *ffuf.Config) {
+	for name, m := range s.Matchers() {
+		conf.MatcherManager.AddMatcherProvider(name, m)
+	}
+	for name, f := range s.Filters() {
+		conf.MatcherManager.AddFilterProvider(name, f)
+	}
+}
+
 func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 	errs := ffuf.NewMultierror()
 	conf.MatcherManager = filter.NewMatcherManager()
@@ -427,7 +635,7 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 	return errs.ErrorOrNil()
 }
 
-func printSearchResults(conf *ffuf.Config, pos int, exectime time.Time, hash string) {
+func printSearchResults(conf *ffuf.Config, fuffahash ffuf.FuffaHash, exectime time.Time, seeds int, hash string) {
 	inp, err := input.NewInputProvider(conf)
 	if err.ErrorOrNil() != nil {
 		fmt.Printf("-------------------------------------------\n")
@@ -435,14 +643,64 @@ func printSearchResults(conf *ffuf.Config, pos int, exectime time.Time, hash str
 		fmt.Println(err.ErrorOrNil())
 		return
 	}
-	inp.SetPosition(pos)
-	inputdata := inp.Value()
-	inputdata["FFUFHASH"] = []byte(hash)
-	basereq := ffuf.BaseRequest(conf)
+	// the paths seeded with -seed are not stored, placeholders keep the positions of the wordlist after them
+	seedPlaceholders := make(map[string]bool, seeds)
+	if seeds > 0 {
+		placeholders := make([][]byte, 0, seeds)
+		for i := 0; i < seeds; i++ {
+			placeholder := fmt.Sprintf("\x00seed-%d", i)
+			seedPlaceholders[placeholder] = true
+			placeholders = append(placeholders, []byte(placeholder))
+		}
+		if _, err := inp.Prepend("FUZZ", placeholders); err != nil {
+			fmt.Printf("[ERR] Could not map the positions of the seeded paths: %s\n", err)
+			return
+		}
+	}
+	basereqs := []ffuf.Request{ffuf.BaseRequest(conf)}
+	if conf.InputMode == "sniper" {
+		sniperreqs := ffuf.SniperRequests(&basereqs[0], conf.InputProviders[0].Template)
+		if fuffahash.Queue == 0 {
+			// the first hash format has no queue index, so the request may come from any payload location
+			basereqs = sniperreqs
+		} else if fuffahash.Queue <= len(sniperreqs) {
+			basereqs = sniperreqs[fuffahash.Queue-1 : fuffahash.Queue]
+		}
+	}
+	if fuffahash.Version > 1 && fuffahash.Position == 0 {
+		// the calibration requests use random strings, which are not stored in the history
+		fmt.Printf("-------------------------------------------\n")
+		fmt.Printf("fuffa job started at: %s\n", exectime.Format(time.RFC3339))
+		fmt.Printf("Queued job: %d\n", fuffahash.Queue)
+		fmt.Printf("\nAutocalibration request of the queued job, its random payload can not be reproduced\n")
+		return
+	}
 	dummyrunner := runner.NewRunnerByName("simple", conf, false)
-	ffufreq, _ := dummyrunner.Prepare(inputdata, &basereq)
-	rawreq, _ := dummyrunner.Dump(&ffufreq)
-	fmt.Printf("-------------------------------------------\n")
-	fmt.Printf("fuffa job started at: %s\n\n", exectime.Format(time.RFC3339))
-	fmt.Printf("%s\n", string(rawreq))
+	for _, basereq := range basereqs {
+		// activate the keywords of the queued job, like when it was started
+		keywords := make([]string, 0)
+		for _, k := range inp.Keywords() {
+			if ffuf.RequestContainsKeyword(basereq, k) {
+				keywords = append(keywords, k)
+			}
+		}
+		inp.ActivateKeywords(keywords)
+		inp.SetPosition(fuffahash.Position)
+		inputdata := inp.Value()
+		if seedPlaceholders[string(inputdata["FUZZ"])] {
+			fmt.Printf("-------------------------------------------\n")
+			fmt.Printf("fuffa job started at: %s\n", exectime.Format(time.RFC3339))
+			fmt.Printf("\nRequest to a path seeded from robots.txt, sitemaps or well-known files, the path is not stored in the history\n")
+			continue
+		}
+		inputdata["FUFFAHASH"] = []byte(hash)
+		ffufreq, _ := dummyrunner.Prepare(inputdata, &basereq)
+		rawreq, _ := dummyrunner.Dump(&ffufreq)
+		fmt.Printf("-------------------------------------------\n")
+		fmt.Printf("fuffa job started at: %s\n", exectime.Format(time.RFC3339))
+		if fuffahash.Queue > 0 {
+			fmt.Printf("Queued job: %d\n", fuffahash.Queue)
+		}
+		fmt.Printf("\n%s\n", string(rawreq))
+	}
 }
@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jsPathRegexp finds quoted strings in JavaScript that look like relative or absolute paths
+var jsPathRegexp = regexp.MustCompile(`["'` + "`" + `]((?:https?://[^"'` + "`" + `\s<>]+)|(?:\.{0,2}/[a-zA-Z0-9_\-.~/%]+(?:\?[^"'` + "`" + `\s<>]*)?)|(?:[a-zA-Z0-9_\-]+/[a-zA-Z0-9_\-.~/]+\.[a-zA-Z0-9]{1,5}(?:\?[^"'` + "`" + `\s<>]*)?))["'` + "`" + `]`)
+
+// linkAttributes maps the HTML elements to the attributes containing links we are interested in
+var linkAttributes = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"form":   "action",
+	"script": "src",
+	"iframe": "src",
+	"frame":  "src",
+}
+
+type Crawler struct{}
+
+func NewCrawler() ffuf.Crawler {
+	return &Crawler{}
+}
+
+// Links returns the absolute URLs of links, form actions, script sources and JavaScript string paths
+// found from a HTML or JavaScript response
+func (c *Crawler) Links(resp *ffuf.Response) []string {
+	if resp.Request == nil {
+		return []string{}
+	}
+	base, err := url.Parse(resp.Request.Url)
+	if err != nil {
+		return []string{}
+	}
+	raw := make([]string, 0)
+	ctype := strings.ToLower(resp.ContentType)
+	switch {
+	case strings.Contains(ctype, "html"):
+		raw, base = htmlLinks(string(resp.Data), base)
+	case strings.Contains(ctype, "javascript") || strings.HasSuffix(base.Path, ".js"):
+		raw = jsLinks(string(resp.Data))
+	}
+	links := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		u, err := base.Parse(strings.TrimSpace(r))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			links = append(links, u.String())
+		}
+	}
+	return links
+}
+
+// htmlLinks returns the links from element attributes and inline scripts, and the base URL to resolve them with
+func htmlLinks(data string, base *url.URL) ([]string, *url.URL) {
+	links := make([]string, 0)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(data))
+	if err != nil {
+		return links, base
+	}
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+	for element, attr := range linkAttributes {
+		doc.Find(element + "[" + attr + "]").Each(func(i int, sel *goquery.Selection) {
+			if val, ok := sel.Attr(attr); ok && len(strings.TrimSpace(val)) > 0 {
+				links = append(links, val)
+			}
+		})
+	}
+	doc.Find("script:not([src])").Each(func(i int, sel *goquery.Selection) {
+		links = append(links, jsLinks(sel.Text())...)
+	})
+	return links, base
+}
+
+func jsLinks(data string) []string {
+	links := make([]string, 0)
+	for _, m := range jsPathRegexp.FindAllStringSubmatch(data, -1) {
+		// skip comments and protocol-relative noise like "//"
+		if strings.Trim(m[1], "/.") == "" {
+			continue
+		}
+		links = append(links, m[1])
+	}
+	return links
+}
@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestLinksHTML(t *testing.T) {
+	data := `<html><head><script src="/static/app.js"></script></head><body>
+<a href="admin/">admin</a>
+<a href="https://other.example.com/x">external</a>
+<a href="mailto:foo@example.com">mail</a>
+<a href="#top">top</a>
+<form action="/login.php" method="POST"></form>
+<script>var api = "/api/v1/users"; fetch('reports/export.json');</script>
+</body></html>`
+	resp := ffuf.Response{
+		ContentType: "text/html; charset=utf-8",
+		Data:        []byte(data),
+		Request:     &ffuf.Request{Url: "http://example.com/app/index.php"},
+	}
+	links := NewCrawler().Links(&resp)
+	sort.Strings(links)
+	expected := []string{
+		"http://example.com/api/v1/users",
+		"http://example.com/app/admin/",
+		"http://example.com/app/index.php",
+		"http://example.com/app/reports/export.json",
+		"http://example.com/login.php",
+		"http://example.com/static/app.js",
+		"https://other.example.com/x",
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links but got %d: %v", len(expected), len(links), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Errorf("Expected link %s but got %s", expected[i], links[i])
+		}
+	}
+}
+
+func TestLinksJS(t *testing.T) {
+	resp := ffuf.Response{
+		ContentType: "application/javascript",
+		Data:        []byte(`const a = "/internal/health"; let b = 'v2/orders.json?id=1'; // "not a path"`),
+		Request:     &ffuf.Request{Url: "http://example.com/static/app.js"},
+	}
+	links := NewCrawler().Links(&resp)
+	sort.Strings(links)
+	if len(links) != 2 || links[0] != "http://example.com/internal/health" || links[1] != "http://example.com/static/v2/orders.json?id=1" {
+		t.Errorf("Unexpected links from JavaScript: %v", links)
+	}
+}
+
+func TestLinksIgnoresOtherContent(t *testing.T) {
+	resp := ffuf.Response{
+		ContentType: "image/png",
+		Data:        []byte(`"/not/a/link"`),
+		Request:     &ffuf.Request{Url: "http://example.com/logo.png"},
+	}
+	if links := NewCrawler().Links(&resp); len(links) != 0 {
+		t.Errorf("Expected no links from non HTML / JS content, got %v", links)
+	}
+}
@@ -82,20 +82,52 @@ func setupDefaultAutocalibrationStrategies() error {
 
 func (j *Job) calibrationRequest(inputs map[string][]byte) (Response, error) {
 	basereq := BaseRequest(j.Config)
-	req, err := j.Runner.Prepare(inputs, &basereq)
+	// the input positions start from 1, the position 0 of the FUFFAHASH tells the calibration requests apart
+	calibinputs := make(map[string][]byte, len(inputs)+1)
+	for k, v := range inputs {
+		calibinputs[k] = v
+	}
+	calibinputs["FUFFAHASH"] = j.fuffahash(0)
+	preflightWorker, err := j.preflight(calibinputs)
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Encountered an error while fetching the pre-flight token of an autocalibration request: %s\n", err))
+		j.incError()
+		log.Printf("%s", err)
+		return Response{}, err
+	}
+	req, err := j.Runner.Prepare(calibinputs, &basereq)
 	if err != nil {
+		j.releasePreflight(preflightWorker, nil)
 		j.Output.Error(fmt.Sprintf("Encountered an error while preparing autocalibration request: %s\n", err))
 		j.incError()
 		log.Printf("%s", err)
 		return Response{}, err
 	}
+	sessionGeneration := 0
+	if j.Session != nil {
+		sessionGeneration = j.Session.Apply(&req)
+	}
+	j.applyPreflight(preflightWorker, &req)
+	if j.Signer != nil {
+		if err := j.Signer.Sign(&req); err != nil {
+			j.releasePreflight(preflightWorker, nil)
+			j.Output.Error(fmt.Sprintf("Encountered an error while signing autocalibration request: %s\n", err))
+			j.incError()
+			log.Printf("%s", err)
+			return Response{}, err
+		}
+	}
 	resp, err := j.Runner.Execute(&req)
+	j.releasePreflight(preflightWorker, &resp)
 	if err != nil {
 		j.Output.Error(fmt.Sprintf("Encountered an error while executing autocalibration request: %s\n", err))
 		j.incError()
 		log.Printf("%s", err)
 		return Response{}, err
 	}
+	if j.Session != nil {
+		j.Session.Check(&resp, sessionGeneration)
+	}
 	// Only calibrate on responses that would be matched otherwise
 	if j.isMatch(resp) {
 		return resp, nil
@@ -22,6 +22,7 @@ func (o *NullOutput) Raw(output string)                      {}
 func (o *NullOutput) Warning(warnstring string)              {}
 func (o *NullOutput) Result(resp Response)                   {}
 func (o *NullOutput) PrintResult(res Result)                 {}
+func (o *NullOutput) Event(event JobEvent)                   {}
 func (o *NullOutput) SaveFile(filename, format string) error { return nil }
 func (o *NullOutput) GetCurrentResults() []Result            { return o.Results }
 func (o *NullOutput) SetCurrentResults(results []Result)     { o.Results = results }
@@ -2,21 +2,32 @@ package ffuf
 
 import (
 	"context"
+	"time"
 )
 
 type Config struct {
 	AuditLog                  string                `json:"auditlog"`
+	AuditLogFormat            string                `json:"auditlog_format"`
+	AuditLogCompress          bool                  `json:"auditlog_compress"`
+	AuditLogMaxAge            time.Duration         `json:"auditlog_max_age"`
+	AuditLogMaxSize           int                   `json:"auditlog_max_size"`
 	AutoCalibration           bool                  `json:"autocalibration"`
 	AutoCalibrationKeyword    string                `json:"autocalibration_keyword"`
 	AutoCalibrationPerHost    bool                  `json:"autocalibration_perhost"`
 	AutoCalibrationStrategies []string              `json:"autocalibration_strategies"`
 	AutoCalibrationStrings    []string              `json:"autocalibration_strings"`
+	Baseline                  string                `json:"baseline"`
 	Cancel                    context.CancelFunc    `json:"-"`
 	Colors                    bool                  `json:"colors"`
 	CommandKeywords           []string              `json:"-"`
 	CommandLine               string                `json:"cmdline"`
+	CorrelationHeader         string                `json:"correlation_header"`
+	CorrelationParam          string                `json:"correlation_param"`
 	ConfigFile                string                `json:"configfile"`
 	Context                   context.Context       `json:"-"`
+	Crawl                     bool                  `json:"crawl"`
+	CrawlMode                 string                `json:"crawl_mode"`
+	CrawlOnMatch              bool                  `json:"crawl_on_match"`
 	Data                      string                `json:"postdata"`
 	Debuglog                  string                `json:"debuglog"`
 	Delay                     optRange              `json:"delay"`
@@ -26,6 +37,8 @@ type Config struct {
 	FilterMode                string                `json:"fmode"`
 	FollowRedirects           bool                  `json:"follow_redirects"`
 	Headers                   map[string]string     `json:"headers"`
+	HistoryMaxAge             time.Duration         `json:"history_max_age"`
+	HistoryMaxEntries         int                   `json:"history_max_entries"`
 	IgnoreBody                bool                  `json:"ignorebody"`
 	IgnoreWordlistComments    bool                  `json:"ignore_wordlist_comments"`
 	InputMode                 string                `json:"inputmode"`
@@ -34,17 +47,26 @@ type Config struct {
 	InputShell                string                `json:"inputshell"`
 	WordlistLimit             int                   `json:"wordlist_limit"`
 	Json                      bool                  `json:"json"`
+	ResultFormat              string                `json:"result_format"`
 	MatcherManager            MatcherManager        `json:"matchers"`
 	MatcherMode               string                `json:"mmode"`
 	MaxTime                   int                   `json:"maxtime"`
 	MaxTimeJob                int                   `json:"maxtime_job"`
 	Method                    string                `json:"method"`
 	Noninteractive            bool                  `json:"noninteractive"`
+	OOBDNS                    string                `json:"oob_dns"`
+	OOBDomain                 string                `json:"oob_domain"`
+	OOBHTTP                   string                `json:"oob_http"`
+	OOBHost                   string                `json:"oob_host"`
+	OOBWait                   int                   `json:"oob_wait"`
 	OutputDirectory           string                `json:"outputdirectory"`
 	OutputFile                string                `json:"outputfile"`
 	OutputFormat              string                `json:"outputformat"`
+	OutputSinks               []string              `json:"output_sinks"`
 	OutputSkipEmptyFile       bool                  `json:"OutputSkipEmptyFile"`
 	ProgressFrequency         int                   `json:"-"`
+	PreflightExtract          string                `json:"preflight_extract"`
+	PreflightFile             string                `json:"preflight_file"`
 	ProxyURL                  string                `json:"proxyurl"`
 	Quiet                     bool                  `json:"quiet"`
 	Rate                      int64                 `json:"rate"`
@@ -52,11 +74,23 @@ type Config struct {
 	Recursion                 bool                  `json:"recursion"`
 	RecursionDepth            int                   `json:"recursion_depth"`
 	RecursionStrategy         string                `json:"recursion_strategy"`
+	Redact                    bool                  `json:"redact"`
+	RedactFields              []string              `json:"redact_fields"`
+	RedactHeaders             []string              `json:"redact_headers"`
+	RedactRegexes             []string              `json:"redact_regexes"`
+	Redaction                 *RedactionPolicy      `json:"-"`
 	ReplayProxyURL            string                `json:"replayproxyurl"`
 	RequestFile               string                `json:"requestfile"`
 	RequestProto              string                `json:"requestproto"`
 	ScraperFile               string                `json:"scraperfile"`
 	Scrapers                  string                `json:"scrapers"`
+	Seed                      bool                  `json:"seed"`
+	SessionFile               string                `json:"session_file"`
+	SignAWS                   string                `json:"sign_aws"`
+	SignHMAC                  string                `json:"sign_hmac"`
+	SignHMACHeader            string                `json:"sign_hmac_header"`
+	SignHMACKey               string                `json:"-"`
+	SignHMACTemplate          string                `json:"sign_hmac_template"`
 	SNI                       string                `json:"sni"`
 	StopOn403                 bool                  `json:"stop_403"`
 	StopOnAll                 bool                  `json:"stop_all"`
@@ -65,6 +99,11 @@ type Config struct {
 	Timeout                   int                   `json:"timeout"`
 	Url                       string                `json:"url"`
 	Verbose                   bool                  `json:"verbose"`
+	Webhook                   string                `json:"webhook"`
+	WebhookBatch              int                   `json:"webhook_batch"`
+	WebhookRetries            int                   `json:"webhook_retries"`
+	WebhookSeverity           string                `json:"webhook_severity"`
+	WebhookTemplate           string                `json:"webhook_template"`
 	DebugFirstRequest         bool                  `json:"debug_first_request"`
 	ForceDebugNext            bool                  `json:"-"`
 	Wordlists                 []string              `json:"wordlists"`
@@ -91,6 +130,9 @@ func NewConfig(ctx context.Context, cancel context.CancelFunc) Config {
 	conf.CommandKeywords = make([]string, 0)
 	conf.Context = ctx
 	conf.Cancel = cancel
+	conf.Crawl = false
+	conf.CrawlMode = "queue"
+	conf.CrawlOnMatch = false
 	conf.Data = ""
 	conf.Debuglog = ""
 	conf.Delay = optRange{0, 0, false, false}
@@ -98,6 +140,7 @@ func NewConfig(ctx context.Context, cancel context.CancelFunc) Config {
 	conf.Encoders = make([]string, 0)
 	conf.Extensions = make([]string, 0)
 	conf.FilterMode = "or"
+	conf.OOBWait = 5
 	conf.FollowRedirects = false
 	conf.Headers = make(map[string]string)
 	conf.IgnoreWordlistComments = false
@@ -119,22 +162,54 @@ func NewConfig(ctx context.Context, cancel context.CancelFunc) Config {
 	conf.Recursion = false
 	conf.RecursionDepth = 0
 	conf.RecursionStrategy = "default"
+	conf.Redact = false
+	conf.RedactFields = []string{}
+	conf.RedactHeaders = []string{}
+	conf.RedactRegexes = []string{}
 	conf.RequestFile = ""
 	conf.RequestProto = "https"
 	conf.SNI = ""
+	conf.SignAWS = ""
+	conf.SignHMAC = ""
+	conf.SignHMACHeader = "X-Signature"
+	conf.SignHMACKey = ""
+	conf.SignHMACTemplate = ""
 	conf.ScraperFile = ""
 	conf.Scrapers = "all"
+	conf.Seed = false
 	conf.StopOn403 = false
 	conf.StopOnAll = false
 	conf.StopOnErrors = false
 	conf.Timeout = 10
 	conf.Url = ""
 	conf.Verbose = false
+	conf.WebhookBatch = 1
+	conf.WebhookRetries = 3
+	conf.WebhookSeverity = "info"
 	conf.Wordlists = []string{}
 	conf.Http2 = false
 	return conf
 }
 
+// RawOutputNeeded returns true if an output file format needs the raw requests and responses
+func (c *Config) RawOutputNeeded() bool {
+	formats := make([]string, 0, len(c.OutputSinks)+1)
+	if len(c.OutputFile) > 0 {
+		formats = append(formats, c.OutputFormat)
+	}
+	for _, sink := range c.OutputSinks {
+		if s, err := ParseOutputSink(sink); err == nil {
+			formats = append(formats, s.Format)
+		}
+	}
+	for _, format := range formats {
+		if format == "har" || format == "warc" {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Config) SetContext(ctx context.Context, cancel context.CancelFunc) {
 	c.Context = ctx
 	c.Cancel = cancel
@@ -9,6 +9,11 @@ func (c *Config) ToOptions() ConfigOptions {
 	o := ConfigOptions{}
 	// HTTP options
 	o.HTTP.Cookies = []string{}
+	o.HTTP.Crawl = c.Crawl
+	o.HTTP.CrawlMode = c.CrawlMode
+	o.HTTP.CrawlOnMatch = c.CrawlOnMatch
+	o.HTTP.CorrelationHeader = c.CorrelationHeader
+	o.HTTP.CorrelationParam = c.CorrelationParam
 	o.HTTP.Data = c.Data
 	o.HTTP.FollowRedirects = c.FollowRedirects
 	o.HTTP.Headers = make([]string, 0)
@@ -17,12 +22,25 @@ func (c *Config) ToOptions() ConfigOptions {
 	}
 	o.HTTP.IgnoreBody = c.IgnoreBody
 	o.HTTP.Method = c.Method
+	o.HTTP.OOBDNS = c.OOBDNS
+	o.HTTP.OOBDomain = c.OOBDomain
+	o.HTTP.OOBHTTP = c.OOBHTTP
+	o.HTTP.OOBHost = c.OOBHost
+	o.HTTP.OOBWait = c.OOBWait
+	o.HTTP.Preflight = c.PreflightFile
+	o.HTTP.PreflightExtract = c.PreflightExtract
 	o.HTTP.ProxyURL = c.ProxyURL
 	o.HTTP.Raw = c.Raw
 	o.HTTP.Recursion = c.Recursion
 	o.HTTP.RecursionDepth = c.RecursionDepth
 	o.HTTP.RecursionStrategy = c.RecursionStrategy
 	o.HTTP.ReplayProxyURL = c.ReplayProxyURL
+	o.HTTP.Session = c.SessionFile
+	o.HTTP.SignAWS = c.SignAWS
+	o.HTTP.SignHMAC = c.SignHMAC
+	o.HTTP.SignHMACHeader = c.SignHMACHeader
+	o.HTTP.SignHMACKey = maskSignKey(c.SignHMACKey)
+	o.HTTP.SignHMACTemplate = c.SignHMACTemplate
 	o.HTTP.SNI = c.SNI
 	o.HTTP.Timeout = c.Timeout
 	o.HTTP.URL = c.Url
@@ -44,7 +62,12 @@ func (c *Config) ToOptions() ConfigOptions {
 	} else {
 		o.General.Delay = ""
 	}
+	if c.HistoryMaxAge > 0 {
+		o.General.HistoryMaxAge = c.HistoryMaxAge.String()
+	}
+	o.General.HistoryMaxEntries = c.HistoryMaxEntries
 	o.General.Json = c.Json
+	o.General.ResultFormat = c.ResultFormat
 	o.General.MaxTime = c.MaxTime
 	o.General.MaxTimeJob = c.MaxTimeJob
 	o.General.Noninteractive = c.Noninteractive
@@ -72,14 +95,32 @@ func (c *Config) ToOptions() ConfigOptions {
 	}
 	o.Input.Request = c.RequestFile
 	o.Input.RequestProto = c.RequestProto
+	o.Input.Seed = c.Seed
 	o.Input.Wordlists = c.Wordlists
 
 	o.Output.AuditLog = c.AuditLog
+	o.Output.AuditLogFormat = c.AuditLogFormat
+	o.Output.AuditLogCompress = c.AuditLogCompress
+	if c.AuditLogMaxAge > 0 {
+		o.Output.AuditLogMaxAge = c.AuditLogMaxAge.String()
+	}
+	o.Output.AuditLogMaxSize = c.AuditLogMaxSize
+	o.Output.Baseline = c.Baseline
 	o.Output.DebugLog = c.Debuglog
 	o.Output.OutputDirectory = c.OutputDirectory
 	o.Output.OutputFile = c.OutputFile
 	o.Output.OutputFormat = c.OutputFormat
 	o.Output.OutputSkipEmptyFile = c.OutputSkipEmptyFile
+	o.Output.OutputSinks = c.OutputSinks
+	o.Output.Redact = c.Redact
+	o.Output.RedactFields = c.RedactFields
+	o.Output.RedactHeaders = c.RedactHeaders
+	o.Output.RedactRegexes = c.RedactRegexes
+	o.Output.Webhook = c.Webhook
+	o.Output.WebhookBatch = c.WebhookBatch
+	o.Output.WebhookRetries = c.WebhookRetries
+	o.Output.WebhookSeverity = c.WebhookSeverity
+	o.Output.WebhookTemplate = c.WebhookTemplate
 
 	o.Filter.Mode = c.FilterMode
 	o.Filter.Lines = ""
@@ -0,0 +1,184 @@
+package ffuf
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// crawlMaxPages is the upper limit of pages requested during the crawl phase
+const crawlMaxPages = 500
+
+// crawlStaticExtensions are not requested during the crawl phase as they are unlikely to contain links
+var crawlStaticExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".ico", ".svg", ".webp", ".bmp",
+	".woff", ".woff2", ".ttf", ".eot", ".otf", ".pdf", ".zip", ".gz", ".mp3", ".mp4", ".avi", ".webm"}
+
+type crawlPage struct {
+	url   string
+	depth int
+}
+
+// crawlBase returns the URL the crawler is scoped to, ie. the target URL without the trailing FUZZ keyword
+func (j *Job) crawlBase() (*url.URL, error) {
+	return url.Parse(strings.TrimSuffix(j.queueJob(0).Url, "FUZZ"))
+}
+
+// crawl requests the target URL and follows the same-origin links found from the responses
+// before the fuzzing jobs are started, seeding the discoveries to the queue or the wordlist
+func (j *Job) crawl() {
+	base, err := j.crawlBase()
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Could not parse the URL for crawling: %s", err))
+		return
+	}
+	j.Output.Info(fmt.Sprintf("Crawling %s for links", base.String()))
+	pages := []crawlPage{{url: base.String(), depth: 0}}
+	visited := map[string]bool{base.String(): true}
+	requested := 0
+	for len(pages) > 0 && requested < crawlMaxPages {
+		if j.Config.Context.Err() != nil {
+			return
+		}
+		page := pages[0]
+		pages = pages[1:]
+		resp, err := j.fetchURL(page.url)
+		requested++
+		if err != nil {
+			continue
+		}
+		for _, link := range j.handleCrawlLinks(j.Crawler.Links(&resp)) {
+			if visited[link] || isStaticLink(link) {
+				continue
+			}
+			visited[link] = true
+			if j.Config.RecursionDepth == 0 || page.depth < j.Config.RecursionDepth {
+				pages = append(pages, crawlPage{url: link, depth: page.depth + 1})
+			}
+		}
+	}
+	j.Output.Info(fmt.Sprintf("Crawling finished, requested %d pages", requested))
+}
+
+// fetchURL performs a GET request outside of the fuzzing jobs, using the headers of the base request
+func (j *Job) fetchURL(link string) (Response, error) {
+	basereq := RecursionRequest(j.Config, link)
+	basereq.Method = "GET"
+	basereq.Data = []byte{}
+	req, err := j.Runner.Prepare(map[string][]byte{}, &basereq)
+	if err != nil {
+		log.Printf("%s", err)
+		return Response{}, err
+	}
+	sessionGeneration := 0
+	if j.Session != nil {
+		sessionGeneration = j.Session.Apply(&req)
+	}
+	if j.Signer != nil {
+		if err := j.Signer.Sign(&req); err != nil {
+			log.Printf("%s", err)
+			return Response{}, err
+		}
+	}
+	<-j.Rate.RateLimiter.C
+	resp, err := j.Runner.Execute(&req)
+	if err != nil {
+		req.Error = err.Error()
+	}
+	if j.AuditLogger != nil {
+		if e := j.AuditLogger.Write(&req); e != nil {
+			j.Output.Error(fmt.Sprintf("Encountered error while writing request audit log: %s\n", e))
+		}
+	}
+	if err != nil {
+		log.Printf("Request to %s failed: %s", link, err)
+		return Response{}, err
+	}
+	if j.AuditLogger != nil {
+		if e := j.AuditLogger.Write(&resp); e != nil {
+			j.Output.Error(fmt.Sprintf("Encountered error while writing response audit log: %s\n", e))
+		}
+	}
+	if j.Session != nil {
+		j.Session.Check(&resp, sessionGeneration)
+	}
+	j.sleepIfNeeded()
+	return resp, nil
+}
+
+// handleCrawlLinks enqueues new directories or feeds new paths to the FUZZ wordlist for the in-scope links,
+// and returns the in-scope links that were not seen before
+func (j *Job) handleCrawlLinks(links []string) []string {
+	base, err := j.crawlBase()
+	if err != nil {
+		return []string{}
+	}
+	newLinks := make([]string, 0)
+	newPaths := make([]string, 0)
+	j.crawlMutex.Lock()
+	defer j.crawlMutex.Unlock()
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil || !UrlEqual(base, u) || !strings.HasPrefix(u.Path, base.Path) {
+			continue
+		}
+		u.RawQuery = ""
+		if j.crawlSeen[u.String()] {
+			continue
+		}
+		j.crawlSeen[u.String()] = true
+		newLinks = append(newLinks, link)
+		relPath := strings.TrimPrefix(u.Path, base.Path)
+		if len(relPath) == 0 {
+			continue
+		}
+		if j.Config.CrawlMode == "wordlist" {
+			newPaths = append(newPaths, relPath)
+		} else {
+			j.enqueueCrawlDirectories(base, relPath)
+		}
+	}
+	if len(newPaths) > 0 {
+		j.feedInput("FUZZ", newPaths)
+	}
+	return newLinks
+}
+
+// enqueueCrawlDirectories adds a queue job for each directory leading to relPath that is within the recursion depth
+func (j *Job) enqueueCrawlDirectories(base *url.URL, relPath string) {
+	dirs := strings.Split(path.Dir("/"+relPath), "/")[1:]
+	current := strings.TrimSuffix(base.Scheme+"://"+base.Host+base.Path, "/")
+	for i, dir := range dirs {
+		if len(dir) == 0 {
+			break
+		}
+		depth := i + 1
+		if j.Config.RecursionDepth > 0 && depth > j.Config.RecursionDepth {
+			break
+		}
+		current = current + "/" + dir
+		recUrl := current + "/FUZZ"
+		if j.crawlSeen[recUrl] {
+			continue
+		}
+		j.crawlSeen[recUrl] = true
+		newJob := QueueJob{Url: recUrl, depth: depth, req: RecursionRequest(j.Config, recUrl)}
+		j.enqueue(newJob)
+		j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+	}
+}
+
+func isStaticLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	for _, e := range crawlStaticExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
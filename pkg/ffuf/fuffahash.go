@@ -0,0 +1,122 @@
+package ffuf
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FUFFAHASH_PREFIX starts the current FUFFAHASH format, the hashes of the first format being plain hex strings
+const FUFFAHASH_PREFIX = "v2"
+
+// fuffaHashJobLength is the number of the characters of the history entry hash in the current FUFFAHASH format
+const fuffaHashJobLength = 12
+
+var fuffaHashRegexp = regexp.MustCompile(`^v2([0-9a-f]{12})q([0-9a-f]+)p([0-9a-f]+)([0-9a-f]{4})$`)
+
+// fuffaHashCandidate matches the current FUFFAHASH format in a longer string, possibly followed by more hex characters
+var fuffaHashCandidate = regexp.MustCompile(`(?i)v2[0-9a-f]{12}q[0-9a-f]+p[0-9a-f]{5,}`)
+
+// FuffaHash identifies a request of a scan: the history entry of its job, the index of the job in the queue of
+// recursion and sniper jobs, and the position of its input. It is formatted as
+// v2<12 hex of the history entry hash>q<queue index in hex>p<position in hex><4 hex checksum>.
+type FuffaHash struct {
+	Version int
+	// Job is the beginning of the hash of the history entry
+	Job string
+	// Queue is the index of the job in the queue starting from 1, or 0 for the first format that has no index
+	Queue    int
+	Position int
+}
+
+// NewFuffaHash returns the FUFFAHASH of a request
+func NewFuffaHash(jobhash string, queue, position int) FuffaHash {
+	job := strings.ToLower(jobhash)
+	if len(job) > fuffaHashJobLength {
+		job = job[:fuffaHashJobLength]
+	}
+	// the job hash is missing if the history could not be written, keep the format parseable
+	job += strings.Repeat("0", fuffaHashJobLength-len(job))
+	return FuffaHash{Version: 2, Job: job, Queue: queue, Position: position}
+}
+
+func (h FuffaHash) String() string {
+	if h.Version < 2 {
+		return fmt.Sprintf("%s%x", h.Job, h.Position)
+	}
+	body := fmt.Sprintf("%s%sq%xp%x", FUFFAHASH_PREFIX, h.Job, h.Queue, h.Position)
+	return body + fuffaHashChecksum(body)
+}
+
+// fuffaHashChecksum returns the checksum detecting truncated and mistyped hashes
+func fuffaHashChecksum(body string) string {
+	return fmt.Sprintf("%04x", crc32.ChecksumIEEE([]byte(body))&0xffff)
+}
+
+// ParseFuffaHash decodes a FUFFAHASH of the current format, or of the first format made of the first 5 hex
+// characters of the history entry hash followed by the position in hex
+func ParseFuffaHash(hash string) (FuffaHash, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if strings.HasPrefix(hash, FUFFAHASH_PREFIX) {
+		m := fuffaHashRegexp.FindStringSubmatch(hash)
+		if m == nil {
+			return FuffaHash{}, errors.New("bad FUFFAHASH value")
+		}
+		if fuffaHashChecksum(strings.TrimSuffix(hash, m[4])) != m[4] {
+			return FuffaHash{}, errors.New("bad FUFFAHASH checksum, the hash is truncated or mistyped")
+		}
+		queue, err := strconv.ParseInt(m[2], 16, 32)
+		if err != nil {
+			return FuffaHash{}, errors.New("bad queue index in FUFFAHASH")
+		}
+		position, err := strconv.ParseInt(m[3], 16, 32)
+		if err != nil {
+			return FuffaHash{}, errors.New("bad positional value in FUFFAHASH")
+		}
+		return FuffaHash{Version: 2, Job: m[1], Queue: int(queue), Position: int(position)}, nil
+	}
+	if len(hash) < 6 {
+		return FuffaHash{}, errors.New("bad FUFFAHASH value")
+	}
+	position, err := strconv.ParseInt(hash[5:], 16, 32)
+	if err != nil {
+		return FuffaHash{}, errors.New("bad positional value in FUFFAHASH")
+	}
+	return FuffaHash{Version: 1, Job: hash[:5], Position: int(position)}, nil
+}
+
+// ExtractFuffaHash returns the FUFFAHASH of a value copied from the server logs, like a correlation header line or
+// an URL with the correlation query parameter. A value without a FUFFAHASH of the current format is returned as is.
+func ExtractFuffaHash(s string) string {
+	s = strings.TrimSpace(s)
+	if _, err := ParseFuffaHash(s); err == nil {
+		return s
+	}
+	if found := FindFuffaHashes(s); len(found) > 0 {
+		return found[0]
+	}
+	return s
+}
+
+// FindFuffaHashes returns the FUFFAHASHes of the current format found in a string, like an URL or a DNS name. The
+// checksum tells where a hash ends when it is followed by other hex characters.
+func FindFuffaHashes(s string) []string {
+	found := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, candidate := range fuffaHashCandidate.FindAllString(s, -1) {
+		candidate = strings.ToLower(candidate)
+		for end := len(candidate); end >= strings.LastIndex(candidate, "p")+6; end-- {
+			if _, err := ParseFuffaHash(candidate[:end]); err == nil {
+				if !seen[candidate[:end]] {
+					seen[candidate[:end]] = true
+					found = append(found, candidate[:end])
+				}
+				break
+			}
+		}
+	}
+	return found
+}
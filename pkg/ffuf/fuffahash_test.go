@@ -0,0 +1,83 @@
+package ffuf
+
+import (
+	"testing"
+)
+
+func TestFuffaHash(t *testing.T) {
+	hash := NewFuffaHash("7D10DEF72B506C9747E73DFDB07D8649", 3, 0x2a)
+	s := hash.String()
+	if s[:16] != "v27d10def72b50q3" {
+		t.Errorf("Unexpected FUFFAHASH %s", s)
+	}
+	parsed, err := ParseFuffaHash(s)
+	if err != nil {
+		t.Fatalf("Could not parse FUFFAHASH %s: %s", s, err)
+	}
+	if parsed != hash {
+		t.Errorf("Expected %+v, got %+v", hash, parsed)
+	}
+	if _, err := ParseFuffaHash(s[:len(s)-1]); err == nil {
+		t.Errorf("Expected an error for a truncated hash")
+	}
+	mistyped := s[:15] + "4" + s[16:]
+	if _, err := ParseFuffaHash(mistyped); err == nil {
+		t.Errorf("Expected a checksum error for the mistyped hash %s", mistyped)
+	}
+
+	// the job hash is padded when the history entry could not be written
+	if parsed, err = ParseFuffaHash(NewFuffaHash("", 1, 1).String()); err != nil || parsed.Job != "000000000000" {
+		t.Errorf("Expected a parseable hash without job hash, got %+v (%v)", parsed, err)
+	}
+}
+
+func TestParseFuffaHashFirstFormat(t *testing.T) {
+	parsed, err := ParseFuffaHash("7d10d1f")
+	if err != nil {
+		t.Fatalf("Could not parse the first format hash: %s", err)
+	}
+	expected := FuffaHash{Version: 1, Job: "7d10d", Queue: 0, Position: 0x1f}
+	if parsed != expected {
+		t.Errorf("Expected %+v, got %+v", expected, parsed)
+	}
+	if parsed.String() != "7d10d1f" {
+		t.Errorf("Expected the first format hash to format back, got %s", parsed.String())
+	}
+	for _, bad := range []string{"7d10d", "7d10dxyz", "v2zz"} {
+		if _, err := ParseFuffaHash(bad); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
+
+func TestFindFuffaHashes(t *testing.T) {
+	first := NewFuffaHash("0123456789abcdef", 1, 0xabc).String()
+	second := NewFuffaHash("fedcba9876543210", 12, 3).String()
+	// the first hash is followed by hex characters that look like a longer position and checksum
+	s := "GET /" + first + "beef?h=" + second + ".oob.example.com&again=" + first
+	found := FindFuffaHashes(s)
+	if len(found) != 2 || found[0] != first || found[1] != second {
+		t.Errorf("Expected [%s %s], got %v", first, second, found)
+	}
+	if found = FindFuffaHashes("v2" + first[2:len(first)-1] + "0"); len(found) != 0 {
+		t.Errorf("Expected no hash with a bad checksum, got %v", found)
+	}
+}
+
+func TestExtractFuffaHash(t *testing.T) {
+	hash := NewFuffaHash("0123456789abcdef", 2, 0x1f).String()
+	for _, s := range []string{
+		hash,
+		" " + hash + "\n",
+		"X-Fuffa-Id: " + hash,
+		`10.0.0.1 - - [19/Oct/2026:10:00:00 +0000] "GET /admin?fuffa_id=` + hash + ` HTTP/1.1" 404 153`,
+	} {
+		if extracted := ExtractFuffaHash(s); extracted != hash {
+			t.Errorf("Expected %s from %q, got %s", hash, s, extracted)
+		}
+	}
+	// the first format can not be found in a longer string, and is passed as is
+	if extracted := ExtractFuffaHash("7d10d1f"); extracted != "7d10d1f" {
+		t.Errorf("Expected the first format hash as is, got %s", extracted)
+	}
+}
@@ -5,22 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 type ConfigOptionsHistory struct {
 	ConfigOptions
 	Time time.Time `json:"time"`
+	// Seeds is the number of the paths prepended to the FUZZ input by -seed, shifting the wordlist positions
+	Seeds int `json:"seeds,omitempty"`
 }
 
-func WriteHistoryEntry(conf *Config) (string, error) {
+// WriteHistoryEntry writes the options of a job to the history, seeds being the number of the seeded paths
+func WriteHistoryEntry(conf *Config, seeds int) (string, error) {
+	if conf.Redaction != nil {
+		// the history is shared like the audit log, so the secrets are not stored in it
+		conf = conf.Redaction.Config(conf)
+	}
 	options := ConfigOptionsHistory{
 		ConfigOptions: conf.ToOptions(),
 		Time:          time.Now(),
+		Seeds:         seeds,
 	}
 	jsonoptions, err := json.Marshal(options)
 	if err != nil {
@@ -31,7 +41,7 @@ func WriteHistoryEntry(conf *Config) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	err = os.WriteFile(filepath.Join(HISTORYDIR, hashstr, "options"), jsonoptions, 0640)
+	err = os.WriteFile(filepath.Join(HISTORYDIR, hashstr, historyOptionsFile), jsonoptions, 0640)
 	return hashstr, err
 }
 
@@ -39,24 +49,22 @@ func calculateHistoryHash(options []byte) string {
 	return fmt.Sprintf("%x", sha256.Sum256(options))
 }
 
-func SearchHash(hash string) ([]ConfigOptionsHistory, int, error) {
+// SearchHash returns the history entries of the job of a FUFFAHASH, and the decoded hash. A hash of the current
+// format resolves a single entry, the first format only has 5 characters of the entry hash.
+func SearchHash(hash string) ([]ConfigOptionsHistory, FuffaHash, error) {
 	coptions := make([]ConfigOptionsHistory, 0)
-	if len(hash) < 6 {
-		return coptions, 0, errors.New("bad FUFFAHASH value")
-	}
-	historypart := hash[0:5]
-	position, err := strconv.ParseInt(hash[5:], 16, 32)
+	fuffahash, err := ParseFuffaHash(hash)
 	if err != nil {
-		return coptions, 0, errors.New("bad positional value in FUFFAHASH")
+		return coptions, fuffahash, err
 	}
 	all_dirs, err := os.ReadDir(HISTORYDIR)
 	if err != nil {
-		return coptions, 0, err
+		return coptions, fuffahash, err
 	}
 	matched_dirs := make([]string, 0)
 	for _, filename := range all_dirs {
 		if filename.IsDir() {
-			if strings.HasPrefix(strings.ToLower(filename.Name()), strings.ToLower(historypart)) {
+			if strings.HasPrefix(strings.ToLower(filename.Name()), fuffahash.Job) {
 				matched_dirs = append(matched_dirs, filename.Name())
 			}
 		}
@@ -69,7 +77,7 @@ func SearchHash(hash string) ([]ConfigOptionsHistory, int, error) {
 		coptions = append(coptions, copts)
 
 	}
-	return coptions, int(position), err
+	return coptions, fuffahash, err
 }
 
 func HistoryReplayable(conf *Config) (bool, string) {
@@ -82,7 +90,7 @@ func HistoryReplayable(conf *Config) (bool, string) {
 }
 
 func configFromHistory(dirname string) (ConfigOptionsHistory, error) {
-	jsonOptions, err := os.ReadFile(filepath.Join(dirname, "options"))
+	jsonOptions, err := os.ReadFile(filepath.Join(dirname, historyOptionsFile))
 	if err != nil {
 		return ConfigOptionsHistory{}, err
 	}
@@ -90,3 +98,286 @@ func configFromHistory(dirname string) (ConfigOptionsHistory, error) {
 	err = json.Unmarshal(jsonOptions, &tmpOptions)
 	return tmpOptions, err
 }
+
+const (
+	historyOptionsFile = "options"
+	historyJobFile     = "job"
+	historyResultsFile = "results.jsonl"
+)
+
+// HistoryJob is the summary of a job stored in the scan history when the job ends
+type HistoryJob struct {
+	Url      string    `json:"url"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Requests int       `json:"requests"`
+	Errors   int       `json:"errors"`
+	Matches  int       `json:"matches"`
+	Aborted  bool      `json:"aborted"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// HistoryResult is a result of a job stored in the scan history
+type HistoryResult struct {
+	Position         int               `json:"position"`
+	FuffaHash        string            `json:"fuffahash"`
+	Url              string            `json:"url"`
+	Host             string            `json:"host"`
+	Input            map[string]string `json:"input"`
+	StatusCode       int64             `json:"status"`
+	ContentLength    int64             `json:"length"`
+	ContentWords     int64             `json:"words"`
+	ContentLines     int64             `json:"lines"`
+	ContentType      string            `json:"content-type"`
+	RedirectLocation string            `json:"redirectlocation"`
+	Duration         time.Duration     `json:"duration"`
+}
+
+// HistoryEntry is a job of the scan history. Job is nil if the job did not end, like when fuffa was killed, or if
+// the entry was written by an older version.
+type HistoryEntry struct {
+	Hash    string               `json:"hash"`
+	Options ConfigOptionsHistory `json:"options"`
+	Job     *HistoryJob          `json:"job"`
+}
+
+// Time returns the start time of the job
+func (e *HistoryEntry) Time() time.Time {
+	return e.Options.Time
+}
+
+// Url returns the target of the job
+func (e *HistoryEntry) Url() string {
+	if e.Job != nil {
+		return e.Job.Url
+	}
+	return e.Options.HTTP.URL
+}
+
+// Matches returns the number of results of the job
+func (e *HistoryEntry) Matches() int {
+	if e.Job != nil {
+		return e.Job.Matches
+	}
+	return 0
+}
+
+// ListHistory returns the entries of the scan history, oldest first
+func ListHistory() ([]HistoryEntry, error) {
+	dirs, err := os.ReadDir(HISTORYDIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []HistoryEntry{}, nil
+		}
+		return nil, err
+	}
+	entries := make([]HistoryEntry, 0, len(dirs))
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		entry, err := readHistoryEntry(dir.Name())
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time().Before(entries[j].Time()) })
+	return entries, nil
+}
+
+// FindHistory returns the entries whose hash starts with the prefix, like the first characters of a FUFFAHASH
+func FindHistory(prefix string) ([]HistoryEntry, error) {
+	if len(prefix) < 5 {
+		return nil, errors.New("the history hash must have at least 5 characters")
+	}
+	all, err := ListHistory()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HistoryEntry, 0)
+	for _, entry := range all {
+		if strings.HasPrefix(entry.Hash, strings.ToLower(prefix)) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func readHistoryEntry(hash string) (HistoryEntry, error) {
+	options, err := configFromHistory(filepath.Join(HISTORYDIR, hash))
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	entry := HistoryEntry{Hash: hash, Options: options}
+	if data, err := os.ReadFile(filepath.Join(HISTORYDIR, hash, historyJobFile)); err == nil {
+		job := &HistoryJob{}
+		if json.Unmarshal(data, job) == nil {
+			entry.Job = job
+		}
+	}
+	if entry.Options.Time.IsZero() {
+		if stat, err := os.Stat(filepath.Join(HISTORYDIR, hash, historyOptionsFile)); err == nil {
+			entry.Options.Time = stat.ModTime()
+		}
+	}
+	return entry, nil
+}
+
+// HistoryResults returns the results stored for the history entry
+func HistoryResults(hash string) ([]HistoryResult, error) {
+	f, err := os.Open(filepath.Join(HISTORYDIR, hash, historyResultsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []HistoryResult{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	results := make([]HistoryResult, 0)
+	decoder := json.NewDecoder(f)
+	for decoder.More() {
+		var result HistoryResult
+		if err := decoder.Decode(&result); err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// PruneHistory removes the history entries older than maxAge and the oldest entries above maxEntries, a zero limit
+// being unlimited. The removed entries are returned.
+func PruneHistory(maxAge time.Duration, maxEntries int) ([]HistoryEntry, error) {
+	entries, err := ListHistory()
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]HistoryEntry, 0)
+	var errs Multierror
+	for i, entry := range entries {
+		expired := maxAge > 0 && time.Since(entry.Time()) > maxAge
+		excess := maxEntries > 0 && len(entries)-i > maxEntries
+		if !expired && !excess {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(HISTORYDIR, entry.Hash)); err != nil {
+			errs.Add(err)
+			continue
+		}
+		removed = append(removed, entry)
+	}
+	return removed, errs.ErrorOrNil()
+}
+
+// historyRecorder stores the results and the summary of a job in its history entry
+type historyRecorder struct {
+	hash      string
+	redaction *RedactionPolicy
+	file      *os.File
+	lock      sync.Mutex
+	matches   int
+	// errorCount is the error counter of the scan when the job started
+	errorCount int
+	err        error
+	// summary is the summary written by Close, updated by the results arriving late, like the OOB interactions
+	summary *HistoryJob
+}
+
+func newHistoryRecorder(hash string, conf *Config, errorCount int) *historyRecorder {
+	return &historyRecorder{hash: hash, redaction: conf.Redaction, errorCount: errorCount}
+}
+
+// Result appends a result to the history entry. The first error is kept and returned by Close. The hit count of
+// the summary is updated if the job was closed already.
+func (h *historyRecorder) Result(resp Response) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.matches++
+	h.writeResult(resp)
+	if h.summary == nil {
+		return
+	}
+	if h.file != nil {
+		h.file.Close()
+		h.file = nil
+	}
+	h.summary.Matches = h.matches
+	if err := h.writeSummary(); err != nil {
+		log.Printf("Could not write the history of the job: %s", err)
+	}
+}
+
+func (h *historyRecorder) writeResult(resp Response) {
+	if h.err != nil {
+		return
+	}
+	if h.file == nil {
+		h.file, h.err = os.OpenFile(filepath.Join(HISTORYDIR, h.hash, historyResultsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if h.err != nil {
+			return
+		}
+	}
+	if h.redaction != nil {
+		resp = *h.redaction.Response(&resp)
+	}
+	result := HistoryResult{
+		StatusCode:       resp.StatusCode,
+		ContentLength:    resp.ContentLength,
+		ContentWords:     resp.ContentWords,
+		ContentLines:     resp.ContentLines,
+		ContentType:      resp.ContentType,
+		RedirectLocation: resp.GetRedirectLocation(false),
+		Duration:         resp.Duration,
+		Input:            make(map[string]string),
+	}
+	if resp.Request != nil {
+		result.Position = resp.Request.Position
+		result.Url = resp.Request.Url
+		result.Host = resp.Request.Host
+		for k, v := range resp.Request.Input {
+			if k == "FUFFAHASH" {
+				result.FuffaHash = string(v)
+			} else {
+				result.Input[k] = string(v)
+			}
+		}
+	}
+	line, err := json.Marshal(result)
+	if err != nil {
+		h.err = err
+		return
+	}
+	_, h.err = h.file.Write(append(line, '\n'))
+}
+
+// Close writes the summary of the job to the history entry, errorCount being the error counter of the scan
+func (h *historyRecorder) Close(job HistoryJob, errorCount int) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.file != nil {
+		if err := h.file.Close(); err != nil && h.err == nil {
+			h.err = err
+		}
+		h.file = nil
+	}
+	job.Matches = h.matches
+	job.Errors = errorCount - h.errorCount
+	if h.redaction != nil {
+		job.Url = h.redaction.String(job.Url)
+	}
+	h.summary = &job
+	if err := h.writeSummary(); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// writeSummary writes the summary of the job to the history entry, the caller needs to hold the lock
+func (h *historyRecorder) writeSummary() error {
+	data, err := json.Marshal(h.summary)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(HISTORYDIR, h.hash, historyJobFile), data, 0640)
+}
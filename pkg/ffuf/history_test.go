@@ -0,0 +1,115 @@
+package ffuf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// emptyMatcherManager has no matchers and filters, for configs written to the history
+type emptyMatcherManager struct {
+	MatcherManager
+}
+
+func (m emptyMatcherManager) GetFilters() map[string]FilterProvider  { return nil }
+func (m emptyMatcherManager) GetMatchers() map[string]FilterProvider { return nil }
+
+func TestHistoryRecorder(t *testing.T) {
+	defer func(dir string) { HISTORYDIR = dir }(HISTORYDIR)
+	HISTORYDIR = t.TempDir()
+
+	conf := NewConfig(context.Background(), func() {})
+	conf.MatcherManager = emptyMatcherManager{}
+	conf.Url = "http://example.com/FUZZ"
+	hash, err := WriteHistoryEntry(&conf, 0)
+	if err != nil {
+		t.Fatalf("Error writing the history entry: %s", err)
+	}
+	recorder := newHistoryRecorder(hash, &conf, 3)
+	req := &Request{Url: "http://example.com/admin", Position: 2, Input: map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte(hash[:5] + "2")}}
+	recorder.Result(Response{StatusCode: 200, ContentLength: 42, Request: req})
+	if err := recorder.Close(HistoryJob{Url: conf.Url, Requests: 10}, 5); err != nil {
+		t.Fatalf("Error closing the history recorder: %s", err)
+	}
+
+	entries, err := FindHistory(hash[:5])
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected a single history entry, got %v (%v)", entries, err)
+	}
+	job := entries[0].Job
+	if job == nil || job.Matches != 1 || job.Requests != 10 || job.Errors != 2 {
+		t.Errorf("Expected the job summary with 1 match, 10 requests and 2 errors, got %+v", job)
+	}
+	results, err := HistoryResults(hash)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected a single result, got %v (%v)", results, err)
+	}
+	if results[0].Input["FUZZ"] != "admin" || results[0].FuffaHash != hash[:5]+"2" || results[0].StatusCode != 200 {
+		t.Errorf("Unexpected result %+v", results[0])
+	}
+}
+
+func TestHistoryRecorderLateResult(t *testing.T) {
+	defer func(dir string) { HISTORYDIR = dir }(HISTORYDIR)
+	HISTORYDIR = t.TempDir()
+
+	conf := NewConfig(context.Background(), func() {})
+	conf.MatcherManager = emptyMatcherManager{}
+	conf.Url = "http://example.com/FUZZ"
+	hash, err := WriteHistoryEntry(&conf, 0)
+	if err != nil {
+		t.Fatalf("Error writing the history entry: %s", err)
+	}
+	recorder := newHistoryRecorder(hash, &conf, 0)
+	if err := recorder.Close(HistoryJob{Url: conf.Url, Requests: 10}, 0); err != nil {
+		t.Fatalf("Error closing the history recorder: %s", err)
+	}
+	// an out-of-band interaction caught after the job finished
+	fuffahash := NewFuffaHash(hash, 1, 3).String()
+	req := &Request{Url: "http://example.com/ssrf", Position: 3, Input: map[string][]byte{"FUZZ": []byte("ssrf"), "FUFFAHASH": []byte(fuffahash)}}
+	recorder.Result(Response{ContentType: "oob/dns", Request: req})
+
+	entries, err := FindHistory(hash)
+	if err != nil || len(entries) != 1 || entries[0].Job == nil || entries[0].Job.Matches != 1 || entries[0].Job.Requests != 10 {
+		t.Fatalf("Expected the late result in the job summary, got %+v (%v)", entries, err)
+	}
+	results, err := HistoryResults(hash)
+	if err != nil || len(results) != 1 || results[0].FuffaHash != fuffahash {
+		t.Errorf("Expected the late result in the results, got %+v (%v)", results, err)
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	defer func(dir string) { HISTORYDIR = dir }(HISTORYDIR)
+	HISTORYDIR = t.TempDir()
+
+	conf := NewConfig(context.Background(), func() {})
+	conf.MatcherManager = emptyMatcherManager{}
+	hashes := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		conf.Url = "http://example.com/" + string(rune('a'+i))
+		hash, err := WriteHistoryEntry(&conf, 0)
+		if err != nil {
+			t.Fatalf("Error writing the history entry: %s", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	// age the first entry, that has no time like the entries of older versions
+	os.WriteFile(filepath.Join(HISTORYDIR, hashes[0], historyOptionsFile), []byte(`{}`), 0640)
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(HISTORYDIR, hashes[0], historyOptionsFile), old, old)
+
+	removed, err := PruneHistory(24*time.Hour, 0)
+	if err != nil || len(removed) != 1 || removed[0].Hash != hashes[0] {
+		t.Errorf("Expected the expired entry to be removed, got %v (%v)", removed, err)
+	}
+	removed, err = PruneHistory(0, 1)
+	if err != nil || len(removed) != 1 || removed[0].Hash != hashes[1] {
+		t.Errorf("Expected the oldest entry to be removed, got %v (%v)", removed, err)
+	}
+	if entries, _ := ListHistory(); len(entries) != 1 || entries[0].Hash != hashes[2] {
+		t.Errorf("Expected the newest entry to be kept, got %v", entries)
+	}
+}
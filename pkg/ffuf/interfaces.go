@@ -12,6 +12,8 @@ type MatcherManager interface {
 	AddPerDomainFilter(domain string, name string, option string) error
 	RemoveFilter(name string)
 	AddMatcher(name string, option string) error
+	AddMatcherProvider(name string, provider FilterProvider)
+	AddFilterProvider(name string, provider FilterProvider)
 	GetFilters() map[string]FilterProvider
 	GetMatchers() map[string]FilterProvider
 	FiltersForDomain(domain string) map[string]FilterProvider
@@ -37,9 +39,11 @@ type RunnerProvider interface {
 type InputProvider interface {
 	ActivateKeywords([]string)
 	AddProvider(InputProviderConfig) error
+	Feed(keyword string, values [][]byte) (int, error)
 	Keywords() []string
 	Next() bool
 	Position() int
+	Prepend(keyword string, values [][]byte) ([][]byte, error)
 	SetPosition(int)
 	Reset()
 	Value() map[string][]byte
@@ -72,6 +76,7 @@ type OutputProvider interface {
 	Warning(warnstring string)
 	Result(resp Response)
 	PrintResult(res Result)
+	Event(event JobEvent)
 	SaveFile(filename, format string) error
 	GetCurrentResults() []Result
 	SetCurrentResults(results []Result)
@@ -86,9 +91,53 @@ type AuditLogger interface {
 	Write(data interface{}) error
 }
 
+// OOBProvider is an out-of-band interaction listener catching the callbacks of blind payloads
+type OOBProvider interface {
+	// Payloads returns the values of the OOB keywords for the FUFFAHASH of a request
+	Payloads(fuffahash string) map[string][]byte
+	// Interactions returns the channel of the caught interactions, closed by Close
+	Interactions() <-chan OOBInteraction
+	Close() error
+}
+
+// SessionProvider keeps the scan authenticated, logging in before the scan and again when the session expires
+type SessionProvider interface {
+	// Login runs the login macro, unless the session of the generation has already been renewed
+	Login(generation int) error
+	// Apply adds the session cookies and headers to a prepared request, and returns the generation of the session
+	Apply(req *Request) int
+	// Check stores the cookies set by a response to a request of the generation, and returns true if the session
+	// has expired
+	Check(resp *Response, generation int) bool
+}
+
+// PreflightProvider fetches a fresh token for the CSRFTOKEN keyword before each request. Each worker of the pool
+// has its own cookie jar, and is held by a single request.
+type PreflightProvider interface {
+	// Fetch sends the pre-flight request with the cookies of a free worker, and returns the worker and the token
+	Fetch(input map[string][]byte) (int, []byte, error)
+	// Apply adds the cookies of the worker to a prepared request
+	Apply(worker int, req *Request)
+	// Release stores the cookies set by the response, if any, and frees the worker
+	Release(worker int, resp *Response)
+}
+
+// SignerProvider signs the prepared requests, like with AWS SigV4 or a HMAC, just before they are sent
+type SignerProvider interface {
+	Sign(req *Request) error
+}
+
+// Crawler extracts links from responses for the link crawler
+type Crawler interface {
+	Links(resp *Response) []string
+}
+
 type Scraper interface {
 	Execute(resp *Response, matched bool) []ScraperResult
 	AppendFromFile(path string) error
+	Matchers() map[string]FilterProvider
+	Filters() map[string]FilterProvider
+	Warnings() []string
 }
 
 type ScraperResult struct {
@@ -110,6 +159,7 @@ type Result struct {
 	Url              string              `json:"url"`
 	Duration         time.Duration       `json:"duration"`
 	ScraperData      map[string][]string `json:"scraper"`
+	Seed             string              `json:"seed,omitempty"`
 	ResultFile       string              `json:"resultfile"`
 	Host             string              `json:"host"`
 	HTMLColor        string              `json:"-"`
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -20,8 +21,16 @@ type Job struct {
 	Runner               RunnerProvider
 	ReplayRunner         RunnerProvider
 	Scraper              Scraper
+	Crawler              Crawler
+	OOB                  OOBProvider
+	Session              SessionProvider
+	Preflight            PreflightProvider
+	Signer               SignerProvider
 	Output               OutputProvider
 	Jobhash              string
+	history              *historyRecorder
+	histories            map[int]*historyRecorder
+	historyMutex         sync.Mutex
 	Counter              int
 	ErrorCounter         int
 	SpuriousErrorCounter int
@@ -39,7 +48,17 @@ type Job struct {
 	queuepos             int
 	skipQueue            bool
 	currentDepth         int
+	crawlSeen            map[string]bool
+	seeds                map[string]string
+	seedCount            int
+	scraperSummary       map[string]map[string]*scraperFinding
+	summaryMutex         sync.Mutex
+	crawlMutex           sync.Mutex
+	queueMutex           sync.Mutex
 	calibMutex           sync.Mutex
+	oobRequests          map[string]Request
+	oobMutex             sync.Mutex
+	oobDone              chan struct{}
 	pauseWg              sync.WaitGroup
 }
 
@@ -61,6 +80,10 @@ func NewJob(conf *Config) *Job {
 	j.queuepos = 0
 	j.queuejobs = make([]QueueJob, 0)
 	j.currentDepth = 0
+	j.crawlSeen = make(map[string]bool)
+	j.histories = make(map[int]*historyRecorder)
+	j.scraperSummary = make(map[string]map[string]*scraperFinding)
+	j.oobRequests = make(map[string]Request)
 	j.Rate = NewRateThrottle(conf)
 	j.skipQueue = false
 	return &j
@@ -97,13 +120,38 @@ func (j *Job) resetSpuriousErrors() {
 
 // DeleteQueueItem deletes a recursion job from the queue by its index in the slice
 func (j *Job) DeleteQueueItem(index int) {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
 	index = j.queuepos + index - 1
 	j.queuejobs = append(j.queuejobs[:index], j.queuejobs[index+1:]...)
 }
 
-// QueuedJobs returns the slice of queued recursive jobs
+// QueuedJobs returns a copy of the queued recursive jobs
 func (j *Job) QueuedJobs() []QueueJob {
-	return j.queuejobs[j.queuepos-1:]
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	return append([]QueueJob{}, j.queuejobs[j.queuepos-1:]...)
+}
+
+// enqueue adds a job to the queue, it is called from the worker goroutines while the queue is being run
+func (j *Job) enqueue(newJob QueueJob) {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	j.queuejobs = append(j.queuejobs, newJob)
+}
+
+// queueJob returns the job at index of the queue
+func (j *Job) queueJob(index int) QueueJob {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	return j.queuejobs[index]
+}
+
+// queueLength returns the number of jobs in the queue, including the ones already run
+func (j *Job) queueLength() int {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	return len(j.queuejobs)
 }
 
 // Start the execution of the Job
@@ -118,12 +166,12 @@ func (j *Job) Start() {
 		// process multiple payload locations and create a queue job for each location
 		reqs := SniperRequests(&basereq, j.Config.InputProviders[0].Template)
 		for _, r := range reqs {
-			j.queuejobs = append(j.queuejobs, QueueJob{Url: j.Config.Url, depth: 0, req: r})
+			j.enqueue(QueueJob{Url: j.Config.Url, depth: 0, req: r})
 		}
 		j.Total = j.Input.Total() * len(reqs)
 	} else {
 		// Add the default job to job queue
-		j.queuejobs = append(j.queuejobs, QueueJob{Url: j.Config.Url, depth: 0, req: BaseRequest(j.Config)})
+		j.enqueue(QueueJob{Url: j.Config.Url, depth: 0, req: BaseRequest(j.Config)})
 		j.Total = j.Input.Total()
 	}
 
@@ -144,19 +192,63 @@ func (j *Job) Start() {
 	
 	// Monitor for SIGTERM and do cleanup properly (writing the output files etc)
 	j.interruptMonitor()
+	if j.Config.Seed {
+		j.seed()
+	}
+	if j.Config.Crawl && j.Crawler != nil {
+		j.crawl()
+	}
+	if j.OOB != nil {
+		j.oobDone = make(chan struct{})
+		go j.handleOOBInteractions()
+	}
+	// The history is pruned once before the entries of this scan are written, so that the entries of the
+	// earlier queue jobs are kept for their late results
+	if j.Config.HistoryMaxAge > 0 || j.Config.HistoryMaxEntries > 0 {
+		if _, err := PruneHistory(j.Config.HistoryMaxAge, j.Config.HistoryMaxEntries); err != nil {
+			log.Printf("Could not prune the history: %s", err)
+		}
+	}
 	for j.jobsInQueue() {
 		j.prepareQueueJob()
 		j.Reset(true)
 		j.RunningJob = true
 		j.startExecution()
+		j.closeHistory()
+		if !j.RunningJob {
+			j.sendEvent(EVENT_JOB_ABORTED, j.Error)
+		} else if j.Running {
+			j.sendEvent(EVENT_JOB_FINISHED, "")
+		}
+	}
+	j.waitOOB()
+	if j.Running {
+		j.sendEvent(EVENT_SCAN_FINISHED, "")
+	} else {
+		j.sendEvent(EVENT_SCAN_ABORTED, j.Error)
 	}
 
+	j.printScraperSummary()
 	err := j.Output.Finalize()
 	if err != nil {
 		j.Output.Error(err.Error())
 	}
 }
 
+// sendEvent passes a change in the state of the scan to the output
+func (j *Job) sendEvent(eventType, reason string) {
+	j.Output.Event(JobEvent{
+		Type:       eventType,
+		Url:        j.Config.Url,
+		Reason:     strings.TrimSpace(reason),
+		Requests:   j.Counter,
+		Errors:     j.ErrorCounter,
+		QueuePos:   j.queuepos,
+		QueueTotal: j.queueLength(),
+		Time:       time.Now(),
+	})
+}
+
 // Reset resets the counters and wordlist position for a job
 func (j *Job) Reset(cycle bool) {
 	j.Input.Reset()
@@ -171,25 +263,60 @@ func (j *Job) Reset(cycle bool) {
 }
 
 func (j *Job) jobsInQueue() bool {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
 	return j.queuepos < len(j.queuejobs)
 }
 
 func (j *Job) prepareQueueJob() {
-	j.Config.Url = j.queuejobs[j.queuepos].Url
-	j.currentDepth = j.queuejobs[j.queuepos].depth
+	qj := j.queueJob(j.queuepos)
+	j.Config.Url = qj.Url
+	j.currentDepth = qj.depth
 
 	//Find all keywords present in new queued job
 	kws := j.Input.Keywords()
 	found_kws := make([]string, 0)
 	for _, k := range kws {
-		if RequestContainsKeyword(j.queuejobs[j.queuepos].req, k) {
+		if RequestContainsKeyword(qj.req, k) {
 			found_kws = append(found_kws, k)
 		}
 	}
 	//And activate / disable inputproviders as needed
 	j.Input.ActivateKeywords(found_kws)
+	j.queueMutex.Lock()
 	j.queuepos += 1
-	j.Jobhash, _ = WriteHistoryEntry(j.Config)
+	j.queueMutex.Unlock()
+	var err error
+	j.Jobhash, err = WriteHistoryEntry(j.Config, j.seedCount)
+	if err != nil {
+		log.Printf("Could not write the history entry: %s", err)
+		return
+	}
+	j.history = newHistoryRecorder(j.Jobhash, j.Config, j.ErrorCounter)
+	j.historyMutex.Lock()
+	j.histories[j.queuepos] = j.history
+	j.historyMutex.Unlock()
+}
+
+// closeHistory stores the summary of the finished job in its history entry
+func (j *Job) closeHistory() {
+	if j.history == nil {
+		return
+	}
+	summary := HistoryJob{
+		Url:      j.Config.Url,
+		Started:  j.startTimeJob,
+		Finished: time.Now(),
+		Requests: j.Counter,
+		Aborted:  !j.RunningJob || !j.Running,
+	}
+	if summary.Aborted {
+		summary.Reason = strings.TrimSpace(j.Error)
+	}
+	if err := j.history.Close(summary, j.ErrorCounter); err != nil {
+		log.Printf("Could not write the history of the job: %s", err)
+	}
+	j.history = nil
 }
 
 // SkipQueue allows to skip the current job and advance to the next queued recursion job
@@ -241,7 +368,7 @@ func (j *Job) startExecution() {
 	// Print the base URL when starting a new recursion or sniper queue job
 	if j.queuepos > 1 {
 		if j.Config.InputMode == "sniper" {
-			j.Output.Info(fmt.Sprintf("Starting queued sniper job (%d of %d) on target: %s", j.queuepos, len(j.queuejobs), j.Config.Url))
+			j.Output.Info(fmt.Sprintf("Starting queued sniper job (%d of %d) on target: %s", j.queuepos, j.queueLength(), j.Config.Url))
 		} else {
 			j.Output.Info(fmt.Sprintf("Starting queued job on target: %s", j.Config.Url))
 		}
@@ -250,39 +377,49 @@ func (j *Job) startExecution() {
 	//Limiter blocks after reaching the buffer, ensuring limited concurrency
 	threadlimiter := make(chan bool, j.Config.Threads)
 
-	for j.Input.Next() && !j.skipQueue {
-		// Check if we should stop the process
-		j.CheckStop()
+	var taskWg sync.WaitGroup
+	for {
+		for j.Input.Next() && !j.skipQueue {
+			// Check if we should stop the process
+			j.CheckStop()
 
-		if !j.Running {
-			defer j.Output.Warning(j.Error)
-			break
+			if !j.Running {
+				defer j.Output.Warning(j.Error)
+				break
+			}
+			j.pauseWg.Wait()
+			// Handle the rate & thread limiting
+			threadlimiter <- true
+			// Ratelimiter handles the rate ticker
+			<-j.Rate.RateLimiter.C
+			nextInput := j.Input.Value()
+			nextPosition := j.Input.Position()
+			// Add FUFFAHASH and its value
+			nextInput["FUFFAHASH"] = j.fuffahash(nextPosition)
+			j.addOOBPayloads(nextInput)
+
+			taskWg.Add(1)
+			j.Counter++
+
+			go func() {
+				defer func() { <-threadlimiter }()
+				defer taskWg.Done()
+				threadStart := time.Now()
+				j.runTask(nextInput, nextPosition, false, false)
+				j.sleepIfNeeded()
+				threadEnd := time.Now()
+				j.Rate.Tick(threadStart, threadEnd)
+			}()
+			if !j.RunningJob {
+				defer j.Output.Warning(j.Error)
+				break
+			}
 		}
-		j.pauseWg.Wait()
-		// Handle the rate & thread limiting
-		threadlimiter <- true
-		// Ratelimiter handles the rate ticker
-		<-j.Rate.RateLimiter.C
-		nextInput := j.Input.Value()
-		nextPosition := j.Input.Position()
-		// Add FUFFAHASH and its value
-		nextInput["FUFFAHASH"] = j.fuffahash(nextPosition)
-
-		wg.Add(1)
-		j.Counter++
-
-		go func() {
-			defer func() { <-threadlimiter }()
-			defer wg.Done()
-			threadStart := time.Now()
-			j.runTask(nextInput, nextPosition, false)
-			j.sleepIfNeeded()
-			threadEnd := time.Now()
-			j.Rate.Tick(threadStart, threadEnd)
-		}()
-		if !j.RunningJob {
-			defer j.Output.Warning(j.Error)
-			return
+		// the requests in flight are waited for also when the job is aborted, before its history is closed
+		taskWg.Wait()
+		// Scraper feed actions may have added new inputs while the last requests were in flight
+		if !j.Running || !j.RunningJob || j.skipQueue || j.Input.Position() >= j.Input.Total() {
+			break
 		}
 	}
 	wg.Wait()
@@ -307,14 +444,14 @@ func (j *Job) interruptMonitor() {
 
 func (j *Job) runBackgroundTasks(wg *sync.WaitGroup) {
 	defer wg.Done()
-	totalProgress := j.Input.Total()
-	for j.Counter <= totalProgress && !j.skipQueue {
+	// Total is re-read on every round as scraper feed actions can grow the input
+	for j.Counter <= j.Input.Total() && !j.skipQueue {
 		j.pauseWg.Wait()
 		if !j.Running {
 			break
 		}
 		j.updateProgress()
-		if j.Counter == totalProgress {
+		if j.Counter == j.Input.Total() {
 			return
 		}
 		if !j.RunningJob {
@@ -331,7 +468,7 @@ func (j *Job) updateProgress() {
 		ReqTotal:   j.Input.Total(),
 		ReqSec:     j.Rate.CurrentRate(),
 		QueuePos:   j.queuepos,
-		QueueTotal: len(j.queuejobs),
+		QueueTotal: j.queueLength(),
 		ErrorCount: j.ErrorCounter,
 	}
 	j.Output.Progress(prog)
@@ -389,30 +526,51 @@ func (j *Job) isMatch(resp Response) bool {
 	return true
 }
 
+// fuffahash returns the FUFFAHASH of the input position of the current queue job
 func (j *Job) fuffahash(pos int) []byte {
-	hashstring := ""
-	r := []rune(j.Jobhash)
-	if len(r) > 5 {
-		hashstring = string(r[:5])
-	}
-	hashstring += fmt.Sprintf("%x", pos)
-	return []byte(hashstring)
+	return []byte(NewFuffaHash(j.Jobhash, j.queuepos, pos).String())
 }
 
-func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
-	basereq := j.queuejobs[j.queuepos-1].req
+// runTask sends the request of the input. retried is set for the retry of a failed request, and sessionRetried
+// for the retry after logging in again, so that a request can be retried for both reasons.
+func (j *Job) runTask(input map[string][]byte, position int, retried, sessionRetried bool) {
+	basereq := j.queueJob(j.queuepos - 1).req
+	preflightWorker, err := j.preflight(input)
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Encountered an error while fetching the pre-flight token: %s\n", err))
+		j.incError()
+		log.Printf("%s", err)
+		return
+	}
 	req, err := j.Runner.Prepare(input, &basereq)
 	req.Timestamp = time.Now()
 
 	req.Position = position
 	if err != nil {
+		j.releasePreflight(preflightWorker, nil)
 		j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
 		j.incError()
 		log.Printf("%s", err)
 		return
 	}
+	sessionGeneration := 0
+	if j.Session != nil {
+		sessionGeneration = j.Session.Apply(&req)
+	}
+	j.applyPreflight(preflightWorker, &req)
+	if j.Signer != nil {
+		if err := j.Signer.Sign(&req); err != nil {
+			j.releasePreflight(preflightWorker, nil)
+			j.Output.Error(fmt.Sprintf("Encountered an error while signing request: %s\n", err))
+			j.incError()
+			log.Printf("%s", err)
+			return
+		}
+	}
+	j.registerOOBRequest(basereq, req)
 
 	resp, err := j.Runner.Execute(&req)
+	j.releasePreflight(preflightWorker, &resp)
 	if err != nil {
 		req.Error = err.Error()
 	}
@@ -430,7 +588,7 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 			j.incError()
 			log.Printf("%s", err)
 		} else {
-			j.runTask(input, position, true)
+			j.runTask(input, position, true, sessionRetried)
 		}
 		if os.IsTimeout(err) {
 			for name := range j.Config.MatcherManager.GetMatchers() {
@@ -465,6 +623,17 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 		}
 	}
 
+	// Log in again and retry the request if the session has expired
+	if j.Session != nil && j.Session.Check(&resp, sessionGeneration) && !sessionRetried {
+		if err := j.Session.Login(sessionGeneration); err != nil {
+			j.Output.Error(fmt.Sprintf("Could not renew the session: %s\n", err))
+			j.incError()
+		} else {
+			j.runTask(input, position, false, true)
+			return
+		}
+	}
+
 	if j.SpuriousErrorCounter > 0 {
 		j.resetSpuriousErrors()
 	}
@@ -485,6 +654,11 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 	// Handle autocalibration, must be done after the actual request to ensure sane value in req.Host
 	_ = j.CalibrateIfNeeded(HostURLFromRequest(req), input)
 
+	// Tag the responses for inputs found in the seeding stage
+	if src, ok := j.seeds[string(input["FUZZ"])]; ok {
+		resp.Seed = src
+	}
+
 	// Handle scraper actions
 	if j.Scraper != nil {
 		for _, sres := range j.Scraper.Execute(&resp, j.isMatch(resp)) {
@@ -503,20 +677,38 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 				j.incError()
 				log.Printf("%s", err)
 			} else {
+				if j.Session != nil {
+					j.Session.Apply(&replayreq)
+				}
+				if j.Signer != nil {
+					if err := j.Signer.Sign(&replayreq); err != nil {
+						j.Output.Error(fmt.Sprintf("Encountered an error while signing replayproxy request: %s\n", err))
+						log.Printf("%s", err)
+					}
+				}
 				_, _ = j.ReplayRunner.Execute(&replayreq)
 			}
 		}
 		j.Output.Result(resp)
+		if h := j.historyOf(req); h != nil {
+			h.Result(resp)
+		}
 
 		// Refresh the progress indicator as we printed something out
 		j.updateProgress()
 		if j.Config.Recursion && j.Config.RecursionStrategy == "greedy" {
 			j.handleGreedyRecursionJob(resp)
 		}
+		if j.Config.CrawlOnMatch && j.Crawler != nil {
+			j.handleCrawlLinks(j.Crawler.Links(&resp))
+		}
 	} else {
 		if len(resp.ScraperData) > 0 {
 			// print the result anyway, as scraper found something
 			j.Output.Result(resp)
+			if h := j.historyOf(req); h != nil {
+				h.Result(resp)
+			}
 		}
 	}
 
@@ -527,20 +719,43 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 
 func (j *Job) handleScraperResult(resp *Response, sres ScraperResult) {
 	for _, a := range sres.Action {
-		switch a {
-		case "output":
+		switch {
+		case a == "output":
 			resp.ScraperData[sres.Name] = sres.Results
+		case a == "summary":
+			j.addToScraperSummary(resp, sres)
+		case strings.HasPrefix(a, "feed:"):
+			j.feedInput(strings.TrimPrefix(a, "feed:"), sres.Results)
 		}
 	}
 }
 
+// feedInput adds values found by a scraper to the input of keyword, skipping the ones already known
+func (j *Job) feedInput(keyword string, values []string) {
+	inputs := make([][]byte, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) > 0 && !strings.ContainsAny(v, "\r\n") {
+			inputs = append(inputs, []byte(v))
+		}
+	}
+	added, err := j.Input.Feed(keyword, inputs)
+	if err != nil {
+		log.Printf("Could not feed new inputs for keyword %s: %s", keyword, err)
+		return
+	}
+	if added > 0 && j.Config.Verbose {
+		j.Output.Info(fmt.Sprintf("Added %d new inputs for keyword %s", added, keyword))
+	}
+}
+
 // handleGreedyRecursionJob adds a recursion job to the queue if the maximum depth has not been reached
 func (j *Job) handleGreedyRecursionJob(resp Response) {
 	// Handle greedy recursion strategy. Match has been determined before calling handleRecursionJob
 	if j.Config.RecursionDepth == 0 || j.currentDepth < j.Config.RecursionDepth {
 		recUrl := BuildRecursionURL(resp.Request.Url)
 		newJob := QueueJob{Url: recUrl, depth: j.currentDepth + 1, req: RecursionRequest(j.Config, recUrl)}
-		j.queuejobs = append(j.queuejobs, newJob)
+		j.enqueue(newJob)
 		j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
 	} else {
 		j.Output.Warning(fmt.Sprintf("Maximum recursion depth reached. Ignoring: %s", resp.Request.Url))
@@ -558,7 +773,7 @@ func (j *Job) handleDefaultRecursionJob(resp Response) {
 	if j.Config.RecursionDepth == 0 || j.currentDepth < j.Config.RecursionDepth {
 		// We have yet to reach the maximum recursion depth
 		newJob := QueueJob{Url: recUrl, depth: j.currentDepth + 1, req: RecursionRequest(j.Config, recUrl)}
-		j.queuejobs = append(j.queuejobs, newJob)
+		j.enqueue(newJob)
 		j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
 	} else {
 		j.Output.Warning(fmt.Sprintf("Directory found, but recursion depth exceeded. Ignoring: %s", resp.GetRedirectLocation(true)))
@@ -640,14 +855,31 @@ func (j *Job) executeDebugRequest() {
 	
 	// Add FUFFAHASH
 	firstInput["FUFFAHASH"] = j.fuffahash(firstPosition)
+	j.addOOBPayloads(firstInput)
 	
 	// Prepare and execute debug request
-	basereq := j.queuejobs[0].req
+	basereq := j.queueJob(0).req
+	preflightWorker, err := j.preflight(firstInput)
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Error fetching the pre-flight token of the debug request: %s", err))
+		return
+	}
+	defer j.releasePreflight(preflightWorker, nil)
 	req, err := j.Runner.Prepare(firstInput, &basereq)
 	if err != nil {
 		j.Output.Error(fmt.Sprintf("Error preparing debug request: %s", err))
 		return
 	}
+	if j.Session != nil {
+		j.Session.Apply(&req)
+	}
+	j.applyPreflight(preflightWorker, &req)
+	if j.Signer != nil {
+		if err := j.Signer.Sign(&req); err != nil {
+			j.Output.Error(fmt.Sprintf("Error signing debug request: %s", err))
+			return
+		}
+	}
 	
 	// Force debug printing for this request
 	j.forceDebugPrint(&req)
@@ -0,0 +1,130 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// OOB_KEYWORD is replaced with the callback host of the request, containing its FUFFAHASH
+	OOB_KEYWORD = "{{OOB}}"
+	// OOB_URL_KEYWORD is replaced with the callback URL of the request, containing its FUFFAHASH
+	OOB_URL_KEYWORD = "{{OOB_URL}}"
+)
+
+// OOBInteraction is a callback caught by the out-of-band listener
+type OOBInteraction struct {
+	// Protocol is http or dns
+	Protocol string
+	// FuffaHash is the FUFFAHASH found in the callback, or empty if it has none
+	FuffaHash  string
+	RemoteAddr string
+	// Raw is the raw HTTP request, or the type and name of the DNS query
+	Raw  string
+	Time time.Time
+}
+
+// usesOOB returns true if the request contains an OOB keyword
+func usesOOB(req Request) bool {
+	return RequestContainsKeyword(req, OOB_KEYWORD) || RequestContainsKeyword(req, OOB_URL_KEYWORD)
+}
+
+// addOOBPayloads adds the values of the OOB keywords, built from the FUFFAHASH of the input
+func (j *Job) addOOBPayloads(input map[string][]byte) {
+	if j.OOB == nil {
+		return
+	}
+	for k, v := range j.OOB.Payloads(string(input["FUFFAHASH"])) {
+		input[k] = v
+	}
+}
+
+// registerOOBRequest remembers a request containing an OOB keyword, to link its callbacks to it
+func (j *Job) registerOOBRequest(basereq Request, req Request) {
+	if j.OOB == nil || !usesOOB(basereq) {
+		return
+	}
+	j.oobMutex.Lock()
+	defer j.oobMutex.Unlock()
+	j.oobRequests[string(req.Input["FUFFAHASH"])] = req
+}
+
+// handleOOBInteractions reports the interactions caught by the listener until it is closed
+func (j *Job) handleOOBInteractions() {
+	defer close(j.oobDone)
+	for interaction := range j.OOB.Interactions() {
+		if j.AuditLogger != nil {
+			if err := j.AuditLogger.Write(&interaction); err != nil {
+				j.Output.Error(fmt.Sprintf("Encountered error while writing interaction audit log: %s\n", err))
+			}
+		}
+		j.oobMutex.Lock()
+		req, ok := j.oobRequests[interaction.FuffaHash]
+		j.oobMutex.Unlock()
+		if !ok {
+			j.Output.Warning(fmt.Sprintf("Out-of-band %s interaction from %s not linked to a request: %s", interaction.Protocol, interaction.RemoteAddr, firstLine(interaction.Raw)))
+			continue
+		}
+		resp := oobResponse(interaction, req)
+		j.Output.Result(resp)
+		if h := j.historyOf(req); h != nil {
+			h.Result(resp)
+		}
+		j.updateProgress()
+	}
+}
+
+// historyOf returns the history recorder of the queue job that made the request, resolved from its FUFFAHASH
+func (j *Job) historyOf(req Request) *historyRecorder {
+	hash, err := ParseFuffaHash(string(req.Input["FUFFAHASH"]))
+	if err != nil {
+		return nil
+	}
+	j.historyMutex.Lock()
+	defer j.historyMutex.Unlock()
+	return j.histories[hash.Queue]
+}
+
+// oobResponse returns the result reporting an interaction, linked to the request that caused it
+func oobResponse(interaction OOBInteraction, req Request) Response {
+	data := []byte(interaction.Raw)
+	return Response{
+		Data:          data,
+		ContentLength: int64(len(data)),
+		ContentWords:  int64(len(strings.Split(string(data), " "))),
+		ContentLines:  int64(len(strings.Split(string(data), "\n"))),
+		ContentType:   "oob/" + interaction.Protocol,
+		Request:       &req,
+		Raw:           interaction.Raw,
+		ScraperData: map[string][]string{
+			"oob": {fmt.Sprintf("%s interaction from %s at %s: %s", interaction.Protocol, interaction.RemoteAddr, interaction.Time.Format(time.RFC3339), firstLine(interaction.Raw))},
+		},
+		Timestamp: interaction.Time,
+	}
+}
+
+// waitOOB waits for the late callbacks after the scan, and stops the listener
+func (j *Job) waitOOB() {
+	if j.OOB == nil {
+		return
+	}
+	if j.Running && j.Config.OOBWait > 0 {
+		j.Output.Info(fmt.Sprintf("Waiting %d seconds for out-of-band interactions", j.Config.OOBWait))
+		select {
+		case <-j.Config.Context.Done():
+		case <-time.After(time.Duration(j.Config.OOBWait) * time.Second):
+		}
+	}
+	if err := j.OOB.Close(); err != nil {
+		j.Output.Error(fmt.Sprintf("Could not stop the out-of-band listener: %s", err))
+	}
+	<-j.oobDone
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i != -1 {
+		return s[:i]
+	}
+	return s
+}
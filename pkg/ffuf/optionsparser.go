@@ -1,10 +1,8 @@
 package ffuf
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -12,8 +10,10 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml"
+	"golang.org/x/net/http/httpguts"
 )
 
 type ConfigOptions struct {
@@ -27,17 +27,35 @@ type ConfigOptions struct {
 
 type HTTPOptions struct {
 	Cookies           []string `json:"-"` // this is appended in headers
+	CorrelationHeader string   `json:"correlation_header"`
+	CorrelationParam  string   `json:"correlation_param"`
+	Crawl             bool     `json:"crawl"`
+	CrawlMode         string   `json:"crawl_mode"`
+	CrawlOnMatch      bool     `json:"crawl_on_match"`
 	Data              string   `json:"data"`
 	FollowRedirects   bool     `json:"follow_redirects"`
 	Headers           []string `json:"headers"`
 	IgnoreBody        bool     `json:"ignore_body"`
 	Method            string   `json:"method"`
+	OOBDNS            string   `json:"oob_dns"`
+	OOBDomain         string   `json:"oob_domain"`
+	OOBHTTP           string   `json:"oob_http"`
+	OOBHost           string   `json:"oob_host"`
+	OOBWait           int      `json:"oob_wait"`
+	Preflight         string   `json:"preflight"`
+	PreflightExtract  string   `json:"preflight_extract"`
 	ProxyURL          string   `json:"proxy_url"`
 	Raw               bool     `json:"raw"`
 	Recursion         bool     `json:"recursion"`
 	RecursionDepth    int      `json:"recursion_depth"`
 	RecursionStrategy string   `json:"recursion_strategy"`
 	ReplayProxyURL    string   `json:"replay_proxy_url"`
+	Session           string   `json:"session"`
+	SignAWS           string   `json:"sign_aws"`
+	SignHMAC          string   `json:"sign_hmac"`
+	SignHMACHeader    string   `json:"sign_hmac_header"`
+	SignHMACKey       string   `json:"sign_hmac_key"`
+	SignHMACTemplate  string   `json:"sign_hmac_template"`
 	SNI               string   `json:"sni"`
 	Timeout           int      `json:"timeout"`
 	URL               string   `json:"url"`
@@ -56,6 +74,7 @@ type GeneralOptions struct {
 	ConfigFile                string   `toml:"-" json:"config_file"`
 	Delay                     string   `json:"delay"`
 	Json                      bool     `json:"json"`
+	ResultFormat              string   `json:"result_format"`
 	MaxTime                   int      `json:"maxtime"`
 	MaxTimeJob                int      `json:"maxtime_job"`
 	Noninteractive            bool     `json:"noninteractive"`
@@ -67,6 +86,8 @@ type GeneralOptions struct {
 	ShowVersion               bool     `toml:"-" json:"-"`
 	ShowItalianHelp           bool     `toml:"-" json:"-"`
 	DebugFirstRequest         bool     `json:"debug_first_request"`
+	HistoryMaxAge             string   `json:"history_max_age"`
+	HistoryMaxEntries         int      `json:"history_max_entries"`
 	StopOn403                 bool     `json:"stop_on_403"`
 	StopOnAll                 bool     `json:"stop_on_all"`
 	StopOnErrors              bool     `json:"stop_on_errors"`
@@ -85,6 +106,7 @@ type InputOptions struct {
 	Inputcommands          []string `json:"input_commands"`
 	Request                string   `json:"request_file"`
 	RequestProto           string   `json:"request_proto"`
+	Seed                   bool     `json:"seed"`
 	SubdomainEnumeration   string   `json:"subdomain_enumeration"`
 	VhostEnumeration       bool     `json:"vhost_enumeration"`
 	VhostDomain           string   `json:"vhost_domain"`
@@ -93,12 +115,27 @@ type InputOptions struct {
 }
 
 type OutputOptions struct {
-	AuditLog            string `json:"audit_log"`
-	DebugLog            string `json:"debug_log"`
-	OutputDirectory     string `json:"output_directory"`
-	OutputFile          string `json:"output_file"`
-	OutputFormat        string `json:"output_format"`
-	OutputSkipEmptyFile bool   `json:"output_skip_empty"`
+	AuditLog            string   `json:"audit_log"`
+	AuditLogFormat      string   `json:"audit_log_format"`
+	AuditLogCompress    bool     `json:"audit_log_compress"`
+	AuditLogMaxAge      string   `json:"audit_log_max_age"`
+	AuditLogMaxSize     int      `json:"audit_log_max_size"`
+	Baseline            string   `json:"baseline"`
+	DebugLog            string   `json:"debug_log"`
+	OutputDirectory     string   `json:"output_directory"`
+	OutputFile          string   `json:"output_file"`
+	OutputFormat        string   `json:"output_format"`
+	OutputSkipEmptyFile bool     `json:"output_skip_empty"`
+	OutputSinks         []string `json:"output_sinks"`
+	Redact              bool     `json:"redact"`
+	RedactFields        []string `json:"redact_fields"`
+	RedactHeaders       []string `json:"redact_headers"`
+	RedactRegexes       []string `json:"redact_regexes"`
+	Webhook             string   `json:"webhook"`
+	WebhookBatch        int      `json:"webhook_batch"`
+	WebhookRetries      int      `json:"webhook_retries"`
+	WebhookSeverity     string   `json:"webhook_severity"`
+	WebhookTemplate     string   `json:"webhook_template"`
 }
 
 type FilterOptions struct {
@@ -121,6 +158,12 @@ type MatcherOptions struct {
 	Words  string `json:"words"`
 }
 
+// outputFormats are the supported output file formats, in addition to template:/path/to/file.tmpl
+var outputFormats = []string{"all", "json", "ejson", "html", "md", "csv", "ecsv", "jsonl", "sqlite", "har", "warc", "report"}
+
+// WebhookSeverities are the severities of the webhook notifications, from the least to the most severe
+var WebhookSeverities = []string{"info", "low", "medium", "high"}
+
 // NewConfigOptions returns a newly created ConfigOptions struct with default values
 func NewConfigOptions() *ConfigOptions {
 	c := &ConfigOptions{}
@@ -137,8 +180,11 @@ func NewConfigOptions() *ConfigOptions {
 	c.General.Colors = true
 	c.General.Delay = ""
 	c.General.Json = false
+	c.General.ResultFormat = ""
 	c.General.MaxTime = 0
 	c.General.MaxTimeJob = 0
+	c.General.HistoryMaxAge = ""
+	c.General.HistoryMaxEntries = 0
 	c.General.Noninteractive = false
 	c.General.Quiet = false
 	c.General.Rate = 0
@@ -153,17 +199,35 @@ func NewConfigOptions() *ConfigOptions {
 	c.General.StopOnErrors = false
 	c.General.Threads = 40
 	c.General.Verbose = false
+	c.HTTP.Crawl = false
+	c.HTTP.CrawlMode = "queue"
+	c.HTTP.CrawlOnMatch = false
+	c.HTTP.CorrelationHeader = ""
+	c.HTTP.CorrelationParam = ""
 	c.HTTP.Data = ""
 	c.HTTP.FollowRedirects = false
 	c.HTTP.IgnoreBody = false
 	c.HTTP.Method = ""
+	c.HTTP.Preflight = ""
+	c.HTTP.PreflightExtract = ""
 	c.HTTP.ProxyURL = ""
 	c.HTTP.Raw = false
 	c.HTTP.Recursion = false
 	c.HTTP.RecursionDepth = 0
 	c.HTTP.RecursionStrategy = "default"
 	c.HTTP.ReplayProxyURL = ""
+	c.HTTP.OOBDNS = ""
+	c.HTTP.OOBDomain = ""
+	c.HTTP.OOBHTTP = ""
+	c.HTTP.OOBHost = ""
+	c.HTTP.OOBWait = 5
 	c.HTTP.Timeout = 10
+	c.HTTP.Session = ""
+	c.HTTP.SignAWS = ""
+	c.HTTP.SignHMAC = ""
+	c.HTTP.SignHMACHeader = "X-Signature"
+	c.HTTP.SignHMACKey = ""
+	c.HTTP.SignHMACTemplate = ""
 	c.HTTP.SNI = ""
 	c.HTTP.URL = ""
 	c.HTTP.Http2 = false
@@ -175,6 +239,7 @@ func NewConfigOptions() *ConfigOptions {
 	c.Input.InputNum = 100
 	c.Input.Request = ""
 	c.Input.RequestProto = "https"
+	c.Input.Seed = false
 	c.Input.SubdomainEnumeration = ""
 	c.Input.VhostEnumeration = false
 	c.Input.VhostDomain = ""
@@ -187,11 +252,26 @@ func NewConfigOptions() *ConfigOptions {
 	c.Matcher.Time = ""
 	c.Matcher.Words = ""
 	c.Output.AuditLog = ""
+	c.Output.AuditLogFormat = "json"
+	c.Output.AuditLogCompress = false
+	c.Output.AuditLogMaxAge = ""
+	c.Output.AuditLogMaxSize = 0
+	c.Output.Baseline = ""
 	c.Output.DebugLog = ""
 	c.Output.OutputDirectory = ""
 	c.Output.OutputFile = ""
 	c.Output.OutputFormat = "json"
 	c.Output.OutputSkipEmptyFile = false
+	c.Output.OutputSinks = []string{}
+	c.Output.Redact = false
+	c.Output.RedactFields = []string{}
+	c.Output.RedactHeaders = []string{}
+	c.Output.RedactRegexes = []string{}
+	c.Output.Webhook = ""
+	c.Output.WebhookBatch = 1
+	c.Output.WebhookRetries = 3
+	c.Output.WebhookSeverity = "info"
+	c.Output.WebhookTemplate = ""
 	return c
 }
 
@@ -472,21 +552,113 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		}
 	}
 
+	// Verify the session file, which is parsed when the job is prepared
+	if parseOpts.HTTP.Session != "" {
+		if !FileExists(parseOpts.HTTP.Session) {
+			errs.Add(fmt.Errorf("Session file (-session) %s does not exist", parseOpts.HTTP.Session))
+		} else {
+			conf.SessionFile = parseOpts.HTTP.Session
+			// the login steps without a protocol of their own use the one of the raw requests
+			conf.RequestProto = parseOpts.Input.RequestProto
+		}
+	}
+
 	//Check the output file format option
 	if parseOpts.Output.OutputFile != "" {
 		//No need to check / error out if output file isn't defined
-		outputFormats := []string{"all", "json", "ejson", "html", "md", "csv", "ecsv"}
-		found := false
-		for _, f := range outputFormats {
-			if f == parseOpts.Output.OutputFormat {
-				conf.OutputFormat = f
-				found = true
-			}
+		if err := checkOutputFormat(parseOpts.Output.OutputFormat); err != nil {
+			errs.Add(fmt.Errorf("%s (-of)", err))
+		} else {
+			conf.OutputFormat = parseOpts.Output.OutputFormat
+		}
+	}
+
+	//Check the additional output sinks
+	for _, sink := range parseOpts.Output.OutputSinks {
+		if _, err := ParseOutputSink(sink); err != nil {
+			errs.Add(fmt.Errorf("%s (-sink)", err))
 		}
-		if !found {
-			errs.Add(fmt.Errorf("Unknown output file format (-of): %s", parseOpts.Output.OutputFormat))
+	}
+	conf.OutputSinks = parseOpts.Output.OutputSinks
+
+	//Check the audit log format option
+	if parseOpts.Output.AuditLog != "" {
+		switch parseOpts.Output.AuditLogFormat {
+		case "json", "warc":
+			conf.AuditLogFormat = parseOpts.Output.AuditLogFormat
+		default:
+			errs.Add(fmt.Errorf("Unknown audit log format (-audit-format): %s", parseOpts.Output.AuditLogFormat))
 		}
 	}
+	if parseOpts.Output.AuditLogMaxSize < 0 {
+		errs.Add(fmt.Errorf("Audit log maximum size (-audit-max-size) can not be negative"))
+	}
+	conf.AuditLogMaxSize = parseOpts.Output.AuditLogMaxSize
+	if parseOpts.Output.AuditLogMaxAge != "" {
+		maxAge, err := time.ParseDuration(parseOpts.Output.AuditLogMaxAge)
+		if err != nil || maxAge <= 0 {
+			errs.Add(fmt.Errorf("Audit log maximum age (-audit-max-age) must be a positive duration like 1h or 30m: %s", parseOpts.Output.AuditLogMaxAge))
+		} else {
+			conf.AuditLogMaxAge = maxAge
+		}
+	}
+	conf.AuditLogCompress = parseOpts.Output.AuditLogCompress
+
+	// Prepare the retention limits of the scan history
+	if parseOpts.General.HistoryMaxEntries < 0 {
+		errs.Add(fmt.Errorf("History maximum entries (-history-max-entries) can not be negative"))
+	}
+	conf.HistoryMaxEntries = parseOpts.General.HistoryMaxEntries
+	if parseOpts.General.HistoryMaxAge != "" {
+		maxAge, err := time.ParseDuration(parseOpts.General.HistoryMaxAge)
+		if err != nil || maxAge <= 0 {
+			errs.Add(fmt.Errorf("History maximum age (-history-max-age) must be a positive duration like 720h: %s", parseOpts.General.HistoryMaxAge))
+		} else {
+			conf.HistoryMaxAge = maxAge
+		}
+	}
+
+	//Prepare the redaction policy of the audit log, the history and the output files
+	conf.Redact = parseOpts.Output.Redact
+	conf.RedactFields = parseOpts.Output.RedactFields
+	conf.RedactHeaders = parseOpts.Output.RedactHeaders
+	conf.RedactRegexes = parseOpts.Output.RedactRegexes
+	if redaction, err := NewRedactionPolicy(conf.Redact, conf.RedactHeaders, conf.RedactRegexes, conf.RedactFields); err != nil {
+		errs.Add(fmt.Errorf("%s (-redact-regex)", err))
+	} else {
+		conf.Redaction = redaction
+	}
+
+	if parseOpts.Output.Webhook != "" {
+		if u, err := url.Parse(parseOpts.Output.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add(fmt.Errorf("Webhook URL (-webhook) must be an http or https URL: %s", parseOpts.Output.Webhook))
+		}
+		if parseOpts.Output.WebhookBatch < 1 {
+			errs.Add(fmt.Errorf("Webhook batch size (-webhook-batch) must be at least 1"))
+		}
+		if parseOpts.Output.WebhookRetries < 0 {
+			errs.Add(fmt.Errorf("Webhook retries (-webhook-retries) can not be negative"))
+		}
+		if !StrInSlice(parseOpts.Output.WebhookSeverity, WebhookSeverities) {
+			errs.Add(fmt.Errorf("Unknown webhook severity (-webhook-severity): %s, available severities are: %s",
+				parseOpts.Output.WebhookSeverity, strings.Join(WebhookSeverities, ", ")))
+		}
+		if parseOpts.Output.WebhookTemplate != "" && !FileExists(parseOpts.Output.WebhookTemplate) {
+			errs.Add(fmt.Errorf("Webhook template file (-webhook-template) does not exist: %s", parseOpts.Output.WebhookTemplate))
+		}
+	}
+	conf.Webhook = parseOpts.Output.Webhook
+	conf.WebhookBatch = parseOpts.Output.WebhookBatch
+	conf.WebhookRetries = parseOpts.Output.WebhookRetries
+	conf.WebhookSeverity = parseOpts.Output.WebhookSeverity
+	conf.WebhookTemplate = parseOpts.Output.WebhookTemplate
+
+	if parseOpts.Output.Baseline != "" {
+		if !FileExists(parseOpts.Output.Baseline) {
+			errs.Add(fmt.Errorf("Baseline output file (-baseline) does not exist: %s", parseOpts.Output.Baseline))
+		}
+		conf.Baseline = parseOpts.Output.Baseline
+	}
 
 	// Auto-calibration strings
 	if len(parseOpts.General.AutoCalibrationStrings) > 0 {
@@ -553,6 +725,8 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.StopOnErrors = parseOpts.General.StopOnErrors
 	conf.FollowRedirects = parseOpts.HTTP.FollowRedirects
 	conf.Raw = parseOpts.HTTP.Raw
+	conf.Crawl = parseOpts.HTTP.Crawl
+	conf.CrawlOnMatch = parseOpts.HTTP.CrawlOnMatch
 	conf.Recursion = parseOpts.HTTP.Recursion
 	conf.RecursionDepth = parseOpts.HTTP.RecursionDepth
 	conf.RecursionStrategy = parseOpts.HTTP.RecursionStrategy
@@ -571,6 +745,69 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.VhostEnumeration = parseOpts.Input.VhostEnumeration
 	conf.VhostDomain = parseOpts.Input.VhostDomain
 
+	// Verify the pre-flight request, which is read when the job is prepared
+	if parseOpts.HTTP.Preflight != "" {
+		if !FileExists(parseOpts.HTTP.Preflight) {
+			errs.Add(fmt.Errorf("Pre-flight request file (-preflight) %s does not exist", parseOpts.HTTP.Preflight))
+		} else if parseOpts.HTTP.PreflightExtract == "" {
+			errs.Add(fmt.Errorf("The pre-flight request (-preflight) needs a rule to extract the %s with (-preflight-extract)", CSRF_KEYWORD))
+		} else if !RequestContainsKeyword(BaseRequest(&conf), CSRF_KEYWORD) {
+			errs.Add(fmt.Errorf("The pre-flight request (-preflight) needs the %s keyword in the request", CSRF_KEYWORD))
+		} else {
+			conf.PreflightFile = parseOpts.HTTP.Preflight
+			conf.PreflightExtract = parseOpts.HTTP.PreflightExtract
+			conf.RequestProto = parseOpts.Input.RequestProto
+		}
+	}
+
+	// Prepare the out-of-band interaction listener
+	conf.OOBDNS = parseOpts.HTTP.OOBDNS
+	conf.OOBDomain = strings.Trim(strings.ToLower(parseOpts.HTTP.OOBDomain), ".")
+	conf.OOBHTTP = parseOpts.HTTP.OOBHTTP
+	conf.OOBHost = parseOpts.HTTP.OOBHost
+	conf.OOBWait = parseOpts.HTTP.OOBWait
+	if conf.OOBWait < 0 {
+		errs.Add(fmt.Errorf("Out-of-band wait time (-oob-wait) can not be negative"))
+	}
+	if conf.OOBDNS != "" && conf.OOBDomain == "" {
+		errs.Add(fmt.Errorf("The out-of-band DNS listener (-oob-dns) needs the callback domain delegated to it (-oob-domain)"))
+	}
+	if conf.OOBDNS == "" && conf.OOBHTTP == "" && usesOOB(BaseRequest(&conf)) {
+		errs.Add(fmt.Errorf("The %s and %s keywords need an out-of-band listener (-oob-http or -oob-dns)", OOB_KEYWORD, OOB_URL_KEYWORD))
+	}
+
+	// Prepare the correlation header and query parameter
+	if parseOpts.HTTP.CorrelationHeader != "" {
+		if !httpguts.ValidHeaderFieldName(parseOpts.HTTP.CorrelationHeader) {
+			errs.Add(fmt.Errorf("Correlation header (-correlation-header) \"%s\" is not a valid header name", parseOpts.HTTP.CorrelationHeader))
+		} else {
+			conf.CorrelationHeader = textproto.CanonicalMIMEHeaderKey(parseOpts.HTTP.CorrelationHeader)
+		}
+	}
+	conf.CorrelationParam = parseOpts.HTTP.CorrelationParam
+	if strings.ContainsAny(conf.CorrelationParam, "&=#? ") {
+		errs.Add(fmt.Errorf("Correlation query parameter (-correlation-param) \"%s\" can not contain &, =, #, ? or spaces", conf.CorrelationParam))
+	}
+
+	// Verify the request signing, the signer is created when the job is prepared
+	conf.SignAWS = parseOpts.HTTP.SignAWS
+	conf.SignHMAC = parseOpts.HTTP.SignHMAC
+	if conf.SignAWS != "" && conf.SignHMAC != "" {
+		errs.Add(fmt.Errorf("AWS signing (-sign-aws) and HMAC signing (-sign-hmac) can not be used together"))
+	}
+	if conf.SignHMAC != "" {
+		headerName := strings.TrimSpace(strings.SplitN(parseOpts.HTTP.SignHMACHeader, ":", 2)[0])
+		if parseOpts.HTTP.SignHMACKey == "" || parseOpts.HTTP.SignHMACTemplate == "" {
+			errs.Add(fmt.Errorf("HMAC signing (-sign-hmac) needs a key (-sign-hmac-key) and a canonical string template (-sign-hmac-template)"))
+		} else if !httpguts.ValidHeaderFieldName(headerName) {
+			errs.Add(fmt.Errorf("HMAC signature header (-sign-hmac-header) \"%s\" is not a valid header name", headerName))
+		} else {
+			conf.SignHMACHeader = parseOpts.HTTP.SignHMACHeader
+			conf.SignHMACKey = parseOpts.HTTP.SignHMACKey
+			conf.SignHMACTemplate = parseOpts.HTTP.SignHMACTemplate
+		}
+	}
+
 	// Check that fmode and mmode have sane values
 	valid_opmodes := []string{"and", "or"}
 	fmode_found := false
@@ -608,7 +845,7 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.Method = "POST"
 	}
 
-	conf.CommandLine = strings.Join(os.Args, " ")
+	conf.CommandLine = strings.Join(maskSignKeyArgs(os.Args), " ")
 
 	newInputProviders := []InputProviderConfig{}
 	for _, provider := range conf.InputProviders {
@@ -645,83 +882,127 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		}
 	}
 
+	// Do checks for the robots.txt, sitemap and well-known file seeding
+	if parseOpts.Input.Seed {
+		if !strings.HasSuffix(conf.Url, "FUZZ") {
+			errmsg := "When using -seed the URL (-u) must end with FUZZ keyword."
+			errs.Add(fmt.Errorf(errmsg))
+		}
+	}
+	conf.Seed = parseOpts.Input.Seed
+
+	// Do checks for the link crawler
+	if parseOpts.HTTP.Crawl || parseOpts.HTTP.CrawlOnMatch {
+		if !strings.HasSuffix(conf.Url, "FUZZ") {
+			errmsg := "When using -crawl or -crawl-match the URL (-u) must end with FUZZ keyword."
+			errs.Add(fmt.Errorf(errmsg))
+		}
+		if parseOpts.HTTP.CrawlMode != "queue" && parseOpts.HTTP.CrawlMode != "wordlist" {
+			errs.Add(fmt.Errorf("Unrecognized value for parameter crawl-mode: %s, valid values are: queue, wordlist", parseOpts.HTTP.CrawlMode))
+		}
+	}
+	conf.CrawlMode = parseOpts.HTTP.CrawlMode
+
 	// Make verbose mutually exclusive with json
 	if parseOpts.General.Verbose && parseOpts.General.Json {
 		errs.Add(fmt.Errorf("Cannot have -json and -v"))
 	}
+	if parseOpts.General.ResultFormat != "" {
+		if parseOpts.General.Json {
+			errs.Add(fmt.Errorf("Cannot have -json and -fmt"))
+		}
+		if _, err := ParseResultFormat(parseOpts.General.ResultFormat); err != nil {
+			errs.Add(err)
+		}
+		conf.ResultFormat = parseOpts.General.ResultFormat
+	}
 	return &conf, errs.ErrorOrNil()
 }
 
-func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
-	conf.RequestFile = parseOpts.Input.Request
-	conf.RequestProto = parseOpts.Input.RequestProto
-	file, err := os.Open(parseOpts.Input.Request)
-	if err != nil {
-		return fmt.Errorf("could not open request file: %s", err)
+// checkOutputFormat returns an error if the output file format is unknown, or the file of a template format is missing
+func checkOutputFormat(format string) error {
+	if strings.HasPrefix(format, "template:") {
+		if !FileExists(strings.TrimPrefix(format, "template:")) {
+			return fmt.Errorf("Output template file does not exist: %s", strings.TrimPrefix(format, "template:"))
+		}
+		return nil
 	}
-	defer file.Close()
-
-	r := bufio.NewReader(file)
-
-	s, err := r.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("could not read request: %s", err)
+	if !StrInSlice(format, outputFormats) {
+		return fmt.Errorf("Unknown output file format: %s", format)
 	}
-	parts := strings.Split(s, " ")
-	if len(parts) < 3 {
-		return fmt.Errorf("malformed request supplied")
+	if format == "sqlite" && !SQLiteSupported {
+		return fmt.Errorf("The sqlite output format is not available in this build of fuffa, it needs to be built with cgo (CGO_ENABLED=1)")
 	}
-	// Set the request Method
-	conf.Method = parts[0]
-
-	for {
-		line, err := r.ReadString('\n')
-		line = strings.TrimSpace(line)
+	return nil
+}
 
-		if err != nil || line == "" {
-			break
-		}
+// maskSignKey returns the HMAC signing key to store in the configs and the scan history, masked unless it is read
+// from the environment
+func maskSignKey(key string) string {
+	if key == "" || strings.HasPrefix(key, "env:") {
+		return key
+	}
+	return REDACTED
+}
 
-		p := strings.SplitN(line, ":", 2)
-		if len(p) != 2 {
+// maskSignKeyArgs returns the command line arguments with the HMAC signing key masked
+func maskSignKeyArgs(args []string) []string {
+	masked := make([]string, len(args))
+	copy(masked, args)
+	for i, arg := range masked {
+		name := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") || !strings.HasPrefix(name, "sign-hmac-key") {
 			continue
 		}
-
-		if strings.EqualFold(p[0], "content-length") {
-			continue
+		if value := strings.TrimPrefix(name, "sign-hmac-key="); value != name {
+			masked[i] = strings.TrimSuffix(arg, value) + maskSignKey(value)
+		} else if name == "sign-hmac-key" && i+1 < len(masked) {
+			masked[i+1] = maskSignKey(masked[i+1])
 		}
-
-		conf.Headers[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
 	}
+	return masked
+}
 
-	// Handle case with the full http url in path. In that case,
-	// ignore any host header that we encounter and use the path as request URL
-	if strings.HasPrefix(parts[1], "http") {
-		parsed, err := url.Parse(parts[1])
-		if err != nil {
-			return fmt.Errorf("could not parse request URL: %s", err)
-		}
-		conf.Url = parts[1]
-		conf.Headers["Host"] = parsed.Host
-	} else {
-		// Build the request URL from the request
-		conf.Url = parseOpts.Input.RequestProto + "://" + conf.Headers["Host"] + parts[1]
+// OutputSink is an additional output file, written in its own format and verbosity
+type OutputSink struct {
+	Format  string
+	Path    string
+	Verbose bool
+}
+
+// ParseOutputSink parses an additional output sink definition in FORMAT=PATH[,verbose] form
+func ParseOutputSink(sink string) (OutputSink, error) {
+	parts := strings.SplitN(sink, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return OutputSink{}, fmt.Errorf("Output sink should be in FORMAT=PATH[,verbose] form: %s", sink)
+	}
+	if parts[0] == "all" {
+		return OutputSink{}, fmt.Errorf("Output format all is not supported for output sinks: %s", sink)
+	}
+	s := OutputSink{Format: parts[0], Path: parts[1]}
+	if strings.HasSuffix(s.Path, ",verbose") {
+		s.Path = strings.TrimSuffix(s.Path, ",verbose")
+		s.Verbose = true
 	}
+	if s.Path == "" {
+		return OutputSink{}, fmt.Errorf("Output sink should be in FORMAT=PATH[,verbose] form: %s", sink)
+	}
+	return s, checkOutputFormat(s.Format)
+}
 
-	// Set the request body
-	b, err := io.ReadAll(r)
+func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
+	conf.RequestFile = parseOpts.Input.Request
+	conf.RequestProto = parseOpts.Input.RequestProto
+	req, err := ReadRawRequest(parseOpts.Input.Request, parseOpts.Input.RequestProto)
 	if err != nil {
-		return fmt.Errorf("could not read request body: %s", err)
+		return err
 	}
-	conf.Data = string(b)
-
-	// Remove newline (typically added by the editor) at the end of the file
-	//nolint:gosimple // we specifically want to remove just a single newline, not all of them
-	if strings.HasSuffix(conf.Data, "\r\n") {
-		conf.Data = conf.Data[:len(conf.Data)-2]
-	} else if strings.HasSuffix(conf.Data, "\n") {
-		conf.Data = conf.Data[:len(conf.Data)-1]
+	conf.Method = req.Method
+	for k, v := range req.Headers {
+		conf.Headers[k] = v
 	}
+	conf.Url = req.Url
+	conf.Data = string(req.Data)
 	return nil
 }
 
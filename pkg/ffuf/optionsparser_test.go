@@ -1,6 +1,7 @@
 package ffuf
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -178,3 +179,45 @@ func TestReplayProxyParsing(t *testing.T) {
 		t.Errorf("Expected proxy string with unsupported protocol to fail")
 	}
 }
+
+func TestParseOutputSink(t *testing.T) {
+	s, err := ParseOutputSink("jsonl=/tmp/out=1.jsonl")
+	if err != nil || s.Format != "jsonl" || s.Path != "/tmp/out=1.jsonl" || s.Verbose {
+		t.Errorf("Unexpected output sink parse result: %+v %v", s, err)
+	}
+	s, err = ParseOutputSink("csv=/tmp/out.csv,verbose")
+	if err != nil || s.Format != "csv" || s.Path != "/tmp/out.csv" || !s.Verbose {
+		t.Errorf("Unexpected verbose output sink parse result: %+v %v", s, err)
+	}
+	if _, err := ParseOutputSink("sqlite=/tmp/out.db"); (err == nil) != SQLiteSupported {
+		t.Errorf("Expected the sqlite output sink to be accepted only in the builds with cgo, got: %v", err)
+	}
+	for _, sink := range []string{"jsonl", "jsonl=", "jsonl=,verbose", "all=/tmp/out", "nope=/tmp/out", "template:/nonexistent.tmpl=/tmp/out"} {
+		if _, err := ParseOutputSink(sink); err == nil {
+			t.Errorf("Expected an error for invalid output sink %q", sink)
+		}
+	}
+}
+
+func TestSignKeyMasking(t *testing.T) {
+	args := []string{"fuffa", "-sign-hmac", "sha256", "-sign-hmac-key", "s3cr3t", "--sign-hmac-key=s3cr3t", "-w", "s3cr3t"}
+	masked := strings.Join(maskSignKeyArgs(args), " ")
+	if masked != "fuffa -sign-hmac sha256 -sign-hmac-key [REDACTED] --sign-hmac-key=[REDACTED] -w s3cr3t" {
+		t.Errorf("Unexpected masked command line: %s", masked)
+	}
+	if args[4] != "s3cr3t" {
+		t.Errorf("Expected the arguments to not be modified")
+	}
+	if masked := strings.Join(maskSignKeyArgs([]string{"fuffa", "-sign-hmac-key", "env:KEY"}), " "); masked != "fuffa -sign-hmac-key env:KEY" {
+		t.Errorf("Expected the environment variable reference to be kept, got: %s", masked)
+	}
+
+	conf := &Config{SignHMAC: "sha256", SignHMACKey: "s3cr3t", MatcherManager: emptyMatcherManager{}}
+	data, _ := json.Marshal(conf)
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Errorf("Expected the HMAC key to not be serialized: %s", data)
+	}
+	if opts := conf.ToOptions(); opts.HTTP.SignHMACKey != REDACTED {
+		t.Errorf("Expected the HMAC key of the options to be masked, got %s", opts.HTTP.SignHMACKey)
+	}
+}
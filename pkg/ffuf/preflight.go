@@ -0,0 +1,32 @@
+package ffuf
+
+// CSRF_KEYWORD is replaced with the token fetched by the pre-flight request of each request
+const CSRF_KEYWORD = "CSRFTOKEN"
+
+// preflight fetches a fresh token for the input, and returns the pre-flight worker that is held until the response
+// arrives, or -1 if there is no pre-flight request
+func (j *Job) preflight(input map[string][]byte) (int, error) {
+	if j.Preflight == nil {
+		return -1, nil
+	}
+	worker, token, err := j.Preflight.Fetch(input)
+	if err != nil {
+		return -1, err
+	}
+	input[CSRF_KEYWORD] = token
+	return worker, nil
+}
+
+// applyPreflight adds the cookies of the pre-flight worker to a prepared request
+func (j *Job) applyPreflight(worker int, req *Request) {
+	if worker >= 0 {
+		j.Preflight.Apply(worker, req)
+	}
+}
+
+// releasePreflight frees the pre-flight worker, storing the cookies set by the response
+func (j *Job) releasePreflight(worker int, resp *Response) {
+	if worker >= 0 {
+		j.Preflight.Release(worker, resp)
+	}
+}
@@ -4,6 +4,25 @@ import (
 	"time"
 )
 
+const (
+	EVENT_JOB_FINISHED  = "job_finished"
+	EVENT_JOB_ABORTED   = "job_aborted"
+	EVENT_SCAN_FINISHED = "scan_finished"
+	EVENT_SCAN_ABORTED  = "scan_aborted"
+)
+
+// JobEvent is a change in the state of the scan, like a queued job finishing or the scan getting aborted
+type JobEvent struct {
+	Type       string    `json:"type"`
+	Url        string    `json:"url"`
+	Reason     string    `json:"reason,omitempty"`
+	Requests   int       `json:"requests"`
+	Errors     int       `json:"errors"`
+	QueuePos   int       `json:"queue_pos"`
+	QueueTotal int       `json:"queue_total"`
+	Time       time.Time `json:"time"`
+}
+
 type Progress struct {
 	StartedAt  time.Time
 	ReqCount   int
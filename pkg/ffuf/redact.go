@@ -0,0 +1,250 @@
+package ffuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// REDACTED replaces the redacted values
+const REDACTED = "[REDACTED]"
+
+// defaultRedactHeaders and defaultRedactFields are redacted with -redact, in addition to the custom ones
+var defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token"}
+var defaultRedactFields = []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "api_key", "apikey"}
+
+// RedactionPolicy masks secrets in the requests, responses and configs written to the audit log, the scan history
+// and the output files: the values of the named headers, the values of the named JSON and form fields in the
+// bodies and query strings, and everything matching the regexes.
+type RedactionPolicy struct {
+	headers  map[string]bool
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+	// headerLine matches the named headers in raw HTTP messages and command lines
+	headerLine *regexp.Regexp
+	// formField matches the named fields in query strings and form bodies
+	formField *regexp.Regexp
+	// cookieFlag matches the cookie flags of the command line when the Cookie header is redacted
+	cookieFlag *regexp.Regexp
+}
+
+// NewRedactionPolicy returns the redaction policy of the named headers, regexes and JSON fields. The default
+// headers and fields are included if defaults is true. A nil policy is returned if there is nothing to redact.
+func NewRedactionPolicy(defaults bool, headers, patterns, fields []string) (*RedactionPolicy, error) {
+	if defaults {
+		headers = append(append([]string{}, defaultRedactHeaders...), headers...)
+		fields = append(append([]string{}, defaultRedactFields...), fields...)
+	}
+	if len(headers) == 0 && len(patterns) == 0 && len(fields) == 0 {
+		return nil, nil
+	}
+	p := &RedactionPolicy{headers: make(map[string]bool), fields: make(map[string]bool)}
+	var errs Multierror
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs.Add(fmt.Errorf("Bad redaction regex %s: %s", pattern, err))
+			continue
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	quoted := make([]string, 0, len(headers))
+	for _, h := range headers {
+		p.headers[strings.ToLower(h)] = true
+		quoted = append(quoted, regexp.QuoteMeta(h))
+	}
+	if len(quoted) > 0 {
+		p.headerLine = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)(\s*:\s*)[^"'\r\n]*`)
+	}
+	if p.headers["cookie"] {
+		p.cookieFlag = regexp.MustCompile(`(^|\s)(-b|-cookie|--cookie)(\s+)("[^"]*"|'[^']*'|\S+)`)
+	}
+	quoted = quoted[:0]
+	for _, f := range fields {
+		p.fields[strings.ToLower(f)] = true
+		quoted = append(quoted, regexp.QuoteMeta(url.QueryEscape(f)))
+	}
+	if len(quoted) > 0 {
+		p.formField = regexp.MustCompile(`(?i)(^|[?&;\s"'])(` + strings.Join(quoted, "|") + `)=[^&;\s"'#]*`)
+	}
+	return p, nil
+}
+
+// String redacts the regexes, the header lines and the form fields in a string, like a command line or an URL
+func (p *RedactionPolicy) String(s string) string {
+	if p.headerLine != nil {
+		s = p.headerLine.ReplaceAllString(s, "${1}${2}"+REDACTED)
+	}
+	if p.cookieFlag != nil {
+		s = p.cookieFlag.ReplaceAllString(s, "${1}${2}${3}"+REDACTED)
+	}
+	if p.formField != nil {
+		s = p.formField.ReplaceAllString(s, "${1}${2}="+REDACTED)
+	}
+	for _, re := range p.patterns {
+		s = re.ReplaceAllString(s, REDACTED)
+	}
+	return s
+}
+
+// Headers returns a copy of the headers with the values of the named headers and the regexes redacted
+func (p *RedactionPolicy) Headers(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if p.headers[strings.ToLower(k)] {
+			redacted[k] = REDACTED
+		} else {
+			redacted[k] = p.patternsOnly(v)
+		}
+	}
+	return redacted
+}
+
+// Body redacts a request or response body: the named fields of a JSON body, and the named form fields and the
+// regexes of any body
+func (p *RedactionPolicy) Body(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if len(p.fields) > 0 && json.Valid(body) {
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if decoder.Decode(&doc) == nil && p.redactJSON(doc) {
+			if redacted, err := json.Marshal(doc); err == nil {
+				body = redacted
+			}
+		}
+	}
+	s := string(body)
+	if p.formField != nil {
+		s = p.formField.ReplaceAllString(s, "${1}${2}="+REDACTED)
+	}
+	return []byte(p.patternsOnly(s))
+}
+
+// redactJSON replaces the values of the named fields at any depth, and returns true if anything was replaced
+func (p *RedactionPolicy) redactJSON(doc interface{}) bool {
+	changed := false
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			if p.fields[strings.ToLower(k)] {
+				d[k] = REDACTED
+				changed = true
+			} else if p.redactJSON(v) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, v := range d {
+			if p.redactJSON(v) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// Raw redacts a raw HTTP message: the request line and the headers as strings, and the body as a body
+func (p *RedactionPolicy) Raw(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	i := strings.Index(raw, "\r\n\r\n")
+	if i == -1 {
+		return p.String(raw)
+	}
+	return p.String(raw[:i+4]) + string(p.Body([]byte(raw[i+4:])))
+}
+
+func (p *RedactionPolicy) patternsOnly(s string) string {
+	for _, re := range p.patterns {
+		s = re.ReplaceAllString(s, REDACTED)
+	}
+	return s
+}
+
+// Request returns a redacted copy of the request
+func (p *RedactionPolicy) Request(req *Request) *Request {
+	redacted := *req
+	redacted.Url = p.String(req.Url)
+	redacted.Headers = p.Headers(req.Headers)
+	redacted.Data = p.Body(req.Data)
+	redacted.Raw = p.Raw(req.Raw)
+	return &redacted
+}
+
+// Response returns a redacted copy of the response and its request
+func (p *RedactionPolicy) Response(resp *Response) *Response {
+	redacted := *resp
+	if resp.Headers != nil {
+		redacted.Headers = make(map[string][]string, len(resp.Headers))
+		for k, values := range resp.Headers {
+			redactedValues := make([]string, 0, len(values))
+			for _, v := range values {
+				if p.headers[strings.ToLower(k)] {
+					redactedValues = append(redactedValues, REDACTED)
+				} else {
+					redactedValues = append(redactedValues, p.patternsOnly(v))
+				}
+			}
+			redacted.Headers[k] = redactedValues
+		}
+	}
+	redacted.Data = p.Body(resp.Data)
+	redacted.Raw = p.Raw(resp.Raw)
+	if resp.Request != nil {
+		redacted.Request = p.Request(resp.Request)
+	}
+	return &redacted
+}
+
+// Config returns a redacted copy of the config
+func (p *RedactionPolicy) Config(conf *Config) *Config {
+	redacted := *conf
+	redacted.CommandLine = p.String(conf.CommandLine)
+	redacted.Url = p.String(conf.Url)
+	redacted.Headers = p.Headers(conf.Headers)
+	redacted.Data = string(p.Body([]byte(conf.Data)))
+	redacted.ProxyURL = p.patternsOnly(redactURLPassword(conf.ProxyURL))
+	redacted.ReplayProxyURL = p.patternsOnly(redactURLPassword(conf.ReplayProxyURL))
+	return &redacted
+}
+
+// Redact returns a redacted copy of the audited data, or the data itself if it has nothing to redact
+func (p *RedactionPolicy) Redact(data interface{}) interface{} {
+	if p == nil {
+		return data
+	}
+	switch d := data.(type) {
+	case *Request:
+		return p.Request(d)
+	case *Response:
+		return p.Response(d)
+	case *Config:
+		return p.Config(d)
+	}
+	return data
+}
+
+// redactURLPassword masks the password of the user info of an URL, like the credentials of a proxy
+func redactURLPassword(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.User == nil {
+		return rawurl
+	}
+	if password, ok := u.User.Password(); ok && password != "" {
+		return strings.Replace(rawurl, u.User.String()+"@", url.User(u.User.Username()).String()+":"+REDACTED+"@", 1)
+	}
+	return rawurl
+}
@@ -1,6 +1,12 @@
 package ffuf
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 )
@@ -35,6 +41,80 @@ func BaseRequest(conf *Config) Request {
 	return req
 }
 
+// ReadRawRequest reads a raw HTTP request from a file. The request URL is built from the protocol and the Host
+// header, unless the request line has a full URL.
+func ReadRawRequest(filename string, proto string) (Request, error) {
+	req := Request{Headers: make(map[string]string)}
+	file, err := os.Open(filename)
+	if err != nil {
+		return req, fmt.Errorf("could not open request file: %s", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+
+	s, err := r.ReadString('\n')
+	if err != nil {
+		return req, fmt.Errorf("could not read request: %s", err)
+	}
+	parts := strings.Split(s, " ")
+	if len(parts) < 3 {
+		return req, fmt.Errorf("malformed request supplied")
+	}
+	// Set the request Method
+	req.Method = parts[0]
+
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimSpace(line)
+
+		if err != nil || line == "" {
+			break
+		}
+
+		p := strings.SplitN(line, ":", 2)
+		if len(p) != 2 {
+			continue
+		}
+
+		if strings.EqualFold(p[0], "content-length") {
+			continue
+		}
+
+		req.Headers[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
+	}
+
+	// Handle case with the full http url in path. In that case,
+	// ignore any host header that we encounter and use the path as request URL
+	if strings.HasPrefix(parts[1], "http") {
+		parsed, err := url.Parse(parts[1])
+		if err != nil {
+			return req, fmt.Errorf("could not parse request URL: %s", err)
+		}
+		req.Url = parts[1]
+		req.Headers["Host"] = parsed.Host
+	} else {
+		// Build the request URL from the request
+		req.Url = proto + "://" + req.Headers["Host"] + parts[1]
+	}
+
+	// Set the request body
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return req, fmt.Errorf("could not read request body: %s", err)
+	}
+	req.Data = b
+
+	// Remove newline (typically added by the editor) at the end of the file
+	//nolint:gosimple // we specifically want to remove just a single newline, not all of them
+	if bytes.HasSuffix(req.Data, []byte("\r\n")) {
+		req.Data = req.Data[:len(req.Data)-2]
+	} else if bytes.HasSuffix(req.Data, []byte("\n")) {
+		req.Data = req.Data[:len(req.Data)-1]
+	}
+	return req, nil
+}
+
 // RecursionRequest returns a base request for a recursion target
 func RecursionRequest(conf *Config, path string) Request {
 	r := BaseRequest(conf)
@@ -20,6 +20,7 @@ type Response struct {
 	Raw           string
 	ResultFile    string
 	ScraperData   map[string][]string
+	Seed          string
 	Duration      time.Duration
 	Timestamp     time.Time
 }
@@ -0,0 +1,109 @@
+package ffuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resultFormatFields are the placeholders available in the -fmt result line format, in addition to
+// input.KEYWORD and scraper.NAME
+var resultFormatFields = []string{"status", "size", "length", "words", "lines", "url", "redirect", "content-type",
+	"duration", "position", "host", "resultfile", "seed", "fuffahash"}
+
+// ResultFormat is a parsed single-line result format like "{status} {size} {url} {input.FUZZ}"
+type ResultFormat struct {
+	parts []resultFormatPart
+}
+
+// resultFormatPart is either literal text, or a placeholder when field is set
+type resultFormatPart struct {
+	literal string
+	field   string
+}
+
+// ParseResultFormat parses the format and validates its placeholders
+func ParseResultFormat(format string) (*ResultFormat, error) {
+	f := &ResultFormat{parts: make([]resultFormatPart, 0)}
+	rest := format
+	for len(rest) > 0 {
+		start := strings.Index(rest, "{")
+		if start == -1 {
+			f.parts = append(f.parts, resultFormatPart{literal: rest})
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated placeholder in result format: %s", rest[start:])
+		}
+		field := rest[start+1 : start+end]
+		if !validResultFormatField(field) {
+			return nil, fmt.Errorf("unknown placeholder {%s} in result format, available placeholders are: {%s}, {input.KEYWORD} and {scraper.NAME}",
+				field, strings.Join(resultFormatFields, "}, {"))
+		}
+		if start > 0 {
+			f.parts = append(f.parts, resultFormatPart{literal: rest[:start]})
+		}
+		f.parts = append(f.parts, resultFormatPart{field: field})
+		rest = rest[start+end+1:]
+	}
+	return f, nil
+}
+
+func validResultFormatField(field string) bool {
+	if strings.HasPrefix(field, "input.") || strings.HasPrefix(field, "scraper.") {
+		return !strings.HasSuffix(field, ".")
+	}
+	return StrInSlice(field, resultFormatFields)
+}
+
+// Render returns the result formatted as a single line
+func (f *ResultFormat) Render(r Result) string {
+	var sb strings.Builder
+	for _, p := range f.parts {
+		if p.field == "" {
+			sb.WriteString(p.literal)
+			continue
+		}
+		sb.WriteString(resultFormatValue(r, p.field))
+	}
+	return sb.String()
+}
+
+func resultFormatValue(r Result, field string) string {
+	switch {
+	case strings.HasPrefix(field, "input."):
+		return string(r.Input[strings.TrimPrefix(field, "input.")])
+	case strings.HasPrefix(field, "scraper."):
+		return strings.Join(r.ScraperData[strings.TrimPrefix(field, "scraper.")], ",")
+	}
+	switch field {
+	case "status":
+		return strconv.FormatInt(r.StatusCode, 10)
+	case "size", "length":
+		return strconv.FormatInt(r.ContentLength, 10)
+	case "words":
+		return strconv.FormatInt(r.ContentWords, 10)
+	case "lines":
+		return strconv.FormatInt(r.ContentLines, 10)
+	case "url":
+		return r.Url
+	case "redirect":
+		return r.RedirectLocation
+	case "content-type":
+		return r.ContentType
+	case "duration":
+		return strconv.FormatInt(r.Duration.Milliseconds(), 10) + "ms"
+	case "position":
+		return strconv.Itoa(r.Position)
+	case "host":
+		return r.Host
+	case "resultfile":
+		return r.ResultFile
+	case "seed":
+		return r.Seed
+	case "fuffahash":
+		return string(r.Input["FUFFAHASH"])
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package ffuf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultFormat(t *testing.T) {
+	r := Result{
+		Input:         map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte("abc")},
+		StatusCode:    301,
+		ContentLength: 42,
+		Url:           "http://example.com/admin",
+		Duration:      1500 * time.Millisecond,
+		ScraperData:   map[string][]string{"title": {"Admin", "Login"}},
+	}
+	tests := map[string]string{
+		"{status} {size} {url} {input.FUZZ}":   "301 42 http://example.com/admin admin",
+		"[{duration}] {scraper.title}":         "[1500ms] Admin,Login",
+		"{fuffahash}|{input.MISSING}|{length}": "abc||42",
+		"no placeholders":                      "no placeholders",
+	}
+	for format, expected := range tests {
+		f, err := ParseResultFormat(format)
+		if err != nil {
+			t.Errorf("Could not parse result format %q: %s", format, err)
+			continue
+		}
+		if got := f.Render(r); got != expected {
+			t.Errorf("Result format %q rendered %q, expected %q", format, got, expected)
+		}
+	}
+	for _, format := range []string{"{nope}", "{status", "{input.}"} {
+		if _, err := ParseResultFormat(format); err == nil {
+			t.Errorf("Expected an error for invalid result format %q", format)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ffuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SECRETS_SCRAPER_VERSION is the version of the built-in secrets scraper group. The group file in SCRAPERDIR
+// is overwritten when it was written by an older version, so customized rules should go to a separate file.
+const SECRETS_SCRAPER_VERSION = 1
+
+// defaultScraperRule and defaultScraperGroup mirror the scraper group file format of the scraper package
+type defaultScraperRule struct {
+	Name        string   `json:"name"`
+	Rule        string   `json:"rule"`
+	Target      string   `json:"target"`
+	Type        string   `json:"type"`
+	OnlyMatched bool     `json:"onlymatched"`
+	Action      []string `json:"action"`
+	MinEntropy  float64  `json:"minentropy,omitempty"`
+}
+
+type defaultScraperGroup struct {
+	Rules   []defaultScraperRule `json:"rules"`
+	Name    string               `json:"groupname"`
+	Active  bool                 `json:"active"`
+	Version int                  `json:"version"`
+}
+
+func secretsScraperGroup() defaultScraperGroup {
+	rule := func(name, expr string, minEntropy float64) defaultScraperRule {
+		return defaultScraperRule{
+			Name:        name,
+			Rule:        expr,
+			Target:      "all",
+			Type:        "regexp",
+			OnlyMatched: true,
+			Action:      []string{"output", "summary"},
+			MinEntropy:  minEntropy,
+		}
+	}
+	return defaultScraperGroup{
+		Name:    "secrets",
+		Active:  false,
+		Version: SECRETS_SCRAPER_VERSION,
+		Rules: []defaultScraperRule{
+			rule("aws_access_key_id", `\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`, 3.0),
+			rule("aws_secret_access_key", `(?i)aws.{0,20}?(?:secret|key).{0,20}?['"]([A-Za-z0-9/+=]{40})['"]`, 4.0),
+			rule("gcp_api_key", `\bAIza[0-9A-Za-z_\-]{35}\b`, 3.5),
+			rule("gcp_service_account", `"type"\s*:\s*"service_account"`, 0),
+			rule("azure_storage_key", `(?i)AccountKey=([A-Za-z0-9+/=]{88})`, 4.5),
+			rule("github_token", `\b(?:ghp|gho|ghu|ghs|ghr|github_pat)_[A-Za-z0-9_]{36,255}\b`, 3.5),
+			rule("slack_token", `\bxox[baprs]-[A-Za-z0-9-]{10,72}\b`, 3.0),
+			rule("stripe_key", `\b(?:sk|rk)_live_[0-9a-zA-Z]{24,99}\b`, 3.5),
+			rule("jwt", `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`, 4.0),
+			rule("private_key", `-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`, 0),
+			rule("generic_secret", `(?i)(?:api[_-]?key|apikey|secret|access[_-]?token|auth[_-]?token|client[_-]?secret|password)['"]?\s*[:=]\s*['"]([A-Za-z0-9_\-+/=.]{16,128})['"]`, 3.5),
+			rule("internal_ip", `\b(?:10(?:\.(?:25[0-5]|2[0-4]\d|1?\d?\d)){3}|(?:172\.(?:1[6-9]|2\d|3[01])|192\.168)(?:\.(?:25[0-5]|2[0-4]\d|1?\d?\d)){2})\b`, 0),
+			rule("stacktrace_java", `(?m)^\s*at [\w$.]+\([\w$]+\.java:\d+\)`, 0),
+			rule("stacktrace_python", `Traceback \(most recent call last\):`, 0),
+			rule("stacktrace_php", `(?:Fatal error|Warning|Parse error|Notice): .{1,200}? in /[^\s<]+\.php on line \d+`, 0),
+			rule("stacktrace_dotnet", `(?:Server Error in '[^']*' Application\.|System\.[\w.]+Exception:)`, 0),
+			rule("stacktrace_node", `(?m)^\s+at .{1,200}?\(/[^)\s]+\.js:\d+:\d+\)`, 0),
+		},
+	}
+}
+
+// setupDefaultScrapers writes the built-in scraper groups to SCRAPERDIR if they are missing or outdated
+func setupDefaultScrapers() error {
+	secrets := secretsScraperGroup()
+	secretsFile := filepath.Join(SCRAPERDIR, "secrets.json")
+	if FileExists(secretsFile) {
+		existing := defaultScraperGroup{}
+		data, err := os.ReadFile(secretsFile)
+		if err == nil && json.Unmarshal(data, &existing) == nil && existing.Version >= secrets.Version {
+			return nil
+		}
+	}
+	secretsJson, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(secretsFile, secretsJson, 0640)
+}
+
+// scraperFinding is a deduplicated value found by a scraper rule with the summary action
+type scraperFinding struct {
+	count    int
+	firstUrl string
+}
+
+// addToScraperSummary records the results of a scraper rule to the summary printed at the end of the scan
+func (j *Job) addToScraperSummary(resp *Response, sres ScraperResult) {
+	j.summaryMutex.Lock()
+	defer j.summaryMutex.Unlock()
+	if _, ok := j.scraperSummary[sres.Name]; !ok {
+		j.scraperSummary[sres.Name] = make(map[string]*scraperFinding)
+	}
+	seen := make(map[string]bool)
+	for _, r := range sres.Results {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		if f, ok := j.scraperSummary[sres.Name][r]; ok {
+			f.count++
+			continue
+		}
+		j.scraperSummary[sres.Name][r] = &scraperFinding{count: 1, firstUrl: resp.Request.Url}
+	}
+}
+
+// printScraperSummary prints the aggregated scraper findings of the whole scan
+func (j *Job) printScraperSummary() {
+	j.summaryMutex.Lock()
+	defer j.summaryMutex.Unlock()
+	if len(j.scraperSummary) == 0 {
+		return
+	}
+	names := make([]string, 0, len(j.scraperSummary))
+	for name := range j.scraperSummary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	total := 0
+	for _, name := range names {
+		values := make([]string, 0, len(j.scraperSummary[name]))
+		for v := range j.scraperSummary[name] {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		fmt.Fprintf(&sb, "  %s:\n", name)
+		for _, v := range values {
+			f := j.scraperSummary[name][v]
+			fmt.Fprintf(&sb, "    %s (%d responses, first seen at %s)\n", strings.ReplaceAll(v, "\n", "\\n"), f.count, f.firstUrl)
+			total++
+		}
+	}
+	j.Output.Info(fmt.Sprintf("Scraper summary, %d unique findings:", total))
+	j.Output.Raw(sb.String())
+}
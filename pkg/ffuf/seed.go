@@ -0,0 +1,206 @@
+package ffuf
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// seedMaxSitemaps is the upper limit of sitemap files fetched per host, including nested sitemap indexes
+const seedMaxSitemaps = 10
+
+// seedSources are the well-known files fetched from each target host in the seeding stage, and the
+// parsers extracting paths and further sitemap locations from them
+var seedSources = []struct {
+	path  string
+	parse func(data string) ([]string, []string)
+}{
+	{"robots.txt", parseRobots},
+	{"sitemap.xml", parseSitemap},
+	{".well-known/security.txt", parseSecurityTxt},
+	{"security.txt", parseSecurityTxt},
+	{"humans.txt", parseSecurityTxt},
+}
+
+// seed fetches robots.txt, sitemaps and well-known files from the target hosts and prepends the
+// discovered paths to the FUZZ input
+func (j *Job) seed() {
+	bases := make([]*url.URL, 0)
+	seenHosts := make(map[string]bool)
+	for _, qj := range j.queuejobs {
+		base, err := url.Parse(strings.TrimSuffix(qj.Url, "FUZZ"))
+		if err != nil || seenHosts[base.Scheme+"://"+base.Host] {
+			continue
+		}
+		seenHosts[base.Scheme+"://"+base.Host] = true
+		bases = append(bases, base)
+	}
+	sources := make(map[string]string)
+	values := make([][]byte, 0)
+	for _, base := range bases {
+		j.Output.Info(fmt.Sprintf("Seeding paths from robots.txt, sitemaps and well-known files of %s://%s", base.Scheme, base.Host))
+		for _, s := range j.seedHost(base) {
+			if _, ok := sources[s.path]; !ok {
+				sources[s.path] = s.source
+				values = append(values, []byte(s.path))
+			}
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	added, err := j.Input.Prepend("FUZZ", values)
+	if err != nil {
+		j.Output.Warning(fmt.Sprintf("Could not seed the discovered paths: %s", err))
+		return
+	}
+	// only the new paths are tagged, the ones already in the wordlist keep their normal results
+	j.seeds = make(map[string]string, len(added))
+	for _, v := range added {
+		j.seeds[string(v)] = sources[string(v)]
+	}
+	j.seedCount = len(added)
+	j.Output.Info(fmt.Sprintf("Seeded %d new paths to the FUZZ input", len(added)))
+}
+
+type seedPath struct {
+	path   string
+	source string
+}
+
+// seedHost fetches and parses the seed sources of a single host, returning the discovered paths relative to base
+func (j *Job) seedHost(base *url.URL) []seedPath {
+	paths := make([]seedPath, 0)
+	origin := base.Scheme + "://" + base.Host
+	sitemaps := make([]string, 0)
+	addPaths := func(links []string, source string) {
+		for _, l := range links {
+			if p, ok := seedRelativePath(base, l); ok {
+				paths = append(paths, seedPath{path: p, source: source})
+			}
+		}
+	}
+	for _, src := range seedSources {
+		resp, err := j.fetchURL(origin + "/" + src.path)
+		if err != nil || resp.StatusCode != 200 {
+			continue
+		}
+		if p, ok := seedRelativePath(base, "/"+src.path); ok {
+			paths = append(paths, seedPath{path: p, source: src.path})
+		}
+		links, more := src.parse(string(resp.Data))
+		addPaths(links, src.path)
+		sitemaps = append(sitemaps, more...)
+	}
+	fetched := map[string]bool{origin + "/sitemap.xml": true}
+	for i := 0; i < len(sitemaps) && len(fetched) < seedMaxSitemaps; i++ {
+		loc, err := url.Parse(origin + "/")
+		if err != nil {
+			break
+		}
+		loc, err = loc.Parse(sitemaps[i])
+		if err != nil || !UrlEqual(base, loc) || fetched[loc.String()] {
+			continue
+		}
+		fetched[loc.String()] = true
+		resp, err := j.fetchURL(loc.String())
+		if err != nil || resp.StatusCode != 200 {
+			continue
+		}
+		addPaths([]string{loc.Path}, "sitemap")
+		links, more := parseSitemap(string(resp.Data))
+		addPaths(links, "sitemap")
+		sitemaps = append(sitemaps, more...)
+	}
+	return paths
+}
+
+// seedRelativePath returns the path of link relative to base, if the link is within base
+func seedRelativePath(base *url.URL, link string) (string, bool) {
+	u, err := base.Parse(link)
+	if err != nil || !UrlEqual(base, u) || !strings.HasPrefix(u.Path, base.Path) {
+		return "", false
+	}
+	rel := strings.TrimPrefix(u.Path, base.Path)
+	if len(rel) == 0 || strings.ContainsAny(rel, "\r\n") {
+		return "", false
+	}
+	return rel, true
+}
+
+// parseRobots returns the Allow and Disallow paths, and the Sitemap locations from a robots.txt file.
+// Wildcard rules are cut at the first wildcard character.
+func parseRobots(data string) ([]string, []string) {
+	paths := make([]string, 0)
+	sitemaps := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		val := strings.TrimSpace(parts[1])
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "allow", "disallow":
+			if i := strings.Index(val, "*"); i >= 0 {
+				val = val[:i]
+			}
+			val = strings.TrimSuffix(val, "$")
+			if strings.Trim(val, "/") != "" {
+				paths = append(paths, val)
+			}
+		case "sitemap":
+			if len(val) > 0 {
+				sitemaps = append(sitemaps, val)
+			}
+		}
+	}
+	return paths, sitemaps
+}
+
+// parseSitemap returns the page locations of a sitemap, or the nested sitemap locations of a sitemap index
+func parseSitemap(data string) ([]string, []string) {
+	var sitemap struct {
+		URLs     []string `xml:"url>loc"`
+		Sitemaps []string `xml:"sitemap>loc"`
+	}
+	if err := xml.Unmarshal([]byte(data), &sitemap); err != nil {
+		return []string{}, []string{}
+	}
+	return trimAll(sitemap.URLs), trimAll(sitemap.Sitemaps)
+}
+
+// parseSecurityTxt returns the URLs found from the field values of security.txt and humans.txt style files
+func parseSecurityTxt(data string) ([]string, []string) {
+	links := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		val := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(val, "http://") || strings.HasPrefix(val, "https://") || strings.HasPrefix(val, "/") {
+			links = append(links, val)
+		}
+	}
+	return links, []string{}
+}
+
+func trimAll(values []string) []string {
+	ret := make([]string, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, strings.TrimSpace(v))
+	}
+	return ret
+}
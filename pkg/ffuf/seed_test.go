@@ -0,0 +1,78 @@
+package ffuf
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	data := `User-agent: *
+Disallow: /admin/ # staff only
+Disallow: /search*?q=
+Allow: /public/index.html$
+Disallow: /
+Disallow:
+Sitemap: https://example.com/sitemap_news.xml
+`
+	paths, sitemaps := parseRobots(data)
+	if !reflect.DeepEqual(paths, []string{"/admin/", "/search", "/public/index.html"}) {
+		t.Errorf("Unexpected paths from robots.txt: %v", paths)
+	}
+	if !reflect.DeepEqual(sitemaps, []string{"https://example.com/sitemap_news.xml"}) {
+		t.Errorf("Unexpected sitemaps from robots.txt: %v", sitemaps)
+	}
+}
+
+func TestParseSitemap(t *testing.T) {
+	urlset := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/about</loc></url>
+  <url><loc> https://example.com/blog/post-1 </loc></url>
+</urlset>`
+	urls, nested := parseSitemap(urlset)
+	if !reflect.DeepEqual(urls, []string{"https://example.com/about", "https://example.com/blog/post-1"}) || len(nested) != 0 {
+		t.Errorf("Unexpected locations from sitemap: %v %v", urls, nested)
+	}
+
+	index := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-pages.xml</loc></sitemap>
+</sitemapindex>`
+	urls, nested = parseSitemap(index)
+	if len(urls) != 0 || !reflect.DeepEqual(nested, []string{"https://example.com/sitemap-pages.xml"}) {
+		t.Errorf("Unexpected locations from sitemap index: %v %v", urls, nested)
+	}
+}
+
+func TestParseSecurityTxt(t *testing.T) {
+	data := `# comment: https://example.com/ignored
+Contact: mailto:security@example.com
+Contact: https://example.com/security/contact
+Policy: /security/policy.html
+Expires: 2030-01-01T00:00:00.000Z`
+	links, _ := parseSecurityTxt(data)
+	if !reflect.DeepEqual(links, []string{"https://example.com/security/contact", "/security/policy.html"}) {
+		t.Errorf("Unexpected links from security.txt: %v", links)
+	}
+}
+
+func TestSeedRelativePath(t *testing.T) {
+	base, _ := url.Parse("https://example.com/app/")
+	tests := []struct {
+		link     string
+		expected string
+		ok       bool
+	}{
+		{"/app/admin/", "admin/", true},
+		{"https://example.com/app/login.php?next=1", "login.php", true},
+		{"/other/", "", false},
+		{"https://other.example.com/app/x", "", false},
+		{"/app/", "", false},
+	}
+	for _, tc := range tests {
+		rel, ok := seedRelativePath(base, tc.link)
+		if rel != tc.expected || ok != tc.ok {
+			t.Errorf("seedRelativePath(%s): expected %q, %t but got %q, %t", tc.link, tc.expected, tc.ok, rel, ok)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build cgo
+// +build cgo
+
+package ffuf
+
+// SQLiteSupported is true if the sqlite output format is available, the SQLite driver needing cgo
+const SQLiteSupported = true
@@ -101,6 +101,10 @@ func CheckOrCreateConfigDir() error {
 		return err
 	}
 	err = setupDefaultAutocalibrationStrategies()
+	if err != nil {
+		return err
+	}
+	err = setupDefaultScrapers()
 	return err
 }
 
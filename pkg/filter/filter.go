@@ -148,6 +148,20 @@ func (f *MatcherManager) AddMatcher(name string, option string) error {
 	return err
 }
 
+// AddMatcherProvider adds an already initialized matcher, replacing an existing one with the same name
+func (f *MatcherManager) AddMatcherProvider(name string, provider ffuf.FilterProvider) {
+	f.Mutex.Lock()
+	defer f.Mutex.Unlock()
+	f.Matchers[name] = provider
+}
+
+// AddFilterProvider adds an already initialized filter, replacing an existing one with the same name
+func (f *MatcherManager) AddFilterProvider(name string, provider ffuf.FilterProvider) {
+	f.Mutex.Lock()
+	defer f.Mutex.Unlock()
+	f.Filters[name] = provider
+}
+
 func (f *MatcherManager) GetFilters() map[string]ffuf.FilterProvider {
 	return f.Filters
 }
@@ -16,6 +16,13 @@ type MainInputProvider struct {
 	msbIterator int
 }
 
+// feedableInputProvider is implemented by the InternalInputProviders that can receive new values mid-scan
+type feedableInputProvider interface {
+	Feed(values [][]byte, live bool) int
+	FlushPending()
+	Prepend(values [][]byte) [][]byte
+}
+
 func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, ffuf.Multierror) {
 	validmode := false
 	errs := ffuf.NewMultierror()
@@ -129,9 +136,52 @@ func (i *MainInputProvider) Value() map[string][]byte {
 	return retval
 }
 
+// Feed adds new unique values for a keyword. The values are picked up by the running job when it's safe to
+// extend the input space mid-iteration, otherwise they are included starting from the next job.
+func (i *MainInputProvider) Feed(keyword string, values [][]byte) (int, error) {
+	activeCount := 0
+	for _, p := range i.Providers {
+		if p.Active() {
+			activeCount++
+		}
+	}
+	for _, p := range i.Providers {
+		if p.Keyword() != keyword {
+			continue
+		}
+		fp, ok := p.(feedableInputProvider)
+		if !ok {
+			return 0, fmt.Errorf("input provider for keyword %s does not support feeding", keyword)
+		}
+		// Growing one of several clusterbomb inputs would skip the combinations that were already iterated
+		live := p.Active() && (i.Config.InputMode == "pitchfork" || activeCount == 1)
+		return fp.Feed(values, live), nil
+	}
+	return 0, fmt.Errorf("no input provider for keyword %s", keyword)
+}
+
+// Prepend adds values to the beginning of the input of the provider for keyword, before the iteration starts.
+// Returns the values added, the ones already in the input being skipped.
+func (i *MainInputProvider) Prepend(keyword string, values [][]byte) ([][]byte, error) {
+	for _, p := range i.Providers {
+		if p.Keyword() != keyword {
+			continue
+		}
+		fp, ok := p.(feedableInputProvider)
+		if !ok {
+			return nil, fmt.Errorf("input provider for keyword %s does not support seeding", keyword)
+		}
+		return fp.Prepend(values), nil
+	}
+	return nil, fmt.Errorf("no input provider for keyword %s", keyword)
+}
+
 // Reset resets all the inputproviders and counters
 func (i *MainInputProvider) Reset() {
 	for _, p := range i.Providers {
+		if fp, ok := p.(feedableInputProvider); ok {
+			fp.FlushPending()
+		}
 		p.ResetPosition()
 	}
 	i.position = 0
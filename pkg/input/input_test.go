@@ -0,0 +1,43 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestMainInputProviderFeed(t *testing.T) {
+	conf := &ffuf.Config{InputMode: "clusterbomb"}
+	fuzz := &WordlistInput{active: true, keyword: "FUZZ", data: [][]byte{[]byte("a")}}
+	param := &WordlistInput{active: true, keyword: "PARAM", data: [][]byte{[]byte("x")}}
+	cmd, _ := NewCommandInput("CMD", "seq 1 10", conf)
+	ip := MainInputProvider{Config: conf, Providers: []ffuf.InternalInputProvider{fuzz, param, cmd}}
+	cmd.Disable()
+
+	// Two active clusterbomb inputs: new values are deferred to the next job
+	_, err := ip.Feed("FUZZ", [][]byte{[]byte("b")})
+	if err != nil {
+		t.Errorf("Unexpected error while feeding: %s", err)
+	}
+	if ip.Total() != 1 {
+		t.Errorf("Expected fed value to be deferred, total was %d", ip.Total())
+	}
+	ip.Reset()
+	if ip.Total() != 2 {
+		t.Errorf("Expected deferred value to be included after reset, total was %d", ip.Total())
+	}
+
+	// Single active input: new values are picked up by the running job
+	param.Disable()
+	_, _ = ip.Feed("FUZZ", [][]byte{[]byte("c")})
+	if ip.Total() != 3 {
+		t.Errorf("Expected fed value to be live, total was %d", ip.Total())
+	}
+
+	if _, err := ip.Feed("CMD", [][]byte{[]byte("1")}); err == nil {
+		t.Errorf("Expected an error when feeding a command input")
+	}
+	if _, err := ip.Feed("MISSING", [][]byte{[]byte("1")}); err == nil {
+		t.Errorf("Expected an error when feeding a nonexistent keyword")
+	}
+}
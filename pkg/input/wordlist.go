@@ -5,6 +5,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/Mascol9/fuffa/pkg/ffuf"
 )
@@ -15,6 +16,10 @@ type WordlistInput struct {
 	data     [][]byte
 	position int
 	keyword  string
+	// fed values are deduplicated against everything already in the wordlist
+	seen    map[string]bool
+	pending [][]byte
+	mutex   sync.RWMutex
 }
 
 func NewWordlistInput(keyword string, value string, conf *ffuf.Config) (*WordlistInput, error) {
@@ -64,6 +69,8 @@ func (w *WordlistInput) Keyword() string {
 
 // Next will return a boolean telling if there's words left in the list
 func (w *WordlistInput) Next() bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
 	return w.position < len(w.data)
 }
 
@@ -74,14 +81,77 @@ func (w *WordlistInput) IncrementPosition() {
 
 // Value returns the value from wordlist at current cursor position
 func (w *WordlistInput) Value() []byte {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
 	return w.data[w.position]
 }
 
 // Total returns the size of wordlist
 func (w *WordlistInput) Total() int {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
 	return len(w.data)
 }
 
+// Feed adds new unique values to the wordlist. When live is true the values are appended right away and
+// picked up by the running job, otherwise they are held back until FlushPending is called.
+// Returns the number of values that were not seen before.
+func (w *WordlistInput) Feed(values [][]byte, live bool) int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.initSeen()
+	added := 0
+	for _, v := range values {
+		if w.seen[string(v)] {
+			continue
+		}
+		w.seen[string(v)] = true
+		if live {
+			w.data = append(w.data, v)
+		} else {
+			w.pending = append(w.pending, v)
+		}
+		added++
+	}
+	return added
+}
+
+// Prepend adds the values not yet present in the wordlist to the beginning of it, to be called before
+// the iteration starts. Returns the values added.
+func (w *WordlistInput) Prepend(values [][]byte) [][]byte {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.initSeen()
+	newData := make([][]byte, 0, len(values)+len(w.data))
+	for _, v := range values {
+		if w.seen[string(v)] {
+			continue
+		}
+		w.seen[string(v)] = true
+		newData = append(newData, v)
+	}
+	w.data = append(append([][]byte{}, newData...), w.data...)
+	return newData
+}
+
+// initSeen populates the lookup map of existing values, the caller needs to hold the write lock
+func (w *WordlistInput) initSeen() {
+	if w.seen == nil {
+		w.seen = make(map[string]bool, len(w.data))
+		for _, d := range w.data {
+			w.seen[string(d)] = true
+		}
+	}
+}
+
+// FlushPending moves the values held back by Feed to the wordlist
+func (w *WordlistInput) FlushPending() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.data = append(w.data, w.pending...)
+	w.pending = nil
+}
+
 // Active returns boolean if the inputprovider is active
 func (w *WordlistInput) Active() bool {
 	return w.active
@@ -19,3 +19,39 @@ func TestStripCommentsStripsCommentAfterText(t *testing.T) {
 		t.Errorf("Comment was not stripped or pre-comment text was not returned")
 	}
 }
+
+func TestWordlistFeed(t *testing.T) {
+	wl := WordlistInput{data: [][]byte{[]byte("admin"), []byte("login")}}
+
+	added := wl.Feed([][]byte{[]byte("admin"), []byte("api"), []byte("api")}, true)
+	if added != 1 {
+		t.Errorf("Expected 1 new value to be fed, got %d", added)
+	}
+	if wl.Total() != 3 {
+		t.Errorf("Expected live fed value to be in the wordlist, total was %d", wl.Total())
+	}
+
+	added = wl.Feed([][]byte{[]byte("debug")}, false)
+	if added != 1 || wl.Total() != 3 {
+		t.Errorf("Expected pending value to be held back, added %d, total %d", added, wl.Total())
+	}
+	wl.FlushPending()
+	if wl.Total() != 4 {
+		t.Errorf("Expected pending value to be flushed to the wordlist, total was %d", wl.Total())
+	}
+}
+
+func TestWordlistPrepend(t *testing.T) {
+	wl := WordlistInput{data: [][]byte{[]byte("admin"), []byte("login")}}
+
+	added := wl.Prepend([][]byte{[]byte("private/"), []byte("admin"), []byte("sitemap.xml")})
+	if len(added) != 2 || string(added[0]) != "private/" || string(added[1]) != "sitemap.xml" {
+		t.Errorf("Expected the 2 new values to be returned, got %q", added)
+	}
+	expected := []string{"private/", "sitemap.xml", "admin", "login"}
+	for i, e := range expected {
+		if string(wl.data[i]) != e {
+			t.Errorf("Expected value %s at position %d, got %s", e, i, wl.data[i])
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package oob
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxCallbackBody is the size of the HTTP callback body that is kept
+const maxCallbackBody = 64 * 1024
+
+// interactionBuffer is the number of the interactions waiting to be reported, more are dropped
+const interactionBuffer = 1024
+
+// Listener catches the out-of-band HTTP and DNS callbacks of blind payloads, and links them to the requests by the
+// FUFFAHASH found in their host name, path, headers or body
+type Listener struct {
+	// host is the host[:port] of the HTTP callbacks, domain the domain of the DNS callbacks
+	host         string
+	domain       string
+	answer       net.IP
+	httpListener net.Listener
+	httpServer   *http.Server
+	dnsConn      net.PacketConn
+	interactions chan ffuf.OOBInteraction
+	wg           sync.WaitGroup
+	closeOnce    sync.Once
+}
+
+// NewListener starts the HTTP and DNS listeners of the config
+func NewListener(conf *ffuf.Config) (*Listener, error) {
+	l := &Listener{domain: conf.OOBDomain, interactions: make(chan ffuf.OOBInteraction, interactionBuffer)}
+	if conf.OOBHTTP != "" {
+		listener, err := net.Listen("tcp", conf.OOBHTTP)
+		if err != nil {
+			return nil, fmt.Errorf("could not start the out-of-band HTTP listener: %s", err)
+		}
+		l.httpListener = listener
+		l.httpServer = &http.Server{Handler: http.HandlerFunc(l.serveHTTP), ReadHeaderTimeout: 10 * time.Second}
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			if err := l.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				log.Printf("Out-of-band HTTP listener stopped: %s", err)
+			}
+		}()
+	}
+	if conf.OOBDNS != "" {
+		conn, err := net.ListenPacket("udp", conf.OOBDNS)
+		if err != nil {
+			if l.httpServer != nil {
+				l.httpServer.Close()
+			}
+			return nil, fmt.Errorf("could not start the out-of-band DNS listener: %s", err)
+		}
+		l.dnsConn = conn
+		l.wg.Add(1)
+		go l.serveDNS()
+	}
+	l.host = conf.OOBHost
+	if l.host == "" && l.httpListener != nil {
+		l.host = reachableAddr(l.httpListener.Addr())
+	}
+	l.answer = l.answerIP()
+	return l, nil
+}
+
+// reachableAddr returns the address of a listener, with the loopback address instead of the unspecified one
+func reachableAddr(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok || !tcpAddr.IP.IsUnspecified() {
+		return addr.String()
+	}
+	return net.JoinHostPort("127.0.0.1", fmt.Sprintf("%d", tcpAddr.Port))
+}
+
+// answerIP returns the address the DNS listener resolves the callback names to, ie. the one of the HTTP listener
+func (l *Listener) answerIP() net.IP {
+	host := l.host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host).To4(); ip != nil {
+		return ip
+	}
+	if l.dnsConn != nil {
+		if udpAddr, ok := l.dnsConn.LocalAddr().(*net.UDPAddr); ok && !udpAddr.IP.IsUnspecified() && udpAddr.IP.To4() != nil {
+			return udpAddr.IP.To4()
+		}
+	}
+	return net.IPv4(127, 0, 0, 1).To4()
+}
+
+// HTTPAddr returns the address of the HTTP listener, or an empty string if it is not started
+func (l *Listener) HTTPAddr() string {
+	if l.httpListener == nil {
+		return ""
+	}
+	return l.httpListener.Addr().String()
+}
+
+// DNSAddr returns the address of the DNS listener, or an empty string if it is not started
+func (l *Listener) DNSAddr() string {
+	if l.dnsConn == nil {
+		return ""
+	}
+	return l.dnsConn.LocalAddr().String()
+}
+
+// Payloads returns the callback host and URL of a request. The host is a subdomain of the callback domain if
+// there is one, and the host of the HTTP listener with the FUFFAHASH as the path otherwise.
+func (l *Listener) Payloads(fuffahash string) map[string][]byte {
+	var host, url string
+	if l.domain != "" {
+		host = fuffahash + "." + l.domain
+		url = "http://" + host
+		if _, port, err := net.SplitHostPort(l.host); err == nil && port != "80" {
+			url += ":" + port
+		}
+		url += "/"
+	} else {
+		host = l.host + "/" + fuffahash
+		url = "http://" + host
+	}
+	return map[string][]byte{
+		ffuf.OOB_KEYWORD:     []byte(host),
+		ffuf.OOB_URL_KEYWORD: []byte(url),
+	}
+}
+
+// Interactions returns the channel of the caught interactions, closed by Close
+func (l *Listener) Interactions() <-chan ffuf.OOBInteraction {
+	return l.interactions
+}
+
+// Close stops the listeners and closes the interactions channel
+func (l *Listener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		if l.httpServer != nil {
+			err = l.httpServer.Close()
+		}
+		if l.dnsConn != nil {
+			if dnsErr := l.dnsConn.Close(); err == nil {
+				err = dnsErr
+			}
+		}
+		l.wg.Wait()
+		close(l.interactions)
+	})
+	return err
+}
+
+// report passes an interaction to the job, dropping it if the job does not keep up
+func (l *Listener) report(protocol, remoteAddr, raw string, hashes []string) {
+	interaction := ffuf.OOBInteraction{Protocol: protocol, RemoteAddr: remoteAddr, Raw: raw, Time: time.Now()}
+	if len(hashes) > 0 {
+		interaction.FuffaHash = hashes[0]
+	}
+	select {
+	case l.interactions <- interaction:
+	default:
+		log.Printf("Dropped an out-of-band %s interaction from %s", protocol, remoteAddr)
+	}
+}
+
+func (l *Listener) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCallbackBody)
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+	raw, err := httputil.DumpRequest(r, true)
+	if err != nil {
+		raw = []byte(r.Method + " " + r.RequestURI + " " + r.Proto)
+	}
+	l.report("http", r.RemoteAddr, string(raw), ffuf.FindFuffaHashes(string(raw)))
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+func (l *Listener) serveDNS() {
+	defer l.wg.Done()
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := l.dnsConn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		reply, question, ok := l.answerDNS(buf[:n])
+		if !ok {
+			continue
+		}
+		l.dnsConn.WriteTo(reply, addr)
+		name := strings.ToLower(question.Name.String())
+		raw := fmt.Sprintf("%s %s", strings.TrimPrefix(question.Type.String(), "Type"), name)
+		l.report("dns", addr.String(), raw, ffuf.FindFuffaHashes(name))
+	}
+}
+
+// answerDNS returns the reply to a DNS query, resolving the A queries of the callback domain to the answer address
+func (l *Listener) answerDNS(query []byte) ([]byte, dnsmessage.Question, bool) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil || header.Response {
+		return nil, dnsmessage.Question{}, false
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, dnsmessage.Question{}, false
+	}
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+	inDomain := name == l.domain || strings.HasSuffix(name, "."+l.domain)
+	reply := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:            header.ID,
+			Response:      true,
+			Authoritative: inDomain,
+			RCode:         dnsmessage.RCodeSuccess,
+		},
+		Questions: []dnsmessage.Question{question},
+	}
+	if !inDomain {
+		reply.Header.RCode = dnsmessage.RCodeRefused
+	} else if question.Type == dnsmessage.TypeA || question.Type == dnsmessage.TypeALL {
+		var a [4]byte
+		copy(a[:], l.answer)
+		reply.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 0},
+			Body:   &dnsmessage.AResource{A: a},
+		}}
+	}
+	packed, err := reply.Pack()
+	if err != nil {
+		return nil, question, false
+	}
+	return packed, question, true
+}
@@ -0,0 +1,103 @@
+package oob
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newTestListener(t *testing.T, domain string) *Listener {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.OOBHTTP = "127.0.0.1:0"
+	conf.OOBDNS = "127.0.0.1:0"
+	conf.OOBDomain = domain
+	l, err := NewListener(&conf)
+	if err != nil {
+		t.Fatalf("Could not start the listener: %s", err)
+	}
+	return l
+}
+
+func nextInteraction(t *testing.T, l *Listener) ffuf.OOBInteraction {
+	select {
+	case interaction := <-l.Interactions():
+		return interaction
+	case <-time.After(5 * time.Second):
+		t.Fatalf("No interaction caught")
+	}
+	return ffuf.OOBInteraction{}
+}
+
+func TestListenerHTTP(t *testing.T) {
+	l := newTestListener(t, "")
+	defer l.Close()
+	hash := ffuf.NewFuffaHash("0123456789abcdef", 1, 42).String()
+	payloads := l.Payloads(hash)
+	if string(payloads[ffuf.OOB_KEYWORD]) != l.HTTPAddr()+"/"+hash {
+		t.Errorf("Unexpected callback host %s", payloads[ffuf.OOB_KEYWORD])
+	}
+	resp, err := http.Get(string(payloads[ffuf.OOB_URL_KEYWORD]) + "?more=1")
+	if err != nil {
+		t.Fatalf("Callback request failed: %s", err)
+	}
+	resp.Body.Close()
+	interaction := nextInteraction(t, l)
+	if interaction.Protocol != "http" || interaction.FuffaHash != hash {
+		t.Errorf("Expected an http interaction with the FUFFAHASH %s, got %+v", hash, interaction)
+	}
+}
+
+func TestListenerDNS(t *testing.T) {
+	l := newTestListener(t, "oob.test")
+	defer l.Close()
+	hash := ffuf.NewFuffaHash("0123456789abcdef", 2, 7).String()
+	host := string(l.Payloads(hash)[ffuf.OOB_KEYWORD])
+	if host != hash+".oob.test" {
+		t.Errorf("Unexpected callback host %s", host)
+	}
+
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 4242, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName("x." + host + "."), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}},
+	}
+	packed, _ := query.Pack()
+	conn, err := net.Dial("udp", l.DNSAddr())
+	if err != nil {
+		t.Fatalf("Could not connect to the DNS listener: %s", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write(packed)
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("No DNS reply: %s", err)
+	}
+	var reply dnsmessage.Message
+	if err := reply.Unpack(buf[:n]); err != nil || reply.ID != 4242 || len(reply.Answers) != 1 {
+		t.Fatalf("Expected a single answer to the query, got %+v (%v)", reply, err)
+	}
+	if a, ok := reply.Answers[0].Body.(*dnsmessage.AResource); !ok || net.IP(a.A[:]).String() != "127.0.0.1" {
+		t.Errorf("Expected the callback name to resolve to the HTTP listener, got %v", reply.Answers[0].Body)
+	}
+	interaction := nextInteraction(t, l)
+	if interaction.Protocol != "dns" || interaction.FuffaHash != hash {
+		t.Errorf("Expected a dns interaction with the FUFFAHASH %s, got %+v", hash, interaction)
+	}
+}
+
+func TestListenerClose(t *testing.T) {
+	l := newTestListener(t, "oob.test")
+	if err := l.Close(); err != nil {
+		t.Errorf("Error closing the listener: %s", err)
+	}
+	if _, ok := <-l.Interactions(); ok {
+		t.Errorf("Expected the interactions channel to be closed")
+	}
+	l.Close()
+}
@@ -1,24 +1,39 @@
 package output
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
 )
 
 type AuditLogger struct {
-	file *os.File
-	lock sync.Mutex
+	file      *rotatingFile
+	lock      sync.Mutex
+	redaction *ffuf.RedactionPolicy
 }
 
 func NewAuditLogger(filename string) (*AuditLogger, error) {
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	return NewAuditLoggerWithOptions(filename, AuditLogOptions{})
+}
+
+// NewAuditLoggerWithOptions returns an audit logger rotating, compressing and redacting the log as configured
+func NewAuditLoggerWithOptions(filename string, options AuditLogOptions) (*AuditLogger, error) {
+	f, err := newRotatingFile(filename, options, nil)
 	if err != nil {
 		return nil, err
 	}
-	auditLogger := &AuditLogger{file: f}
+	auditLogger := &AuditLogger{file: f, redaction: options.Redaction}
 
 	return auditLogger, nil
 }
@@ -36,7 +51,7 @@ func (logger *AuditLogger) Write(data interface{}) error {
 		Data interface{}
 	}{
 		reflect.TypeOf(data).String(),
-		data,
+		logger.redaction.Redact(data),
 	}
 
 	j, err := json.Marshal(d)
@@ -44,15 +59,161 @@ func (logger *AuditLogger) Write(data interface{}) error {
 		return fmt.Errorf("could not marshal json data: %s", err)
 	}
 
-	_, err = logger.file.Write(j)
+	// the record is written at once, so that it is not split between rotated segments
+	_, err = logger.file.Write(append(j, '\n'))
 	if err != nil {
 		return fmt.Errorf("could not write json data to audit log: %s", err)
 	}
 
-	_, err = logger.file.Write([]byte("\n"))
+	return nil
+}
+
+// AuditLog holds the records of a JSON audit log
+type AuditLog struct {
+	// Config is the config of the audited scan, without its matchers and filters
+	Config    *ffuf.Config
+	Requests  []ffuf.Request
+	Responses []ffuf.Response
+}
+
+// auditRecord is a record of the JSON audit log, decoded according to its type
+type auditRecord struct {
+	Type string
+	Data json.RawMessage
+}
+
+// ReadAuditLog reads the JSON audit logs, including the rotated segments compressed with gzip. The segments may be
+// passed in any order, the config of the scan is taken from the first one that has it.
+func ReadAuditLog(filenames ...string) (*AuditLog, error) {
+	log := &AuditLog{}
+	for _, filename := range filenames {
+		if err := log.read(filename); err != nil {
+			return nil, fmt.Errorf("could not read the audit log %s: %s", filename, err)
+		}
+	}
+	if log.Config == nil {
+		return nil, fmt.Errorf("the audit log has no config record")
+	}
+	return log, nil
+}
+
+func (log *AuditLog) read(filename string) error {
+	f, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("could not write newline to underlying io.Writer: %w", err)
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(filename, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	reader := bufio.NewReader(r)
+	for lineno := 1; ; lineno++ {
+		// the records can be longer than the limit of bufio.Scanner, as they include the response bodies
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if lineno == 1 && bytes.HasPrefix(line, []byte("WARC/")) {
+				return fmt.Errorf("WARC audit logs are not supported, use -audit-format json")
+			}
+			if recErr := log.add(line); recErr != nil {
+				return fmt.Errorf("line %d: %s", lineno, recErr)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 	}
+}
 
+func (log *AuditLog) add(line []byte) error {
+	var record auditRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return err
+	}
+	switch record.Type {
+	case "*ffuf.Config":
+		if log.Config != nil {
+			return nil
+		}
+		// the matchers are an interface that cannot be decoded, they are set up again from the command line
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(record.Data, &fields); err != nil {
+			return err
+		}
+		delete(fields, "matchers")
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		conf := &ffuf.Config{}
+		if err := json.Unmarshal(data, conf); err != nil {
+			return err
+		}
+		log.Config = conf
+	case "*ffuf.Request":
+		var req ffuf.Request
+		if err := json.Unmarshal(record.Data, &req); err != nil {
+			return err
+		}
+		log.Requests = append(log.Requests, req)
+	case "*ffuf.Response":
+		var resp ffuf.Response
+		if err := json.Unmarshal(record.Data, &resp); err != nil {
+			return err
+		}
+		log.Responses = append(log.Responses, resp)
+	}
 	return nil
 }
+
+// Inputs returns the inputs of the audited requests for each keyword, in the order of their positions. The inputs
+// are deduplicated, except in the pitchfork mode where the inputs of the keywords are paired by their positions.
+func (log *AuditLog) Inputs() map[string][][]byte {
+	reqs := make([]ffuf.Request, 0, len(log.Requests)+len(log.Responses))
+	reqs = append(reqs, log.Requests...)
+	for _, resp := range log.Responses {
+		if resp.Request != nil {
+			reqs = append(reqs, *resp.Request)
+		}
+	}
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[i].Position < reqs[j].Position })
+
+	keywords := make(map[string]bool)
+	for _, provider := range log.Config.InputProviders {
+		keywords[provider.Keyword] = true
+	}
+	pitchfork := log.Config.InputMode == "pitchfork"
+	inputs := make(map[string][][]byte)
+	seen := make(map[string]bool)
+	for _, req := range reqs {
+		if pitchfork {
+			key := strconv.Itoa(req.Position)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		for keyword, value := range req.Input {
+			if !keywords[keyword] {
+				continue
+			}
+			key := keyword + "\x00" + string(value)
+			if !pitchfork {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			inputs[keyword] = append(inputs[keyword], value)
+		}
+	}
+	return inputs
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Mascol9/fuffa/pkg/ffuf"
@@ -117,3 +118,57 @@ func TestAuditLogWrite(t *testing.T) {
 		t.Errorf("Error was nil, expected non-nil")
 	}
 }
+
+func TestReadAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "audit.jsonl")
+	audit, err := NewAuditLoggerWithOptions(filename, AuditLogOptions{MaxSize: 1, Compress: true})
+	if err != nil {
+		t.Fatalf("Error creating audit logger: %s", err)
+	}
+	conf := &ffuf.Config{Url: "http://example.com/FUZZ", Method: "GET", InputMode: "clusterbomb",
+		InputProviders: []ffuf.InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}}
+	audit.Write(conf)
+	for i, word := range []string{"b", "a", "b"} {
+		req := &ffuf.Request{Method: "GET", Url: "http://example.com/" + word, Position: i + 1,
+			Input: map[string][]byte{"FUZZ": []byte(word), "FUFFAHASH": []byte("hash")}}
+		audit.Write(&ffuf.Response{StatusCode: 200, Data: []byte("body " + word), Request: req})
+	}
+	audit.Close()
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "audit.jsonl*"))
+	if len(segments) < 2 {
+		t.Fatalf("Expected rotated segments, got %v", segments)
+	}
+	log, err := ReadAuditLog(segments...)
+	if err != nil {
+		t.Fatalf("Error reading the audit log: %s", err)
+	}
+	if log.Config.Url != conf.Url {
+		t.Errorf("Expected the config url %s, got %s", conf.Url, log.Config.Url)
+	}
+	if len(log.Responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(log.Responses))
+	}
+	inputs := log.Inputs()
+	if len(inputs) != 1 || len(inputs["FUZZ"]) != 2 || string(inputs["FUZZ"][0]) != "b" || string(inputs["FUZZ"][1]) != "a" {
+		t.Errorf("Expected the deduplicated inputs [b a] in position order, got %q", inputs)
+	}
+
+	log.Config.InputMode = "pitchfork"
+	if inputs = log.Inputs(); len(inputs["FUZZ"]) != 3 {
+		t.Errorf("Expected an input per position in the pitchfork mode, got %q", inputs)
+	}
+}
+
+func TestReadAuditLogWARC(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.warc")
+	audit, err := NewWARCLoggerWithOptions(filename, AuditLogOptions{})
+	if err != nil {
+		t.Fatalf("Error creating WARC logger: %s", err)
+	}
+	audit.Close()
+	if _, err := ReadAuditLog(filename); err == nil {
+		t.Errorf("Expected an error reading a WARC audit log")
+	}
+}
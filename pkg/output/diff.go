@@ -0,0 +1,318 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+const (
+	DIFF_NEW     = "new"
+	DIFF_GONE    = "gone"
+	DIFF_CHANGED = "changed"
+
+	diffHTMLTemplate = `{{ define "diff" }}
+<h4>Diff against baseline {{ .Baseline }}</h4>
+<p>{{ .New }} new, {{ .Gone }} gone, {{ .Changed }} changed, {{ .Unchanged }} unchanged</p>
+<table id="ffufdiff">
+  <thead><tr><th>Diff</th><th>URL</th><th>Input</th><th>Status</th><th>Length</th><th>Words</th><th>Lines</th><th>Changes</th></tr></thead>
+  <tbody>
+  {{ range .Entries }}<tr class="diff-{{ .Kind }}">
+    <td>{{ .Kind }}</td><td><a href="{{ .Url }}">{{ .Url }}</a></td><td>{{ .InputString }}</td>
+    <td>{{ .Result.StatusCode }}</td><td>{{ .Result.ContentLength }}</td><td>{{ .Result.ContentWords }}</td><td>{{ .Result.ContentLines }}</td>
+    <td>{{ range .Changes }}{{ .Field }}: {{ .Old }} &rarr; {{ .New }}<br />{{ end }}</td>
+  </tr>
+  {{ end }}</tbody>
+</table>
+{{ end }}`
+
+	diffHTMLDocument = `<!DOCTYPE html>
+<html>
+<head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8" /><title>FUFFA Diff Report</title></head>
+<body>
+<p>Time: {{ .Time }}</p>
+{{ template "diff" .Diff }}
+</body>
+</html>
+`
+
+	diffMarkdownTemplate = `{{ define "diff" }}
+## Diff against baseline ` + "`{{ .Baseline }}`" + `
+
+  {{ .New }} new, {{ .Gone }} gone, {{ .Changed }} changed, {{ .Unchanged }} unchanged
+
+  | Diff | URL | Input | Status | Length | Words | Lines | Changes
+  | :--- | :-- | :---- | :----- | :----- | :---- | :---- | :------ |
+  {{ range .Entries }}| {{ .Kind }} | {{ .Url }} | {{ .InputString }} | {{ .Result.StatusCode }} | {{ .Result.ContentLength }} | {{ .Result.ContentWords }} | {{ .Result.ContentLines }} | {{ range .Changes }}{{ .Field }}: {{ .Old }} -> {{ .New }} {{ end }}
+  {{ end }}{{ end }}`
+
+	diffMarkdownDocument = `# FUFFA Diff Report
+
+  Time: {{ .Time }}
+{{ template "diff" .Diff }}`
+)
+
+// DiffChange is a result field that has a different value in the baseline
+type DiffChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffEntry is a result that is new, gone or changed compared to the baseline. Result is the baseline result
+// for gone entries, and the result of the newer scan otherwise.
+type DiffEntry struct {
+	Kind        string            `json:"kind"`
+	Url         string            `json:"url"`
+	Input       map[string]string `json:"input"`
+	Changes     []DiffChange      `json:"changes,omitempty"`
+	Result      JsonResult        `json:"result"`
+	InputString string            `json:"-"`
+}
+
+// DiffReport is the difference between the results of two scans, matched by URL and input values
+type DiffReport struct {
+	Baseline  string      `json:"baseline"`
+	New       int         `json:"new"`
+	Gone      int         `json:"gone"`
+	Changed   int         `json:"changed"`
+	Unchanged int         `json:"unchanged"`
+	Entries   []DiffEntry `json:"entries"`
+}
+
+type diffFileOutput struct {
+	Time string      `json:"time"`
+	Diff *DiffReport `json:"diff"`
+}
+
+// LoadResultsFile reads the results from a JSON, ejson or JSONL output file
+func LoadResultsFile(filename string) ([]ffuf.Result, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var rawResults []json.RawMessage
+	var doc map[string]json.RawMessage
+	err = json.Unmarshal(data, &doc)
+	if results, ok := doc["results"]; err == nil && ok {
+		if err = json.Unmarshal(results, &rawResults); err != nil {
+			return nil, fmt.Errorf("could not parse results from %s: %s", filename, err)
+		}
+	} else {
+		// not a json or ejson document, try reading it as JSONL
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				rawResults = append(rawResults, json.RawMessage(append([]byte{}, line...)))
+			}
+		}
+		if len(rawResults) == 0 {
+			return nil, fmt.Errorf("could not find results in %s", filename)
+		}
+	}
+	results := make([]ffuf.Result, 0, len(rawResults))
+	for _, raw := range rawResults {
+		r, err := parseResult(raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse results from %s: %s", filename, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// parseResult parses a result of the ejson format, which has byte slice inputs, or of the json format
+func parseResult(raw json.RawMessage) (ffuf.Result, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ffuf.Result{}, err
+	}
+	if _, ok := fields["is_vhost_mode"]; ok {
+		var r ffuf.Result
+		err := json.Unmarshal(raw, &r)
+		return r, err
+	}
+	var jr JsonResult
+	if err := json.Unmarshal(raw, &jr); err != nil {
+		return ffuf.Result{}, err
+	}
+	input := make(map[string][]byte, len(jr.Input))
+	for k, v := range jr.Input {
+		input[k] = []byte(v)
+	}
+	return ffuf.Result{
+		Input:            input,
+		Position:         jr.Position,
+		StatusCode:       jr.StatusCode,
+		ContentLength:    jr.ContentLength,
+		ContentWords:     jr.ContentWords,
+		ContentLines:     jr.ContentLines,
+		ContentType:      jr.ContentType,
+		RedirectLocation: jr.RedirectLocation,
+		ScraperData:      jr.ScraperData,
+		Seed:             jr.Seed,
+		Duration:         jr.Duration,
+		ResultFile:       jr.ResultFile,
+		Url:              jr.Url,
+		Host:             jr.Host,
+	}, nil
+}
+
+// diffInputs returns the input values of the result without FUFFAHASH, which changes between scans
+func diffInputs(r ffuf.Result) map[string]string {
+	inputs := make(map[string]string, len(r.Input))
+	for k, v := range r.Input {
+		if k != "FUFFAHASH" {
+			inputs[k] = string(v)
+		}
+	}
+	return inputs
+}
+
+func diffInputString(inputs map[string]string) string {
+	keys := make([]string, 0, len(inputs))
+	for k := range inputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+inputs[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func diffKey(r ffuf.Result) string {
+	return r.Url + "\x00" + diffInputString(diffInputs(r))
+}
+
+func diffChanges(old, new ffuf.Result) []DiffChange {
+	changes := make([]DiffChange, 0)
+	add := func(field string, o, n interface{}) {
+		if o != n {
+			changes = append(changes, DiffChange{Field: field, Old: fmt.Sprint(o), New: fmt.Sprint(n)})
+		}
+	}
+	add("status", old.StatusCode, new.StatusCode)
+	add("size", old.ContentLength, new.ContentLength)
+	add("words", old.ContentWords, new.ContentWords)
+	add("lines", old.ContentLines, new.ContentLines)
+	add("content-type", old.ContentType, new.ContentType)
+	add("redirect", old.RedirectLocation, new.RedirectLocation)
+	return changes
+}
+
+func newDiffEntry(kind string, r ffuf.Result, changes []DiffChange) DiffEntry {
+	inputs := diffInputs(r)
+	return DiffEntry{
+		Kind:        kind,
+		Url:         r.Url,
+		Input:       inputs,
+		Changes:     changes,
+		Result:      toJsonResult(r),
+		InputString: diffInputString(inputs),
+	}
+}
+
+// DiffResults classifies the results as new, gone or changed compared to the baseline results
+func DiffResults(baseline string, old, new []ffuf.Result) *DiffReport {
+	report := &DiffReport{Baseline: baseline, Entries: make([]DiffEntry, 0)}
+	oldResults := make(map[string]ffuf.Result, len(old))
+	for _, r := range old {
+		oldResults[diffKey(r)] = r
+	}
+	seen := make(map[string]bool, len(new))
+	changed := make([]DiffEntry, 0)
+	for _, r := range new {
+		key := diffKey(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		o, ok := oldResults[key]
+		if !ok {
+			report.Entries = append(report.Entries, newDiffEntry(DIFF_NEW, r, nil))
+			report.New++
+			continue
+		}
+		if changes := diffChanges(o, r); len(changes) > 0 {
+			changed = append(changed, newDiffEntry(DIFF_CHANGED, r, changes))
+			report.Changed++
+			continue
+		}
+		report.Unchanged++
+	}
+	report.Entries = append(report.Entries, changed...)
+	for _, r := range old {
+		key := diffKey(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		report.Entries = append(report.Entries, newDiffEntry(DIFF_GONE, r, nil))
+		report.Gone++
+	}
+	return report
+}
+
+// String renders the diff for the terminal
+func (d *DiffReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Diff against baseline %s: %d new, %d gone, %d changed, %d unchanged\n", d.Baseline, d.New, d.Gone, d.Changed, d.Unchanged)
+	for _, e := range d.Entries {
+		fmt.Fprintf(&sb, "  %-9s %s", "["+strings.ToUpper(e.Kind)+"]", e.Url)
+		if e.InputString != "" {
+			fmt.Fprintf(&sb, " [%s]", e.InputString)
+		}
+		if e.Kind == DIFF_CHANGED {
+			changes := make([]string, 0, len(e.Changes))
+			for _, c := range e.Changes {
+				changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, c.Old, c.New))
+			}
+			fmt.Fprintf(&sb, " (%s)\n", strings.Join(changes, ", "))
+		} else {
+			fmt.Fprintf(&sb, " (status: %d, size: %d)\n", e.Result.StatusCode, e.Result.ContentLength)
+		}
+	}
+	return sb.String()
+}
+
+// WriteDiff writes a standalone diff document in json, html or md format
+func WriteDiff(filename, format string, report *DiffReport) error {
+	out := diffFileOutput{Time: time.Now().Format(time.RFC3339), Diff: report}
+	if format == "json" {
+		outBytes, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, outBytes, 0644)
+	}
+	var t *template.Template
+	var err error
+	switch format {
+	case "html":
+		t, err = template.New("diff.html").Parse(diffHTMLTemplate + diffHTMLDocument)
+	case "md":
+		t, err = template.New("diff.md").Parse(diffMarkdownTemplate + diffMarkdownDocument)
+	default:
+		return fmt.Errorf("unsupported diff output format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.Execute(f, out)
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func diffTestResult(url, input string, status, length int64) ffuf.Result {
+	return ffuf.Result{
+		Input:         map[string][]byte{"FUZZ": []byte(input), "FUFFAHASH": []byte(url)},
+		Url:           url,
+		StatusCode:    status,
+		ContentLength: length,
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	old := []ffuf.Result{
+		diffTestResult("http://example.com/admin", "admin", 200, 10),
+		diffTestResult("http://example.com/login", "login", 200, 20),
+		diffTestResult("http://example.com/old", "old", 200, 30),
+	}
+	new := []ffuf.Result{
+		diffTestResult("http://example.com/admin", "admin", 403, 10),
+		diffTestResult("http://example.com/login", "login", 200, 20),
+		diffTestResult("http://example.com/new", "new", 200, 40),
+	}
+	// the hash differs between scans and must not affect the matching
+	new[1].Input["FUFFAHASH"] = []byte("changed")
+	report := DiffResults("old.json", old, new)
+	if report.New != 1 || report.Gone != 1 || report.Changed != 1 || report.Unchanged != 1 {
+		t.Fatalf("Unexpected diff counts: %+v", report)
+	}
+	kinds := make([]string, 0)
+	for _, e := range report.Entries {
+		kinds = append(kinds, e.Kind+" "+e.Url)
+	}
+	expected := "new http://example.com/new,changed http://example.com/admin,gone http://example.com/old"
+	if strings.Join(kinds, ",") != expected {
+		t.Errorf("Unexpected diff entries: %v", kinds)
+	}
+	changes := report.Entries[1].Changes
+	if len(changes) != 1 || changes[0] != (DiffChange{Field: "status", Old: "200", New: "403"}) {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+	if !strings.Contains(report.String(), "[CHANGED] http://example.com/admin [FUZZ: admin] (status: 200 -> 403)") {
+		t.Errorf("Unexpected diff rendering:\n%s", report.String())
+	}
+}
+
+func TestLoadResultsFile(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{}
+	res := []ffuf.Result{diffTestResult("http://example.com/admin", "admin", 200, 10)}
+	writers := map[string]func(string) error{
+		"json":  func(f string) error { return writeJSON(f, conf, res, nil) },
+		"ejson": func(f string) error { return writeEJSON(f, conf, res) },
+		"jsonl": func(f string) error { return writeJSONL(f, conf, res) },
+	}
+	for format, write := range writers {
+		filename := filepath.Join(dir, "out."+format)
+		if err := write(filename); err != nil {
+			t.Fatalf("%s: could not write results: %s", format, err)
+		}
+		loaded, err := LoadResultsFile(filename)
+		if err != nil {
+			t.Fatalf("%s: could not load results: %s", format, err)
+		}
+		if len(loaded) != 1 || string(loaded[0].Input["FUZZ"]) != "admin" || loaded[0].StatusCode != 200 {
+			t.Errorf("%s: unexpected results loaded: %+v", format, loaded)
+		}
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	report := DiffResults("old.json", nil, []ffuf.Result{diffTestResult("http://example.com/<new>", "<new>", 200, 10)})
+	for _, format := range []string{"json", "html", "md"} {
+		filename := filepath.Join(t.TempDir(), "diff."+format)
+		if err := WriteDiff(filename, format, report); err != nil {
+			t.Fatalf("%s: could not write diff: %s", format, err)
+		}
+		data, _ := os.ReadFile(filename)
+		if !strings.Contains(string(data), "new") || strings.Contains(string(data), "<new>") {
+			t.Errorf("%s: unexpected diff document: %s", format, data)
+		}
+	}
+	filename := filepath.Join(t.TempDir(), "out.html")
+	if err := writeHTML(filename, &ffuf.Config{}, nil, report); err != nil {
+		t.Fatalf("Could not write HTML report with diff: %s", err)
+	}
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "Diff against baseline old.json") {
+		t.Errorf("Expected the HTML report to contain the diff")
+	}
+}
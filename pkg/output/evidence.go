@@ -0,0 +1,208 @@
+package output
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+const (
+	// EVIDENCE_INDEX is the manifest of the output directory, a JSON line per stored result
+	EVIDENCE_INDEX = "index.jsonl"
+
+	evidenceRequests  = "requests"
+	evidenceResponses = "responses"
+	evidenceBodies    = "bodies"
+)
+
+// EvidenceEntry maps a matched result to its request, response headers and response body files in the output
+// directory. The file paths are relative to the output directory.
+type EvidenceEntry struct {
+	Id            string            `json:"id"`
+	FuffaHash     string            `json:"fuffahash"`
+	Url           string            `json:"url"`
+	Method        string            `json:"method"`
+	Host          string            `json:"host"`
+	Input         map[string]string `json:"input"`
+	Position      int               `json:"position"`
+	StatusCode    int64             `json:"status"`
+	ContentLength int64             `json:"length"`
+	ContentWords  int64             `json:"words"`
+	ContentLines  int64             `json:"lines"`
+	ContentType   string            `json:"content-type"`
+	Time          time.Time         `json:"time"`
+	Request       string            `json:"request"`
+	Response      string            `json:"response"`
+	Body          string            `json:"body"`
+}
+
+// EvidenceStore is a content-addressed store of the matched requests and responses. The requests, response
+// headers and response bodies are stored separately in files named by the SHA-256 of their content, so identical
+// bodies are stored once, and the index.jsonl manifest maps each result to its files.
+type EvidenceStore struct {
+	dir   string
+	lock  sync.Mutex
+	index *os.File
+}
+
+// NewEvidenceStore creates the output directory layout and opens the manifest for appending
+func NewEvidenceStore(dir string) (*EvidenceStore, error) {
+	for _, sub := range []string{evidenceRequests, evidenceResponses, evidenceBodies} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0750); err != nil {
+			return nil, err
+		}
+	}
+	index, err := os.OpenFile(filepath.Join(dir, EVIDENCE_INDEX), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &EvidenceStore{dir: dir, index: index}, nil
+}
+
+func (e *EvidenceStore) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.index.Close()
+}
+
+// evidenceID identifies the result in the store by the hash of its request
+func evidenceID(resp *ffuf.Response) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(resp.Request.Raw)))
+}
+
+// splitRawResponse returns the status line and the headers of the raw response
+func splitRawResponse(raw string) string {
+	if i := strings.Index(raw, "\r\n\r\n"); i != -1 {
+		return raw[:i+4]
+	}
+	return raw
+}
+
+// writeBlob stores the content under its hash, unless it is already stored, and returns its relative path
+func (e *EvidenceStore) writeBlob(kind string, content []byte) (string, error) {
+	name := filepath.Join(kind, fmt.Sprintf("%x", sha256.Sum256(content)))
+	fullpath := filepath.Join(e.dir, name)
+	if _, err := os.Stat(fullpath); err == nil {
+		return name, nil
+	}
+	// write to a temporary file first, so that a file with the name of a hash is always complete
+	tmp, err := os.CreateTemp(filepath.Join(e.dir, kind), ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err = os.Chmod(tmp.Name(), 0640); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return name, os.Rename(tmp.Name(), fullpath)
+}
+
+// Add stores the request, response headers and decoded response body of the result and appends it to the manifest
+func (e *EvidenceStore) Add(resp *ffuf.Response) (EvidenceEntry, error) {
+	inputs := make(map[string]string, len(resp.Request.Input))
+	for k, v := range resp.Request.Input {
+		if k != "FUFFAHASH" {
+			inputs[k] = string(v)
+		}
+	}
+	entry := EvidenceEntry{
+		Id:            evidenceID(resp),
+		FuffaHash:     string(resp.Request.Input["FUFFAHASH"]),
+		Url:           resp.Request.Url,
+		Method:        resp.Request.Method,
+		Host:          resp.Request.Host,
+		Input:         inputs,
+		Position:      resp.Request.Position,
+		StatusCode:    resp.StatusCode,
+		ContentLength: resp.ContentLength,
+		ContentWords:  resp.ContentWords,
+		ContentLines:  resp.ContentLines,
+		ContentType:   resp.ContentType,
+		Time:          resp.Timestamp,
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var err error
+	if entry.Request, err = e.writeBlob(evidenceRequests, []byte(resp.Request.Raw)); err != nil {
+		return entry, err
+	}
+	if entry.Response, err = e.writeBlob(evidenceResponses, []byte(splitRawResponse(resp.Raw))); err != nil {
+		return entry, err
+	}
+	if entry.Body, err = e.writeBlob(evidenceBodies, resp.Data); err != nil {
+		return entry, err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+	_, err = e.index.Write(append(line, '\n'))
+	return entry, err
+}
+
+// LoadEvidenceIndex reads the manifest of the output directory
+func LoadEvidenceIndex(dir string) ([]EvidenceEntry, error) {
+	f, err := os.Open(filepath.Join(dir, EVIDENCE_INDEX))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := make([]EvidenceEntry, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry EvidenceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %s", EVIDENCE_INDEX, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// FindEvidence returns the entries of the output directory with the FUFFAHASH, or with an id starting with the hash
+func FindEvidence(dir, hash string) ([]EvidenceEntry, error) {
+	entries, err := LoadEvidenceIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]EvidenceEntry, 0)
+	for _, entry := range entries {
+		if entry.FuffaHash == hash || (len(hash) >= 6 && strings.HasPrefix(entry.Id, strings.ToLower(hash))) {
+			found = append(found, entry)
+		}
+	}
+	return found, nil
+}
+
+// ReadEvidence reads the request, response headers and response body of the entry
+func ReadEvidence(dir string, entry EvidenceEntry) (request, response, body []byte, err error) {
+	if request, err = os.ReadFile(filepath.Join(dir, entry.Request)); err != nil {
+		return
+	}
+	if response, err = os.ReadFile(filepath.Join(dir, entry.Response)); err != nil {
+		return
+	}
+	body, err = os.ReadFile(filepath.Join(dir, entry.Body))
+	return
+}
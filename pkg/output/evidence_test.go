@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func evidenceTestResponse(url, hash string, body []byte) *ffuf.Response {
+	req := ffuf.NewRequest(&ffuf.Config{Method: "GET"})
+	req.Url = url
+	req.Input = map[string][]byte{"FUZZ": []byte(url), "FUFFAHASH": []byte(hash)}
+	req.Raw = "GET " + url + " HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	return &ffuf.Response{
+		StatusCode: 200,
+		Request:    &req,
+		Raw:        "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\n\r\n" + string(body),
+		Data:       body,
+	}
+}
+
+func TestEvidenceStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewEvidenceStore(dir)
+	if err != nil {
+		t.Fatalf("Could not create the evidence store: %s", err)
+	}
+	body := []byte{0x00, 0xff, 0xfe, '\n', 0x80}
+	first, err := store.Add(evidenceTestResponse("/a", "abcde1", body))
+	if err != nil {
+		t.Fatalf("Could not add the result: %s", err)
+	}
+	second, err := store.Add(evidenceTestResponse("/b", "abcde2", body))
+	if err != nil {
+		t.Fatalf("Could not add the result: %s", err)
+	}
+	store.Close()
+	if first.Body != second.Body || first.Request == second.Request {
+		t.Errorf("Expected identical bodies to share a file and requests to not: %+v %+v", first, second)
+	}
+	bodies, _ := os.ReadDir(filepath.Join(dir, "bodies"))
+	if len(bodies) != 1 {
+		t.Errorf("Expected a single stored body, got %d", len(bodies))
+	}
+	entries, err := LoadEvidenceIndex(dir)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 entries in the manifest, got %d: %v", len(entries), err)
+	}
+	found, err := FindEvidence(dir, "abcde2")
+	if err != nil || len(found) != 1 || found[0].Url != "/b" || found[0].Input["FUZZ"] != "/b" {
+		t.Fatalf("Could not find the entry by FUFFAHASH: %+v %v", found, err)
+	}
+	if found, _ = FindEvidence(dir, first.Id[:10]); len(found) != 1 || found[0].Url != "/a" {
+		t.Errorf("Could not find the entry by id: %+v", found)
+	}
+	request, response, storedBody, err := ReadEvidence(dir, found[0])
+	if err != nil {
+		t.Fatalf("Could not read the evidence: %s", err)
+	}
+	if string(request) != "GET /a HTTP/1.1\r\nHost: example.com\r\n\r\n" || string(response) != "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\n\r\n" {
+		t.Errorf("Unexpected request or response headers: %q %q", request, response)
+	}
+	if !bytes.Equal(storedBody, body) {
+		t.Errorf("Expected the binary body to be stored as is, got %v", storedBody)
+	}
+}
+
+func TestEvidenceLateResults(t *testing.T) {
+	conf := ffuf.NewConfig(nil, nil)
+	conf.OutputDirectory = t.TempDir()
+	s := NewStdoutput(&conf)
+	done := make(chan bool)
+	closed := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				close(closed)
+				return
+			default:
+				s.closeEvidence()
+			}
+		}
+	}()
+	// results arriving while the output is finalized, like the OOB results of the wait window
+	for i := 0; i < 100; i++ {
+		s.writeResultToFile(*evidenceTestResponse("/late", "abcde3", []byte("late")))
+	}
+	close(done)
+	<-closed
+	s.closeEvidence()
+	if entries, err := LoadEvidenceIndex(conf.OutputDirectory); err != nil || len(entries) != 100 {
+		t.Errorf("Expected all the late results in the manifest, got %d: %v", len(entries), err)
+	}
+}
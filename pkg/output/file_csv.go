@@ -12,7 +12,6 @@ import (
 var staticheaders = []string{"url", "redirectlocation", "position", "status_code", "content_length", "content_words", "content_lines", "content_type", "duration", "resultfile", "Fuffahash"}
 
 func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bool) error {
-	header := make([]string, 0)
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -22,24 +21,12 @@ func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bo
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
-	for _, inputprovider := range config.InputProviders {
-		header = append(header, inputprovider.Keyword)
-	}
-	header = append(header, staticheaders...)
-
+	header := csvHeader(config)
 	if err := w.Write(header); err != nil {
 		return err
 	}
 	for _, r := range res {
-		if encode {
-			inputs := make(map[string][]byte, len(r.Input))
-			for k, v := range r.Input {
-				inputs[k] = []byte(base64encode(v))
-			}
-			r.Input = inputs
-		}
-
-		err := w.Write(toCSV(r))
+		err := w.Write(csvRecord(r, encode))
 		if err != nil {
 			return err
 		}
@@ -47,6 +34,26 @@ func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bo
 	return nil
 }
 
+func csvHeader(config *ffuf.Config) []string {
+	header := make([]string, 0)
+	for _, inputprovider := range config.InputProviders {
+		header = append(header, inputprovider.Keyword)
+	}
+	return append(header, staticheaders...)
+}
+
+// csvRecord returns the CSV row for a result, with the inputs base64 encoded if requested
+func csvRecord(r ffuf.Result, encode bool) []string {
+	if encode {
+		inputs := make(map[string][]byte, len(r.Input))
+		for k, v := range r.Input {
+			inputs[k] = []byte(base64encode(v))
+		}
+		r.Input = inputs
+	}
+	return toCSV(r)
+}
+
 func base64encode(in []byte) string {
 	return base64.StdEncoding.EncodeToString(in)
 }
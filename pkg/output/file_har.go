@@ -0,0 +1,221 @@
+package output
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// The HAR 1.2 structures, see http://www.softwareishard.com/blog/har-12-spec/
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+	// position and url identify the result the entry belongs to
+	position int
+	url      string
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []harNameValue `json:"params"`
+	Text     string         `json:"text"`
+}
+
+type harResponse struct {
+	Status      int64          `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// newHAREntry builds a HAR entry of the request and response pair. The headers are read from the raw request
+// when available, as it contains the headers added by the HTTP client too.
+func newHAREntry(resp *ffuf.Response) harEntry {
+	req := resp.Request
+	durationMs := float64(resp.Duration) / float64(time.Millisecond)
+	entry := harEntry{
+		StartedDateTime: req.Timestamp.Format(time.RFC3339Nano),
+		Time:            durationMs,
+		Timings:         harTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: durationMs, Receive: 0, SSL: -1},
+		position:        req.Position,
+		url:             req.Url,
+	}
+
+	entry.Request = harRequest{
+		Method:      req.Method,
+		URL:         req.Url,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(req.Data),
+	}
+	reqHeaders := http.Header{}
+	for k, v := range req.Headers {
+		reqHeaders.Set(k, v)
+	}
+	if raw, err := http.ReadRequest(bufio.NewReader(strings.NewReader(req.Raw))); err == nil {
+		entry.Request.HTTPVersion = raw.Proto
+		reqHeaders = raw.Header
+		if raw.Host != "" {
+			reqHeaders.Set("Host", raw.Host)
+		}
+	}
+	entry.Request.Headers = harHeaders(reqHeaders)
+	for _, c := range (&http.Request{Header: reqHeaders}).Cookies() {
+		entry.Request.Cookies = append(entry.Request.Cookies, harNameValue{Name: c.Name, Value: c.Value})
+	}
+	if u, err := url.Parse(req.Url); err == nil {
+		for name, values := range u.Query() {
+			for _, v := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: v})
+			}
+		}
+		sort.Slice(entry.Request.QueryString, func(i, j int) bool { return entry.Request.QueryString[i].Name < entry.Request.QueryString[j].Name })
+	}
+	if len(req.Data) > 0 {
+		entry.Request.PostData = &harPostData{MimeType: reqHeaders.Get("Content-Type"), Params: []harNameValue{}, Text: string(req.Data)}
+	}
+
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(int(resp.StatusCode)),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(resp.Headers),
+		RedirectURL: resp.GetRedirectLocation(true),
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+		Content:     harContent{Size: len(resp.Data), MimeType: resp.ContentType},
+	}
+	if proto := strings.SplitN(resp.Raw, " ", 2)[0]; strings.HasPrefix(proto, "HTTP/") {
+		entry.Response.HTTPVersion = proto
+	}
+	for _, c := range (&http.Response{Header: resp.Headers}).Cookies() {
+		entry.Response.Cookies = append(entry.Response.Cookies, harNameValue{Name: c.Name, Value: c.Value})
+	}
+	if utf8.Valid(resp.Data) {
+		entry.Response.Content.Text = string(resp.Data)
+	} else {
+		entry.Response.Content.Text = base64.StdEncoding.EncodeToString(resp.Data)
+		entry.Response.Content.Encoding = "base64"
+	}
+	if resp.Cancelled {
+		entry.Comment = "Response body was not downloaded"
+	}
+	return entry
+}
+
+// harHeaders returns the headers as a list of name-value pairs sorted by name
+func harHeaders(headers map[string][]string) []harNameValue {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hdrs := make([]harNameValue, 0, len(headers))
+	for _, name := range names {
+		for _, v := range headers[name] {
+			hdrs = append(hdrs, harNameValue{Name: name, Value: v})
+		}
+	}
+	return hdrs
+}
+
+// writeHAR writes the entries of the given results to a HAR file
+func writeHAR(filename string, entries []harEntry, res []ffuf.Result) error {
+	// only include the entries of results that have not been filtered out interactively
+	included := make(map[string]bool, len(res))
+	for _, r := range res {
+		included[resultKey(r.Position, r.Url)] = true
+	}
+	out := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "fuffa", Version: ffuf.Version()},
+		Entries: make([]harEntry, 0, len(entries)),
+	}}
+	for _, e := range entries {
+		if included[resultKey(e.position, e.url)] {
+			out.Log.Entries = append(out.Log.Entries, e)
+		}
+	}
+	outBytes, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, outBytes, 0644)
+}
+
+// resultKey identifies a result by its position and URL
+func resultKey(position int, url string) string {
+	return strconv.Itoa(position) + " " + url
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestHAREntry(t *testing.T) {
+	req := &ffuf.Request{
+		Method:    "POST",
+		Url:       "http://example.com/login?next=%2Fadmin",
+		Headers:   map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Data:      []byte("user=admin"),
+		Position:  3,
+		Timestamp: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		Raw:       "POST /login?next=%2Fadmin HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/x-www-form-urlencoded\r\nCookie: session=abc\r\n\r\nuser=admin",
+	}
+	resp := &ffuf.Response{
+		StatusCode:  302,
+		Headers:     map[string][]string{"Location": {"/admin"}, "Content-Type": {"application/octet-stream"}},
+		Data:        []byte{0xff, 0xfe, 0x00},
+		ContentType: "application/octet-stream",
+		Duration:    1500 * time.Millisecond,
+		Request:     req,
+		Raw:         "HTTP/1.1 302 Found\r\n",
+	}
+	e := newHAREntry(resp)
+	if e.StartedDateTime != "2022-01-01T12:00:00Z" || e.Time != 1500 || e.Timings.Wait != 1500 {
+		t.Errorf("Unexpected timings: %s %f %f", e.StartedDateTime, e.Time, e.Timings.Wait)
+	}
+	if e.Request.HTTPVersion != "HTTP/1.1" || len(e.Request.Cookies) != 1 || e.Request.Cookies[0].Value != "abc" {
+		t.Errorf("Unexpected request data read from the raw request: %+v", e.Request)
+	}
+	if len(e.Request.QueryString) != 1 || e.Request.QueryString[0].Value != "/admin" {
+		t.Errorf("Unexpected query string: %+v", e.Request.QueryString)
+	}
+	if e.Request.PostData == nil || e.Request.PostData.Text != "user=admin" || e.Request.PostData.MimeType != "application/x-www-form-urlencoded" {
+		t.Errorf("Unexpected post data: %+v", e.Request.PostData)
+	}
+	if e.Response.StatusText != "Found" || e.Response.RedirectURL != "http://example.com/admin" {
+		t.Errorf("Unexpected response status or redirect: %s %s", e.Response.StatusText, e.Response.RedirectURL)
+	}
+	if e.Response.Content.Encoding != "base64" || e.Response.Content.Text != base64.StdEncoding.EncodeToString(resp.Data) {
+		t.Errorf("Expected binary body to be base64 encoded, got: %+v", e.Response.Content)
+	}
+}
+
+func TestWriteHAR(t *testing.T) {
+	entries := []harEntry{
+		newHAREntry(&ffuf.Response{Data: []byte("kept"), Request: &ffuf.Request{Method: "GET", Url: "http://example.com/a", Position: 1}}),
+		newHAREntry(&ffuf.Response{Data: []byte("filtered"), Request: &ffuf.Request{Method: "GET", Url: "http://example.com/b", Position: 2}}),
+	}
+	results := []ffuf.Result{{Url: "http://example.com/a", Position: 1}}
+	filename := filepath.Join(t.TempDir(), "out.har")
+	if err := writeHAR(filename, entries, results); err != nil {
+		t.Fatalf("Could not write HAR file: %s", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Could not read HAR file: %s", err)
+	}
+	var out harFile
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Could not parse HAR file: %s", err)
+	}
+	if out.Log.Version != "1.2" || out.Log.Creator.Name != "fuffa" {
+		t.Errorf("Unexpected HAR log metadata: %+v", out.Log)
+	}
+	if len(out.Log.Entries) != 1 || out.Log.Entries[0].Response.Content.Text != "kept" {
+		t.Errorf("Expected only the entry of the remaining result, got: %+v", out.Log.Entries)
+	}
+}
@@ -32,6 +32,7 @@ type htmlFileOutput struct {
 	Time        string
 	Keys        []string
 	Results     []htmlResult
+	Diff        *DiffReport
 }
 
 const (
@@ -80,6 +81,7 @@ const (
 
 		<pre>{{ .CommandLine }}</pre>
 		<pre>{{ .Time }}</pre>
+		{{ if .Diff }}{{ template "diff" .Diff }}{{ end }}
 
    <table id="ffufreport">
         <thead>
@@ -200,7 +202,7 @@ func colorizeResults(results []ffuf.Result) []ffuf.Result {
 	return newResults
 }
 
-func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) error {
+func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result, diff *DiffReport) error {
 	results = colorizeResults(results)
 
 	ti := time.Now()
@@ -260,6 +262,7 @@ func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) erro
 		Time:        ti.Format(time.RFC3339),
 		Results:     htmlResults,
 		Keys:        keywords,
+		Diff:        diff,
 	}
 
 	f, err := os.Create(filename)
@@ -270,7 +273,7 @@ func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) erro
 
 	templateName := "output.html"
 	t := template.New(templateName).Delims("{{", "}}")
-	_, err = t.Parse(htmlTemplate)
+	_, err = t.Parse(htmlTemplate + diffHTMLTemplate)
 	if err != nil {
 		return err
 	}
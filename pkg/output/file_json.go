@@ -25,6 +25,7 @@ type JsonResult struct {
 	ContentType      string              `json:"content-type"`
 	RedirectLocation string              `json:"redirectlocation"`
 	ScraperData      map[string][]string `json:"scraper"`
+	Seed             string              `json:"seed,omitempty"`
 	Duration         time.Duration       `json:"duration"`
 	ResultFile       string              `json:"resultfile"`
 	Url              string              `json:"url"`
@@ -36,14 +37,64 @@ type jsonFileOutput struct {
 	Time        string       `json:"time"`
 	Results     []JsonResult `json:"results"`
 	Config      *ffuf.Config `json:"config"`
+	Diff        *DiffReport  `json:"diff,omitempty"`
+}
+
+func toJsonResult(r ffuf.Result) JsonResult {
+	strinput := make(map[string]string)
+	for k, v := range r.Input {
+		strinput[k] = string(v)
+	}
+	return JsonResult{
+		Input:            strinput,
+		Position:         r.Position,
+		StatusCode:       r.StatusCode,
+		ContentLength:    r.ContentLength,
+		ContentWords:     r.ContentWords,
+		ContentLines:     r.ContentLines,
+		ContentType:      r.ContentType,
+		RedirectLocation: r.RedirectLocation,
+		ScraperData:      r.ScraperData,
+		Seed:             r.Seed,
+		Duration:         r.Duration,
+		ResultFile:       r.ResultFile,
+		Url:              r.Url,
+		Host:             r.Host,
+	}
+}
+
+// writeJSONL writes the results as newline-delimited JSON, one result per line
+func writeJSONL(filename string, config *ffuf.Config, res []ffuf.Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, r := range res {
+		if err = enc.Encode(toJsonResult(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedConfig returns a copy of the config with the secrets masked, if a redaction policy is configured
+func redactedConfig(config *ffuf.Config) *ffuf.Config {
+	if config.Redaction == nil {
+		return config
+	}
+	return config.Redaction.Config(config)
 }
 
 func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
 	t := time.Now()
+	config = redactedConfig(config)
 	outJSON := ejsonFileOutput{
 		CommandLine: config.CommandLine,
 		Time:        t.Format(time.RFC3339),
 		Results:     res,
+		Config:      config,
 	}
 
 	outBytes, err := json.Marshal(outJSON)
@@ -57,35 +108,19 @@ func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
 	return nil
 }
 
-func writeJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
+func writeJSON(filename string, config *ffuf.Config, res []ffuf.Result, diff *DiffReport) error {
 	t := time.Now()
+	config = redactedConfig(config)
 	jsonRes := make([]JsonResult, 0)
 	for _, r := range res {
-		strinput := make(map[string]string)
-		for k, v := range r.Input {
-			strinput[k] = string(v)
-		}
-		jsonRes = append(jsonRes, JsonResult{
-			Input:            strinput,
-			Position:         r.Position,
-			StatusCode:       r.StatusCode,
-			ContentLength:    r.ContentLength,
-			ContentWords:     r.ContentWords,
-			ContentLines:     r.ContentLines,
-			ContentType:      r.ContentType,
-			RedirectLocation: r.RedirectLocation,
-			ScraperData:      r.ScraperData,
-			Duration:         r.Duration,
-			ResultFile:       r.ResultFile,
-			Url:              r.Url,
-			Host:             r.Host,
-		})
+		jsonRes = append(jsonRes, toJsonResult(r))
 	}
 	outJSON := jsonFileOutput{
 		CommandLine: config.CommandLine,
 		Time:        t.Format(time.RFC3339),
 		Results:     jsonRes,
 		Config:      config,
+		Diff:        diff,
 	}
 	outBytes, err := json.Marshal(outJSON)
 	if err != nil {
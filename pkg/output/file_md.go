@@ -17,10 +17,10 @@ const (
   {{ range .Keys }}| {{ . }} {{ end }}| URL | Redirectlocation | Position | Status Code | Content Length | Content Words | Content Lines | Content Type | Duration | ResultFile | ScraperData | Ffufhash
   {{ range .Keys }}| :- {{ end }}| :-- | :--------------- | :---- | :------- | :---------- | :------------- | :------------ | :--------- | :----------- | :------------ | :-------- |
   {{range .Results}}{{ range $keyword, $value := .Input }}| {{ $value | printf "%s" }} {{ end }}| {{ .Url }} | {{ .RedirectLocation }} | {{ .Position }} | {{ .StatusCode }} | {{ .ContentLength }} | {{ .ContentWords }} | {{ .ContentLines }} | {{ .ContentType }} | {{ .Duration}} | {{ .ResultFile }} | {{ .ScraperData }} | {{ .FuffahHash }}
-  {{end}}` // The template format is not pretty but follows the markdown guide
+  {{end}}{{ if .Diff }}{{ template "diff" .Diff }}{{ end }}` // The template format is not pretty but follows the markdown guide
 )
 
-func writeMarkdown(filename string, config *ffuf.Config, results []ffuf.Result) error {
+func writeMarkdown(filename string, config *ffuf.Config, results []ffuf.Result, diff *DiffReport) error {
 	ti := time.Now()
 
 	keywords := make([]string, 0)
@@ -79,6 +79,7 @@ func writeMarkdown(filename string, config *ffuf.Config, results []ffuf.Result)
 		Time:        ti.Format(time.RFC3339),
 		Results:     htmlResults,
 		Keys:        keywords,
+		Diff:        diff,
 	}
 
 	f, err := os.Create(filename)
@@ -89,7 +90,7 @@ func writeMarkdown(filename string, config *ffuf.Config, results []ffuf.Result)
 
 	templateName := "output.md"
 	t := template.New(templateName).Delims("{{", "}}")
-	_, err = t.Parse(markdownTemplate)
+	_, err = t.Parse(markdownTemplate + diffMarkdownTemplate)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,354 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// reportPreviewLimit is the maximum size of a request or response embedded in the report for the preview
+const reportPreviewLimit = 256 * 1024
+
+type reportResult struct {
+	Id          int                 `json:"id"`
+	Job         string              `json:"job"`
+	Hash        string              `json:"hash"`
+	Input       map[string]string   `json:"input"`
+	Position    int                 `json:"position"`
+	StatusCode  int64               `json:"status"`
+	Length      int64               `json:"length"`
+	Words       int64               `json:"words"`
+	Lines       int64               `json:"lines"`
+	ContentType string              `json:"content_type"`
+	Redirect    string              `json:"redirect"`
+	Duration    int64               `json:"duration"`
+	Url         string              `json:"url"`
+	Host        string              `json:"host"`
+	ScraperData map[string][]string `json:"scraper"`
+	ResultFile  string              `json:"resultfile"`
+	Request     string              `json:"request,omitempty"`
+	Response    string              `json:"response,omitempty"`
+	Truncated   bool                `json:"truncated,omitempty"`
+}
+
+type reportFileOutput struct {
+	CommandLine string         `json:"commandline"`
+	Time        string         `json:"time"`
+	Version     string         `json:"version"`
+	Keys        []string       `json:"keys"`
+	Results     []reportResult `json:"results"`
+}
+
+// reportPreview reads the request and response of the result from the evidence store of the output directory
+func reportPreview(config *ffuf.Config, evidence map[string]EvidenceEntry, resultFile string) (request, response string, truncated bool) {
+	entry, ok := evidence[resultFile]
+	if !ok {
+		return "", "", false
+	}
+	req, head, body, err := ReadEvidence(config.OutputDirectory, entry)
+	if err != nil {
+		return "", "", false
+	}
+	request, response = string(req), string(head)+string(body)
+	if len(request) > reportPreviewLimit {
+		request, truncated = request[:reportPreviewLimit], true
+	}
+	if len(response) > reportPreviewLimit {
+		response, truncated = response[:reportPreviewLimit], true
+	}
+	return request, response, truncated
+}
+
+// writeReport writes a self-contained single-page HTML report with grouping, response previews and false
+// positive tagging. The results are embedded as JSON and rendered in the browser, without any external assets.
+func writeReport(filename string, config *ffuf.Config, res []ffuf.Result, jobs map[string]string) error {
+	keywords := make([]string, 0)
+	for _, inputprovider := range config.InputProviders {
+		keywords = append(keywords, inputprovider.Keyword)
+	}
+	evidence := make(map[string]EvidenceEntry)
+	if config.OutputDirectory != "" {
+		// the results have no saved requests and responses if the manifest is missing
+		entries, _ := LoadEvidenceIndex(config.OutputDirectory)
+		for _, entry := range entries {
+			evidence[entry.Id] = entry
+		}
+	}
+	results := make([]reportResult, 0, len(res))
+	for i, r := range res {
+		inputs := make(map[string]string, len(r.Input))
+		hash := ""
+		for k, v := range r.Input {
+			if k == "FUFFAHASH" {
+				hash = string(v)
+			} else {
+				inputs[k] = string(v)
+			}
+		}
+		job, ok := jobs[resultKey(r.Position, r.Url)]
+		if !ok {
+			job = config.Url
+		}
+		request, response, truncated := reportPreview(config, evidence, r.ResultFile)
+		results = append(results, reportResult{
+			Id:          i,
+			Job:         job,
+			Hash:        hash,
+			Input:       inputs,
+			Position:    r.Position,
+			StatusCode:  r.StatusCode,
+			Length:      r.ContentLength,
+			Words:       r.ContentWords,
+			Lines:       r.ContentLines,
+			ContentType: r.ContentType,
+			Redirect:    r.RedirectLocation,
+			Duration:    r.Duration.Milliseconds(),
+			Url:         r.Url,
+			Host:        r.Host,
+			ScraperData: r.ScraperData,
+			ResultFile:  r.ResultFile,
+			Request:     request,
+			Response:    response,
+			Truncated:   truncated,
+		})
+	}
+	// json.Marshal escapes <, > and &, so the data can not break out of the script element
+	data, err := json.Marshal(reportFileOutput{
+		CommandLine: config.CommandLine,
+		Time:        time.Now().Format(time.RFC3339),
+		Version:     ffuf.Version(),
+		Keys:        keywords,
+		Results:     results,
+	})
+	if err != nil {
+		return err
+	}
+	t, err := template.New("report.html").Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.Execute(f, string(data))
+}
+
+const reportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8" />
+<meta name="viewport" content="width=device-width, initial-scale=1" />
+<title>FUFFA Report</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 0; color: #222; background: #f4f5f7; }
+  header { background: #263238; color: #fff; padding: 12px 20px; }
+  header pre { margin: 4px 0 0; white-space: pre-wrap; word-break: break-all; color: #cfd8dc; }
+  #controls { padding: 10px 20px; background: #fff; border-bottom: 1px solid #ddd; position: sticky; top: 0; z-index: 1; }
+  #controls label { margin-right: 14px; }
+  main { padding: 10px 20px; }
+  details { background: #fff; margin-bottom: 10px; border: 1px solid #ddd; border-radius: 4px; }
+  summary { padding: 8px 12px; cursor: pointer; font-weight: bold; }
+  table { border-collapse: collapse; width: 100%; font-size: 13px; }
+  th, td { text-align: left; padding: 4px 8px; border-top: 1px solid #eee; vertical-align: top; }
+  td.url { word-break: break-all; }
+  tr.result { cursor: pointer; }
+  tr.result:hover { background: #eef3fb; }
+  tr.fp td { text-decoration: line-through; color: #999; }
+  tr.preview td { background: #fafafa; }
+  .s2 { color: #2e7d32; } .s3 { color: #1565c0; } .s4 { color: #9e7700; } .s5 { color: #c62828; }
+  .reflected { color: #c62828; font-weight: bold; }
+  .panes { display: flex; gap: 10px; }
+  .panes > div { flex: 1; min-width: 0; }
+  .panes pre { max-height: 480px; overflow: auto; background: #fff; border: 1px solid #ddd; padding: 6px; white-space: pre-wrap; word-break: break-all; }
+  mark { background: #ffeb3b; }
+  #export { width: 100%; height: 90px; font-family: monospace; display: none; }
+</style>
+</head>
+<body>
+<header>
+  <strong>FUFFA Report</strong> <span id="meta"></span>
+  <pre id="cmdline"></pre>
+</header>
+<div id="controls">
+  <label>Group by
+    <select id="group">
+      <option value="job">Job</option>
+      <option value="host">Host</option>
+      <option value="signature">Status / size</option>
+      <option value="none">Nothing</option>
+    </select>
+  </label>
+  <label>Search <input id="search" type="search" placeholder="URL, input or content type" /></label>
+  <label><input id="hidefp" type="checkbox" /> Hide false positives</label>
+  <label>Export false positives as
+    <select id="exportattr">
+      <option value="length">-fs (size)</option>
+      <option value="words">-fw (words)</option>
+      <option value="lines">-fl (lines)</option>
+      <option value="status">-fc (status)</option>
+    </select>
+  </label>
+  <button id="exportbtn" type="button">Export filters</button>
+  <textarea id="export" readonly></textarea>
+</div>
+<main id="groups"></main>
+<script type="application/json" id="fuffa-data">{{ . }}</script>
+<script>
+(function () {
+  "use strict";
+  var data = JSON.parse(document.getElementById("fuffa-data").textContent);
+  var storageKey = "fuffa-fp:" + data.commandline + ":" + data.time;
+  var fp = {};
+  try { (JSON.parse(localStorage.getItem(storageKey)) || []).forEach(function (id) { fp[id] = true; }); } catch (e) {}
+  var open = {};
+
+  function esc(s) {
+    return String(s).replace(/[&<>"']/g, function (c) {
+      return { "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" }[c];
+    });
+  }
+  function payloads(r) {
+    return Object.keys(r.input).map(function (k) { return r.input[k]; })
+      .filter(function (p) { return p.length > 0; })
+      .sort(function (a, b) { return b.length - a.length; });
+  }
+  function payloadRegexp(r) {
+    var p = payloads(r);
+    if (!p.length) { return null; }
+    return new RegExp(p.map(function (s) { return s.replace(/[.*+?^${}()|[\]\\]/g, "\\$&"); }).join("|"), "g");
+  }
+  function highlight(text, re) {
+    if (!re) { return esc(text); }
+    var out = "", last = 0, m;
+    re.lastIndex = 0;
+    while ((m = re.exec(text)) !== null) {
+      if (m[0].length === 0) { re.lastIndex++; continue; }
+      out += esc(text.slice(last, m.index)) + "<mark>" + esc(m[0]) + "</mark>";
+      last = m.index + m[0].length;
+    }
+    return out + esc(text.slice(last));
+  }
+  function reflections(r) {
+    var re = payloadRegexp(r);
+    if (!re || !r.response) { return 0; }
+    var m = r.response.match(re);
+    return m ? m.length : 0;
+  }
+  function groupKey(r, by) {
+    switch (by) {
+      case "job": return "Job " + r.job;
+      case "host": return "Host " + r.host;
+      case "signature": return "Status " + r.status + ", size " + r.length;
+    }
+    return "All results";
+  }
+  function matches(r, q) {
+    if (!q) { return true; }
+    var hay = [r.url, r.content_type, r.redirect].concat(payloads(r)).join("\n").toLowerCase();
+    return hay.indexOf(q) !== -1;
+  }
+  function inputString(r) {
+    return data.keys.map(function (k) { return esc(r.input[k] || ""); }).join("</td><td>");
+  }
+  function preview(r) {
+    var re = payloadRegexp(r), html = "";
+    var scraper = Object.keys(r.scraper || {}).filter(function (k) { return r.scraper[k] && r.scraper[k].length; });
+    if (scraper.length) {
+      html += "<p><b>Scraper data:</b> " + scraper.map(function (k) { return esc(k) + ": " + esc(r.scraper[k].join(", ")); }).join("; ") + "</p>";
+    }
+    if (r.redirect) { html += "<p><b>Redirect:</b> " + esc(r.redirect) + "</p>"; }
+    if (r.hash) { html += "<p><b>FUFFAHASH:</b> " + esc(r.hash) + "</p>"; }
+    if (!r.request && !r.response) {
+      return html + "<p>No saved request or response, run with -od to include them in the report.</p>";
+    }
+    var n = reflections(r);
+    html += "<p>" + (n ? "<span class=\"reflected\">Payload reflected " + n + " times in the response</span>" : "Payload not reflected in the response") +
+      (r.truncated ? " (preview truncated)" : "") + "</p>";
+    return html + "<div class=\"panes\"><div><b>Request</b><pre>" + highlight(r.request, re) + "</pre></div>" +
+      "<div><b>Response</b><pre>" + highlight(r.response, re) + "</pre></div></div>";
+  }
+  function row(r) {
+    var cls = "result" + (fp[r.id] ? " fp" : "");
+    var reflected = reflections(r) ? "<span class=\"reflected\" title=\"Payload reflected in the response\">&#8617;</span>" : "";
+    var html = "<tr class=\"" + cls + "\" data-id=\"" + r.id + "\">" +
+      "<td class=\"s" + String(r.status).charAt(0) + "\">" + r.status + "</td>" +
+      (data.keys.length ? "<td>" + inputString(r) + "</td>" : "") +
+      "<td class=\"url\"><a href=\"" + esc(r.url) + "\" target=\"_blank\" rel=\"noreferrer\">" + esc(r.url) + "</a> " + reflected + "</td>" +
+      "<td>" + r.length + "</td><td>" + r.words + "</td><td>" + r.lines + "</td>" +
+      "<td>" + esc(r.content_type) + "</td><td>" + r.duration + "ms</td>" +
+      "<td><input type=\"checkbox\" class=\"fptoggle\" data-id=\"" + r.id + "\"" + (fp[r.id] ? " checked" : "") + " /></td></tr>";
+    if (open[r.id]) {
+      html += "<tr class=\"preview\"><td colspan=\"" + (8 + data.keys.length) + "\">" + preview(r) + "</td></tr>";
+    }
+    return html;
+  }
+  function render() {
+    var by = document.getElementById("group").value;
+    var q = document.getElementById("search").value.toLowerCase();
+    var hidefp = document.getElementById("hidefp").checked;
+    var groups = {}, order = [];
+    data.results.forEach(function (r) {
+      if ((hidefp && fp[r.id]) || !matches(r, q)) { return; }
+      var key = groupKey(r, by);
+      if (!groups[key]) { groups[key] = []; order.push(key); }
+      groups[key].push(r);
+    });
+    var head = "<tr><th>Status</th>" + data.keys.map(function (k) { return "<th>" + esc(k) + "</th>"; }).join("") +
+      "<th>URL</th><th>Size</th><th>Words</th><th>Lines</th><th>Type</th><th>Duration</th><th>False positive</th></tr>";
+    document.getElementById("groups").innerHTML = order.map(function (key) {
+      return "<details open><summary>" + esc(key) + " (" + groups[key].length + ")</summary><table><thead>" + head +
+        "</thead><tbody>" + groups[key].map(row).join("") + "</tbody></table></details>";
+    }).join("") || "<p>No results.</p>";
+  }
+  function save() {
+    try { localStorage.setItem(storageKey, JSON.stringify(Object.keys(fp).map(Number))); } catch (e) {}
+  }
+  function exportFilters() {
+    var attr = document.getElementById("exportattr").value;
+    var flags = { length: ["-fs", "size"], words: ["-fw", "words"], lines: ["-fl", "lines"], status: ["-fc", "status"] }[attr];
+    var values = [];
+    data.results.forEach(function (r) {
+      if (fp[r.id] && values.indexOf(r[attr]) === -1) { values.push(r[attr]); }
+    });
+    values.sort(function (a, b) { return a - b; });
+    var out = document.getElementById("export");
+    out.style.display = "block";
+    out.value = values.length ? flags[0] + " " + values.join(",") + "\n\n[filter]\n    " + flags[1] + " = \"" + values.join(",") + "\"\n" :
+      "Tag results as false positives first.";
+    out.select();
+  }
+
+  document.getElementById("meta").textContent = "v" + data.version + ", " + data.time + ", " + data.results.length + " results";
+  document.getElementById("cmdline").textContent = data.commandline;
+  document.getElementById("group").addEventListener("change", render);
+  document.getElementById("search").addEventListener("input", render);
+  document.getElementById("hidefp").addEventListener("change", render);
+  document.getElementById("exportbtn").addEventListener("click", exportFilters);
+  document.getElementById("groups").addEventListener("click", function (e) {
+    var t = e.target;
+    if (t.classList.contains("fptoggle")) {
+      var id = Number(t.getAttribute("data-id"));
+      if (t.checked) { fp[id] = true; } else { delete fp[id]; }
+      save();
+      render();
+      return;
+    }
+    if (t.tagName === "A") { return; }
+    var tr = t.closest("tr.result");
+    if (tr) {
+      var rid = Number(tr.getAttribute("data-id"));
+      open[rid] = !open[rid];
+      render();
+    }
+  });
+  render();
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{
+		OutputDirectory: dir,
+		Url:             "http://example.com/FUZZ",
+		InputProviders:  []ffuf.InputProviderConfig{{Keyword: "FUZZ"}},
+	}
+	req := ffuf.NewRequest(conf)
+	req.Raw = "GET /</script><b> HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	body := "You searched for </script><b>"
+	resp := ffuf.Response{Request: &req, Raw: "HTTP/1.1 200 OK\r\n\r\n" + body, Data: []byte(body)}
+	store, err := NewEvidenceStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := store.Add(&resp)
+	store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultFile := entry.Id
+	res := []ffuf.Result{
+		{
+			Input:      map[string][]byte{"FUZZ": []byte("</script><b>"), "FUFFAHASH": []byte("abcde1")},
+			Position:   1,
+			StatusCode: 200,
+			Url:        "http://example.com/dir/</script><b>",
+			Host:       "example.com",
+			ResultFile: resultFile,
+		},
+		{Input: map[string][]byte{"FUZZ": []byte("other")}, Position: 2, StatusCode: 403, Url: "http://example.com/other"},
+	}
+	jobs := map[string]string{resultKey(1, res[0].Url): "http://example.com/dir/FUZZ"}
+	filename := filepath.Join(t.TempDir(), "report.html")
+	if err := writeReport(filename, conf, res, jobs); err != nil {
+		t.Fatalf("Could not write the report: %s", err)
+	}
+	content, _ := os.ReadFile(filename)
+	doc := string(content)
+	if strings.Count(doc, "</script>") != 2 {
+		t.Errorf("Expected the embedded data to not close the script element")
+	}
+	if strings.Contains(doc, "src=\"http") || strings.Contains(doc, "href=\"http") {
+		t.Errorf("Expected the report to not load external assets")
+	}
+	start := strings.Index(doc, `id="fuffa-data">`) + len(`id="fuffa-data">`)
+	end := strings.Index(doc[start:], "</script>")
+	var data reportFileOutput
+	if err := json.Unmarshal([]byte(doc[start:start+end]), &data); err != nil {
+		t.Fatalf("Could not parse the embedded report data: %s", err)
+	}
+	if len(data.Results) != 2 || len(data.Keys) != 1 || data.Keys[0] != "FUZZ" {
+		t.Fatalf("Unexpected report data: %+v", data)
+	}
+	first := data.Results[0]
+	if first.Job != "http://example.com/dir/FUZZ" || first.Hash != "abcde1" || first.Input["FUZZ"] != "</script><b>" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if !strings.HasPrefix(first.Request, "GET /</script><b>") || !strings.HasSuffix(first.Response, "You searched for </script><b>") {
+		t.Errorf("Expected the saved request and response in the report, got %q and %q", first.Request, first.Response)
+	}
+	if second := data.Results[1]; second.Job != conf.Url || second.Request != "" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+}
@@ -0,0 +1,205 @@
+package output
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// sqliteSchema creates the result database tables. Every fuffa run appends a new row to runs, and the
+// queued jobs of a run, their results and the related data refer to it through foreign keys.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	commandline TEXT NOT NULL,
+	version TEXT NOT NULL,
+	config TEXT NOT NULL,
+	started_at TEXT NOT NULL,
+	finished_at TEXT
+);
+CREATE TABLE IF NOT EXISTS jobs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	url TEXT NOT NULL,
+	started_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INTEGER NOT NULL REFERENCES jobs(id),
+	position INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	host TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	length INTEGER NOT NULL,
+	words INTEGER NOT NULL,
+	lines INTEGER NOT NULL,
+	content_type TEXT NOT NULL,
+	redirect_location TEXT NOT NULL,
+	duration_ns INTEGER NOT NULL,
+	result_file TEXT NOT NULL,
+	seed TEXT NOT NULL,
+	fuffahash TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS inputs (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	keyword TEXT NOT NULL,
+	value BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS scraper_data (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	name TEXT NOT NULL,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS requests (
+	result_id INTEGER PRIMARY KEY REFERENCES results(id),
+	method TEXT NOT NULL,
+	url TEXT NOT NULL,
+	host TEXT NOT NULL,
+	body_length INTEGER NOT NULL,
+	sent_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS headers (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	direction TEXT NOT NULL CHECK (direction IN ('request', 'response')),
+	name TEXT NOT NULL,
+	value TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_run ON jobs(run_id);
+CREATE INDEX IF NOT EXISTS idx_results_job ON results(job_id);
+CREATE INDEX IF NOT EXISTS idx_results_url ON results(url);
+CREATE INDEX IF NOT EXISTS idx_inputs_result ON inputs(result_id);
+CREATE INDEX IF NOT EXISTS idx_scraper_data_result ON scraper_data(result_id);
+CREATE INDEX IF NOT EXISTS idx_headers_result ON headers(result_id);
+`
+
+// sqliteWriter writes the results to a SQLite database as they arrive. The methods are not safe for
+// concurrent use, the caller is expected to serialize the calls.
+type sqliteWriter struct {
+	db    *sql.DB
+	runID int64
+	jobID int64
+}
+
+// openSQLite opens or creates the database, and adds a new run to it
+func openSQLite(filename string, config *ffuf.Config) (*sqliteWriter, error) {
+	db, err := sql.Open("sqlite3", filename+"?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	configJson, err := json.Marshal(redactedConfig(config))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	res, err := db.Exec("INSERT INTO runs (commandline, version, config, started_at) VALUES (?, ?, ?, ?)",
+		redactedConfig(config).CommandLine, ffuf.Version(), string(configJson), time.Now().Format(time.RFC3339))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	w := &sqliteWriter{db: db}
+	w.runID, err = res.LastInsertId()
+	return w, err
+}
+
+// startJob adds a new job to the current run, the following results are written for it
+func (w *sqliteWriter) startJob(url string) error {
+	res, err := w.db.Exec("INSERT INTO jobs (run_id, url, started_at) VALUES (?, ?, ?)", w.runID, url, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	w.jobID, err = res.LastInsertId()
+	return err
+}
+
+// writeResult writes a result and its related data in a single transaction. The request and response
+// metadata is written if resp is not nil.
+func (w *sqliteWriter) writeResult(r ffuf.Result, resp *ffuf.Response) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	// rolling back is a no-op after a successful commit
+	defer tx.Rollback()
+	res, err := tx.Exec(`INSERT INTO results (job_id, position, url, host, status, length, words, lines, content_type,
+		redirect_location, duration_ns, result_file, seed, fuffahash, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.jobID, r.Position, r.Url, r.Host, r.StatusCode, r.ContentLength, r.ContentWords, r.ContentLines, r.ContentType,
+		r.RedirectLocation, r.Duration.Nanoseconds(), r.ResultFile, r.Seed, string(r.Input["FUFFAHASH"]), time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	resultID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for k, v := range r.Input {
+		if k == "FUFFAHASH" {
+			continue
+		}
+		if _, err = tx.Exec("INSERT INTO inputs (result_id, keyword, value) VALUES (?, ?, ?)", resultID, k, v); err != nil {
+			return err
+		}
+	}
+	for name, values := range r.ScraperData {
+		for _, v := range values {
+			if _, err = tx.Exec("INSERT INTO scraper_data (result_id, name, value) VALUES (?, ?, ?)", resultID, name, v); err != nil {
+				return err
+			}
+		}
+	}
+	if resp != nil && resp.Request != nil {
+		req := resp.Request
+		if _, err = tx.Exec("INSERT INTO requests (result_id, method, url, host, body_length, sent_at) VALUES (?, ?, ?, ?, ?, ?)",
+			resultID, req.Method, req.Url, req.Host, len(req.Data), req.Timestamp.Format(time.RFC3339Nano)); err != nil {
+			return err
+		}
+		for name, value := range req.Headers {
+			if _, err = tx.Exec("INSERT INTO headers (result_id, direction, name, value) VALUES (?, 'request', ?, ?)", resultID, name, value); err != nil {
+				return err
+			}
+		}
+		for name, values := range resp.Headers {
+			for _, value := range values {
+				if _, err = tx.Exec("INSERT INTO headers (result_id, direction, name, value) VALUES (?, 'response', ?, ?)", resultID, name, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// close marks the run finished and closes the database
+func (w *sqliteWriter) close() error {
+	_, err := w.db.Exec("UPDATE runs SET finished_at = ? WHERE id = ?", time.Now().Format(time.RFC3339), w.runID)
+	if cerr := w.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeSQLite appends the results to the database as a new run with a single job
+func writeSQLite(filename string, config *ffuf.Config, res []ffuf.Result) error {
+	w, err := openSQLite(filename, config)
+	if err != nil {
+		return err
+	}
+	if err = w.startJob(config.Url); err != nil {
+		w.close()
+		return err
+	}
+	for _, r := range res {
+		if err = w.writeResult(r, nil); err != nil {
+			w.close()
+			return err
+		}
+	}
+	return w.close()
+}
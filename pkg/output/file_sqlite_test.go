@@ -0,0 +1,97 @@
+//go:build cgo
+// +build cgo
+
+package output
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestSQLiteAppendsRuns(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.sqlite")
+	conf := &ffuf.Config{CommandLine: "fuffa -u http://example.com/FUZZ", Url: "http://example.com/FUZZ"}
+	result := ffuf.Result{
+		Input:       map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte("abc")},
+		StatusCode:  200,
+		Url:         "http://example.com/admin",
+		Duration:    time.Duration(123),
+		ScraperData: map[string][]string{"title": {"Admin", "Login"}},
+	}
+
+	w, err := openSQLite(filename, conf)
+	if err != nil {
+		t.Fatalf("Could not open database: %s", err)
+	}
+	if err = w.startJob(conf.Url); err != nil {
+		t.Fatalf("Could not start job: %s", err)
+	}
+	resp := &ffuf.Response{
+		Headers: map[string][]string{"Server": {"nginx"}},
+		Request: &ffuf.Request{Method: "GET", Url: result.Url, Headers: map[string]string{"User-Agent": "fuffa"}},
+	}
+	if err = w.writeResult(result, resp); err != nil {
+		t.Fatalf("Could not write result: %s", err)
+	}
+	if err = w.close(); err != nil {
+		t.Fatalf("Could not close database: %s", err)
+	}
+	// A later run appends to the same database
+	if err = writeSQLite(filename, conf, []ffuf.Result{result}); err != nil {
+		t.Fatalf("Could not append to database: %s", err)
+	}
+
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatalf("Could not reopen database: %s", err)
+	}
+	defer db.Close()
+	for query, expected := range map[string]int{
+		"SELECT COUNT(*) FROM runs WHERE finished_at IS NOT NULL":                              2,
+		"SELECT COUNT(*) FROM jobs":                                                            2,
+		"SELECT COUNT(*) FROM results WHERE fuffahash = 'abc'":                                 2,
+		"SELECT COUNT(*) FROM inputs WHERE keyword = 'FUZZ' AND value = CAST('admin' AS BLOB)": 2,
+		"SELECT COUNT(*) FROM scraper_data":                                                    4,
+		"SELECT COUNT(*) FROM requests":                                                        1,
+		"SELECT COUNT(*) FROM headers WHERE direction = 'response' AND value = 'nginx'":        1,
+	} {
+		var count int
+		if err := db.QueryRow(query).Scan(&count); err != nil {
+			t.Errorf("Query %q failed: %s", query, err)
+		} else if count != expected {
+			t.Errorf("Query %q: expected %d but got %d", query, expected, count)
+		}
+	}
+}
+
+func TestSQLiteRedaction(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.sqlite")
+	conf := ffuf.NewConfig(nil, nil)
+	conf.Url = "http://example.com/FUZZ"
+	conf.Redaction, _ = ffuf.NewRedactionPolicy(true, nil, nil, nil)
+	s := NewFileOutput(&conf, "sqlite", filename, false)
+	req := ffuf.NewRequest(&conf)
+	req.Url = "http://example.com/admin"
+	req.Headers = map[string]string{"Authorization": "Bearer s3cret"}
+	req.Input = map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte("abc")}
+	s.Result(ffuf.Response{StatusCode: 200, Headers: map[string][]string{"Set-Cookie": {"sid=s3cret"}}, Request: &req})
+	if err := s.closeSQLite(); err != nil {
+		t.Fatalf("Could not close database: %s", err)
+	}
+
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatalf("Could not reopen database: %s", err)
+	}
+	defer db.Close()
+	var leaked, redacted int
+	db.QueryRow("SELECT COUNT(*) FROM headers WHERE value LIKE '%s3cret%'").Scan(&leaked)
+	db.QueryRow("SELECT COUNT(*) FROM headers WHERE value = ?", ffuf.REDACTED).Scan(&redacted)
+	if leaked != 0 || redacted != 2 {
+		t.Errorf("Expected the secret headers to be redacted, got %d leaked and %d redacted", leaked, redacted)
+	}
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+const (
+	// streamSyncBatch is the number of results after which the stream file is synced to disk
+	streamSyncBatch = 50
+	// streamSyncInterval is the longest time a written result waits to be synced to disk
+	streamSyncInterval = 2 * time.Second
+
+	// htmlStreamHeader starts the append-friendly HTML document, the rows are appended to the open table
+	htmlStreamHeader = `<!DOCTYPE html>
+<html>
+<head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8" /><title>FUFFA Report - in progress</title></head>
+<body>
+<p>Command line: <code>%s</code></p>
+<p>Started: %s</p>
+<table border="1">
+<tr><th>Status</th><th>URL</th><th>Input</th><th>Redirect location</th><th>Position</th><th>Length</th><th>Words</th><th>Lines</th><th>Content type</th><th>Duration</th><th>Result file</th><th>FUFFAHASH</th></tr>
+`
+	htmlStreamFooter = `</table>
+</body>
+</html>
+`
+)
+
+// streamFormats are the output formats written incrementally while the results arrive
+var streamFormats = []string{"jsonl", "csv", "ecsv", "html"}
+
+// streamWriter appends each result to the output file as it arrives, so the results survive if the process
+// gets killed. The file is synced to disk in batches. The methods are not safe for concurrent use.
+type streamWriter struct {
+	file      *os.File
+	buf       *bufio.Writer
+	csv       *csv.Writer
+	format    string
+	unsynced  int
+	lastSync  time.Time
+	keywords  []string
+	closeHTML bool
+}
+
+func isStreamFormat(format string) bool {
+	return ffuf.StrInSlice(format, streamFormats)
+}
+
+// openStream creates the output file and writes the header of the format to it
+func openStream(filename, format string, config *ffuf.Config) (*streamWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w := &streamWriter{file: f, buf: bufio.NewWriter(f), format: format, lastSync: time.Now()}
+	switch format {
+	case "csv", "ecsv":
+		w.csv = csv.NewWriter(w.buf)
+		err = w.csv.Write(csvHeader(config))
+	case "html":
+		for _, ip := range config.InputProviders {
+			w.keywords = append(w.keywords, ip.Keyword)
+		}
+		sort.Strings(w.keywords)
+		_, err = fmt.Fprintf(w.buf, htmlStreamHeader, html.EscapeString(config.CommandLine), time.Now().Format(time.RFC3339))
+		w.closeHTML = true
+	}
+	if err == nil {
+		err = w.flush(true)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// write appends a result to the file, syncing it to disk if the batch is full or the interval has passed
+func (w *streamWriter) write(r ffuf.Result) error {
+	var err error
+	switch w.format {
+	case "jsonl":
+		err = json.NewEncoder(w.buf).Encode(toJsonResult(r))
+	case "csv", "ecsv":
+		err = w.csv.Write(csvRecord(r, w.format == "ecsv"))
+	case "html":
+		err = w.writeHTMLRow(r)
+	}
+	if err != nil {
+		return err
+	}
+	w.unsynced++
+	return w.flush(w.unsynced >= streamSyncBatch || time.Since(w.lastSync) >= streamSyncInterval)
+}
+
+func (w *streamWriter) writeHTMLRow(r ffuf.Result) error {
+	inputs := make([]string, 0, len(w.keywords))
+	for _, k := range w.keywords {
+		inputs = append(inputs, html.EscapeString(k+": "+string(r.Input[k])))
+	}
+	_, err := fmt.Fprintf(w.buf, "<tr><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+		r.StatusCode, html.EscapeString(r.Url), strings.Join(inputs, "<br />"), html.EscapeString(r.RedirectLocation), r.Position,
+		r.ContentLength, r.ContentWords, r.ContentLines, html.EscapeString(r.ContentType), r.Duration, html.EscapeString(r.ResultFile), html.EscapeString(string(r.Input["FUFFAHASH"])))
+	return err
+}
+
+// flush writes the buffered data to the file, and syncs the file to disk if requested
+func (w *streamWriter) flush(sync bool) error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if !sync {
+		return nil
+	}
+	w.unsynced = 0
+	w.lastSync = time.Now()
+	return w.file.Sync()
+}
+
+// close completes the document, syncs it to disk and closes the file
+func (w *streamWriter) close() error {
+	var err error
+	if w.closeHTML {
+		_, err = w.buf.WriteString(htmlStreamFooter)
+	}
+	if err == nil {
+		err = w.flush(true)
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestStreamWriter(t *testing.T) {
+	conf := &ffuf.Config{
+		CommandLine:    "fuffa -u http://example.com/FUZZ",
+		InputProviders: []ffuf.InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}},
+	}
+	results := []ffuf.Result{
+		{Input: map[string][]byte{"FUZZ": []byte("admin")}, StatusCode: 200, Url: "http://example.com/admin"},
+		{Input: map[string][]byte{"FUZZ": []byte("<login>")}, StatusCode: 302, Url: "http://example.com/<login>"},
+	}
+	for _, test := range []struct {
+		format   string
+		expected []string
+	}{
+		{"jsonl", []string{`"url":"http://example.com/admin"`, `"status":302`}},
+		{"csv", []string{"FUZZ,url,", "admin,http://example.com/admin,"}},
+		{"ecsv", []string{"YWRtaW4=,http://example.com/admin,"}},
+		{"html", []string{"<td>200</td><td>http://example.com/admin</td>", "FUZZ: &lt;login&gt;"}},
+	} {
+		filename := filepath.Join(t.TempDir(), "out."+test.format)
+		w, err := openStream(filename, test.format, conf)
+		if err != nil {
+			t.Fatalf("%s: could not open stream: %s", test.format, err)
+		}
+		for _, r := range results {
+			if err := w.write(r); err != nil {
+				t.Fatalf("%s: could not write result: %s", test.format, err)
+			}
+		}
+		// The results are in the file before it is closed
+		data, _ := os.ReadFile(filename)
+		for _, e := range test.expected {
+			if !strings.Contains(string(data), e) {
+				t.Errorf("%s: expected streamed file to contain %q, got:\n%s", test.format, e, data)
+			}
+		}
+		if err := w.close(); err != nil {
+			t.Errorf("%s: could not close stream: %s", test.format, err)
+		}
+		data, _ = os.ReadFile(filename)
+		if test.format == "html" && !strings.HasSuffix(string(data), htmlStreamFooter) {
+			t.Errorf("html: expected the document to be closed")
+		}
+		if test.format == "jsonl" {
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("jsonl: invalid line %s", line)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// TEMPLATE_FORMAT_PREFIX is the prefix of the -of template:/path/to/file.tmpl output format
+const TEMPLATE_FORMAT_PREFIX = "template:"
+
+// templateFileOutput is the data the user-defined output templates are executed with
+type templateFileOutput struct {
+	CommandLine string
+	Time        string
+	Version     string
+	Keys        []string
+	Config      *ffuf.Config
+	Results     []ffuf.Result
+}
+
+// templateFuncs are the helper functions available in the user-defined output templates
+var templateFuncs = map[string]interface{}{
+	"input": func(r ffuf.Result, keyword string) string {
+		return string(r.Input[keyword])
+	},
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"ms": func(d time.Duration) int64 {
+		return d.Milliseconds()
+	},
+}
+
+// isHTMLTemplate returns true if the template should be rendered with html/template, escaping the values
+func isHTMLTemplate(path string) bool {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".tmpl")
+	return strings.HasSuffix(name, ".html") || strings.HasSuffix(name, ".htm")
+}
+
+// templateExecutor is implemented by both text/template and html/template templates
+type templateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// loadTemplate reads and parses a user-defined output template. Templates named *.html, *.htm or *.html.tmpl
+// are parsed with html/template, escaping the values, and the rest with text/template.
+func loadTemplate(templatePath string) (templateExecutor, error) {
+	tmpl, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(templatePath)
+	if isHTMLTemplate(templatePath) {
+		return htmltemplate.New(name).Funcs(templateFuncs).Parse(string(tmpl))
+	}
+	return texttemplate.New(name).Funcs(templateFuncs).Parse(string(tmpl))
+}
+
+// ValidateTemplate checks that the user-defined output template can be read and parsed
+func ValidateTemplate(templatePath string) error {
+	_, err := loadTemplate(templatePath)
+	return err
+}
+
+// writeTemplate renders the results with a user-defined output template
+func writeTemplate(filename, templatePath string, config *ffuf.Config, res []ffuf.Result) error {
+	t, err := loadTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+	keywords := make([]string, 0)
+	for _, inputprovider := range config.InputProviders {
+		keywords = append(keywords, inputprovider.Keyword)
+	}
+	out := templateFileOutput{
+		CommandLine: config.CommandLine,
+		Time:        time.Now().Format(time.RFC3339),
+		Version:     ffuf.Version(),
+		Keys:        keywords,
+		Config:      config,
+		Results:     res,
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.Execute(f, out)
+}
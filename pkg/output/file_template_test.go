@@ -0,0 +1,42 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{CommandLine: "fuffa -u http://example.com/FUZZ"}
+	res := []ffuf.Result{{Input: map[string][]byte{"FUZZ": []byte("<admin>")}, StatusCode: 200, Url: "http://example.com/<admin>"}}
+	tmpl := `{{ .CommandLine }}{{ range .Results }}|{{ .StatusCode }} {{ input . "FUZZ" }}{{ end }}`
+	tests := map[string]string{
+		"report.txt.tmpl":  "fuffa -u http://example.com/FUZZ|200 <admin>",
+		"report.html.tmpl": "fuffa -u http://example.com/FUZZ|200 &lt;admin&gt;",
+	}
+	for name, expected := range tests {
+		templatePath := filepath.Join(dir, name)
+		if err := os.WriteFile(templatePath, []byte(tmpl), 0644); err != nil {
+			t.Fatalf("Could not write template: %s", err)
+		}
+		if err := ValidateTemplate(templatePath); err != nil {
+			t.Fatalf("%s: could not validate template: %s", name, err)
+		}
+		filename := filepath.Join(dir, name+".out")
+		if err := writeTemplate(filename, templatePath, conf, res); err != nil {
+			t.Fatalf("%s: could not render template: %s", name, err)
+		}
+		data, _ := os.ReadFile(filename)
+		if string(data) != expected {
+			t.Errorf("%s: rendered %q, expected %q", name, data, expected)
+		}
+	}
+	invalid := filepath.Join(dir, "invalid.tmpl")
+	os.WriteFile(invalid, []byte("{{ .Results"), 0644)
+	if err := ValidateTemplate(invalid); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,230 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// The records follow the WARC 1.1 specification, see
+// https://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/
+const warcVersion = "WARC/1.1"
+
+// WARCLogger is an audit logger backend writing the requests and responses as WARC request and response records.
+// The response records refer to their request records with WARC-Concurrent-To, and the rest of the audited data
+// is written as JSON metadata records with its Go type in WARC-Fuffa-Type.
+type WARCLogger struct {
+	file      *rotatingFile
+	lock      sync.Mutex
+	redaction *ffuf.RedactionPolicy
+}
+
+// warcEntry is the rendered request and response record pair of a result
+type warcEntry struct {
+	position int
+	url      string
+	records  []byte
+}
+
+func NewWARCLogger(filename string) (*WARCLogger, error) {
+	return NewWARCLoggerWithOptions(filename, AuditLogOptions{})
+}
+
+// NewWARCLoggerWithOptions returns a WARC audit logger rotating, compressing and redacting the log as configured.
+// Every segment starts with a warcinfo record.
+func NewWARCLoggerWithOptions(filename string, options AuditLogOptions) (*WARCLogger, error) {
+	f, err := newRotatingFile(filename, options, func(w io.Writer) error {
+		return writeWARCInfo(w, filepath.Base(filename))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WARCLogger{file: f, redaction: options.Redaction}, nil
+}
+
+func (logger *WARCLogger) Close() {
+	logger.file.Close()
+}
+
+func (logger *WARCLogger) Write(data interface{}) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	// the record is rendered first and written at once, so that it is not split between rotated segments
+	var buf bytes.Buffer
+	var err error
+	switch d := logger.redaction.Redact(data).(type) {
+	case *ffuf.Request:
+		err = writeWARCRequest(&buf, d)
+	case *ffuf.Response:
+		err = writeWARCResponse(&buf, d)
+	default:
+		err = writeWARCMetadata(&buf, d)
+	}
+	if err == nil {
+		_, err = logger.file.Write(buf.Bytes())
+	}
+	if err != nil {
+		return fmt.Errorf("could not write WARC record to audit log: %s", err)
+	}
+	return nil
+}
+
+// newWARCEntry renders the request and response records of a result
+func newWARCEntry(resp *ffuf.Response) (warcEntry, error) {
+	var buf bytes.Buffer
+	err := writeWARCRequest(&buf, resp.Request)
+	if err == nil {
+		err = writeWARCResponse(&buf, resp)
+	}
+	return warcEntry{position: resp.Request.Position, url: resp.Request.Url, records: buf.Bytes()}, err
+}
+
+// writeWARC writes the records of the given results to a WARC file, preceded by the warcinfo record and the config
+func writeWARC(filename string, config *ffuf.Config, entries []warcEntry, res []ffuf.Result) error {
+	// only include the entries of results that have not been filtered out interactively
+	included := make(map[string]bool, len(res))
+	for _, r := range res {
+		included[resultKey(r.Position, r.Url)] = true
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = writeWARCInfo(f, filepath.Base(filename)); err != nil {
+		return err
+	}
+	if err = writeWARCMetadata(f, redactedConfig(config)); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !included[resultKey(e.position, e.url)] {
+			continue
+		}
+		if _, err = f.Write(e.records); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
+
+func writeWARCInfo(w io.Writer, filename string) error {
+	fields := fmt.Sprintf("software: fuffa/%s\r\nformat: WARC File Format 1.1\r\nconformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n", ffuf.Version())
+	headers := [][2]string{{"WARC-Filename", filename}}
+	return writeWARCRecord(w, "warcinfo", warcRecordID(time.Now().String(), filename), time.Now(), headers, "application/warc-fields", []byte(fields))
+}
+
+func writeWARCMetadata(w io.Writer, data interface{}) error {
+	block, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	headers := [][2]string{{"WARC-Fuffa-Type", fmt.Sprintf("%T", data)}}
+	return writeWARCRecord(w, "metadata", warcRecordID(now.String(), string(block)), now, headers, "application/json", block)
+}
+
+func writeWARCRequest(w io.Writer, req *ffuf.Request) error {
+	requestID, _ := warcExchangeIDs(req)
+	date := req.Timestamp
+	if date.IsZero() {
+		date = time.Now()
+	}
+	headers := [][2]string{{"WARC-Target-URI", req.Url}}
+	return writeWARCRecord(w, "request", requestID, date, headers, "application/http;msgtype=request", []byte(warcRawRequest(req)))
+}
+
+func writeWARCResponse(w io.Writer, resp *ffuf.Response) error {
+	requestID, responseID := warcExchangeIDs(resp.Request)
+	date := resp.Timestamp
+	if date.IsZero() {
+		date = time.Now()
+	}
+	headers := [][2]string{{"WARC-Target-URI", resp.Request.Url}, {"WARC-Concurrent-To", requestID}}
+	if resp.Cancelled {
+		headers = append(headers, [2]string{"WARC-Truncated", "length"})
+	}
+	return writeWARCRecord(w, "response", responseID, date, headers, "application/http;msgtype=response", []byte(warcRawResponse(resp)))
+}
+
+func writeWARCRecord(w io.Writer, recordType, recordID string, date time.Time, headers [][2]string, contentType string, block []byte) error {
+	digest := sha1.Sum(block)
+	var buf bytes.Buffer
+	buf.WriteString(warcVersion + "\r\n")
+	buf.WriteString("WARC-Type: " + recordType + "\r\n")
+	buf.WriteString("WARC-Record-ID: " + recordID + "\r\n")
+	buf.WriteString("WARC-Date: " + date.UTC().Format("2006-01-02T15:04:05Z") + "\r\n")
+	for _, h := range headers {
+		buf.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	buf.WriteString("WARC-Block-Digest: sha1:" + base32.StdEncoding.EncodeToString(digest[:]) + "\r\n")
+	buf.WriteString("Content-Type: " + contentType + "\r\n")
+	buf.WriteString("Content-Length: " + strconv.Itoa(len(block)) + "\r\n\r\n")
+	buf.Write(block)
+	buf.WriteString("\r\n\r\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// warcExchangeIDs derives the record IDs of the request and its response from the request, so the records
+// written separately by the audit logger can refer to each other
+func warcExchangeIDs(req *ffuf.Request) (string, string) {
+	seed := fmt.Sprintf("%d %d %s %s %s", req.Timestamp.UnixNano(), req.Position, req.Method, req.Url, req.Raw)
+	return warcRecordID("request", seed), warcRecordID("response", seed)
+}
+
+// warcRecordID returns a name based UUID URI of the record
+func warcRecordID(parts ...string) string {
+	h := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// warcRawRequest returns the request as sent, or reconstructs it if the raw request was not retained
+func warcRawRequest(req *ffuf.Request) string {
+	if req.Raw != "" {
+		return req.Raw
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s HTTP/1.1\r\n", req.Method, req.Url)
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s: %s\r\n", name, req.Headers[name])
+	}
+	sb.WriteString("\r\n")
+	sb.Write(req.Data)
+	return sb.String()
+}
+
+// warcRawResponse returns the response as received, or reconstructs the status line and headers if the raw
+// response was not retained, as happens when the body was not downloaded
+func warcRawResponse(resp *ffuf.Response) string {
+	if resp.Raw != "" {
+		return resp.Raw
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP/1.1 %d %s\r\n", resp.StatusCode, http.StatusText(int(resp.StatusCode)))
+	http.Header(resp.Headers).Write(&sb)
+	sb.WriteString("\r\n")
+	sb.Write(resp.Data)
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// readWARCRecords parses the headers and blocks of the records in a WARC file
+func readWARCRecords(t *testing.T, filename string) ([]map[string]string, []string) {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("Could not open WARC file: %s", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	headers := make([]map[string]string, 0)
+	blocks := make([]string, 0)
+	for {
+		version, err := r.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if version != "WARC/1.1\r\n" {
+			t.Fatalf("Unexpected record version line: %q", version)
+		}
+		h := make(map[string]string)
+		for {
+			line, _ := r.ReadString('\n')
+			if line == "\r\n" {
+				break
+			}
+			parts := strings.SplitN(strings.TrimSpace(line), ": ", 2)
+			h[parts[0]] = parts[1]
+		}
+		length, _ := strconv.Atoi(h["Content-Length"])
+		block := make([]byte, length+4)
+		if _, err := io.ReadFull(r, block); err != nil {
+			t.Fatalf("Could not read record block: %s", err)
+		}
+		headers = append(headers, h)
+		blocks = append(blocks, string(block[:length]))
+	}
+	return headers, blocks
+}
+
+func testWARCResponse(url string, position int) *ffuf.Response {
+	req := &ffuf.Request{
+		Method:    "GET",
+		Url:       url,
+		Position:  position,
+		Timestamp: time.Now(),
+		Raw:       "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n",
+	}
+	return &ffuf.Response{
+		StatusCode: 200,
+		Request:    req,
+		Timestamp:  req.Timestamp,
+		Raw:        "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok",
+	}
+}
+
+func TestWARCLogger(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.warc")
+	logger, err := NewWARCLogger(filename)
+	if err != nil {
+		t.Fatalf("Could not create WARC logger: %s", err)
+	}
+	resp := testWARCResponse("http://example.com/", 1)
+	for _, data := range []interface{}{&ffuf.Config{Url: "http://example.com/FUZZ"}, resp.Request, resp} {
+		if err := logger.Write(data); err != nil {
+			t.Fatalf("Could not write to WARC logger: %s", err)
+		}
+	}
+	logger.Close()
+
+	headers, blocks := readWARCRecords(t, filename)
+	types := make([]string, 0)
+	for _, h := range headers {
+		types = append(types, h["WARC-Type"])
+	}
+	if strings.Join(types, ",") != "warcinfo,metadata,request,response" {
+		t.Fatalf("Unexpected record types: %v", types)
+	}
+	if headers[1]["WARC-Fuffa-Type"] != "*ffuf.Config" {
+		t.Errorf("Unexpected metadata record type: %s", headers[1]["WARC-Fuffa-Type"])
+	}
+	if headers[3]["WARC-Concurrent-To"] != headers[2]["WARC-Record-ID"] {
+		t.Errorf("Expected the response record to refer to the request record %s, got %s", headers[2]["WARC-Record-ID"], headers[3]["WARC-Concurrent-To"])
+	}
+	if blocks[3] != resp.Raw || headers[3]["Content-Type"] != "application/http;msgtype=response" {
+		t.Errorf("Unexpected response record: %v %q", headers[3], blocks[3])
+	}
+}
+
+func TestWriteWARC(t *testing.T) {
+	entries := make([]warcEntry, 0)
+	for i, url := range []string{"http://example.com/a", "http://example.com/b"} {
+		entry, err := newWARCEntry(testWARCResponse(url, i+1))
+		if err != nil {
+			t.Fatalf("Could not create WARC entry: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+	filename := filepath.Join(t.TempDir(), "out.warc")
+	results := []ffuf.Result{{Url: "http://example.com/b", Position: 2}}
+	if err := writeWARC(filename, &ffuf.Config{}, entries, results); err != nil {
+		t.Fatalf("Could not write WARC file: %s", err)
+	}
+	headers, _ := readWARCRecords(t, filename)
+	if len(headers) != 4 {
+		t.Fatalf("Expected warcinfo, metadata and one request / response pair, got %d records", len(headers))
+	}
+	for _, h := range headers[2:] {
+		if h["WARC-Target-URI"] != "http://example.com/b" {
+			t.Errorf("Unexpected record of a filtered result: %v", h)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// MultiOutput fans out the output to several output providers. The first provider is the primary one: it
+// provides the current results and saves the files requested interactively, as the results of all of the
+// providers are the same.
+type MultiOutput struct {
+	providers []ffuf.OutputProvider
+}
+
+func NewMultiOutput(primary ffuf.OutputProvider, others ...ffuf.OutputProvider) *MultiOutput {
+	return &MultiOutput{providers: append([]ffuf.OutputProvider{primary}, others...)}
+}
+
+func (m *MultiOutput) Banner() {
+	for _, p := range m.providers {
+		p.Banner()
+	}
+}
+
+func (m *MultiOutput) Finalize() error {
+	var errs ffuf.Multierror
+	for _, p := range m.providers {
+		if err := p.Finalize(); err != nil {
+			errs.Add(err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func (m *MultiOutput) Progress(status ffuf.Progress) {
+	for _, p := range m.providers {
+		p.Progress(status)
+	}
+}
+
+func (m *MultiOutput) Info(infostring string) {
+	for _, p := range m.providers {
+		p.Info(infostring)
+	}
+}
+
+func (m *MultiOutput) Error(errstring string) {
+	for _, p := range m.providers {
+		p.Error(errstring)
+	}
+}
+
+func (m *MultiOutput) Raw(output string) {
+	for _, p := range m.providers {
+		p.Raw(output)
+	}
+}
+
+func (m *MultiOutput) Warning(warnstring string) {
+	for _, p := range m.providers {
+		p.Warning(warnstring)
+	}
+}
+
+func (m *MultiOutput) Result(resp ffuf.Response) {
+	for _, p := range m.providers {
+		p.Result(resp)
+	}
+}
+
+func (m *MultiOutput) PrintResult(res ffuf.Result) {
+	for _, p := range m.providers {
+		p.PrintResult(res)
+	}
+}
+
+func (m *MultiOutput) Event(event ffuf.JobEvent) {
+	for _, p := range m.providers {
+		p.Event(event)
+	}
+}
+
+// SaveFile saves the current results of the primary provider to a file of a given type
+func (m *MultiOutput) SaveFile(filename, format string) error {
+	return m.providers[0].SaveFile(filename, format)
+}
+
+// GetCurrentResults returns the current results of the primary provider
+func (m *MultiOutput) GetCurrentResults() []ffuf.Result {
+	return m.providers[0].GetCurrentResults()
+}
+
+func (m *MultiOutput) SetCurrentResults(results []ffuf.Result) {
+	for _, p := range m.providers {
+		p.SetCurrentResults(results)
+	}
+}
+
+func (m *MultiOutput) Reset() {
+	for _, p := range m.providers {
+		p.Reset()
+	}
+}
+
+func (m *MultiOutput) Cycle() {
+	for _, p := range m.providers {
+		p.Cycle()
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestMultiOutput(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{Quiet: true, InputProviders: []ffuf.InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}}
+	jsonlFile := filepath.Join(dir, "out.jsonl")
+	csvFile := filepath.Join(dir, "out.csv")
+	primary := NewFileOutput(conf, "json", filepath.Join(dir, "out.json"), false)
+	m := NewMultiOutput(primary, NewFileOutput(conf, "jsonl", jsonlFile, true), NewFileOutput(conf, "csv", csvFile, false))
+
+	for _, word := range []string{"admin", "login"} {
+		req := &ffuf.Request{Url: "http://example.com/" + word, Input: map[string][]byte{"FUZZ": []byte(word)}}
+		m.Result(ffuf.Response{StatusCode: 200, Request: req})
+	}
+	if len(m.GetCurrentResults()) != 2 {
+		t.Fatalf("Expected 2 current results, got %d", len(m.GetCurrentResults()))
+	}
+	m.Warning("Getting an unusual amount of 429 responses")
+	m.Event(ffuf.JobEvent{Type: ffuf.EVENT_JOB_FINISHED, QueuePos: 1, QueueTotal: 1, Url: "http://example.com/FUZZ"})
+	// filtering the results interactively applies to every sink
+	m.SetCurrentResults(m.GetCurrentResults()[:1])
+	if err := m.Finalize(); err != nil {
+		t.Fatalf("Could not finalize the outputs: %s", err)
+	}
+	for _, filename := range []string{jsonlFile, csvFile, filepath.Join(dir, "out.json")} {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Could not read sink output %s: %s", filename, err)
+		}
+		if !strings.Contains(string(data), "http://example.com/admin") || strings.Contains(string(data), "http://example.com/login") {
+			t.Errorf("Unexpected sink output in %s: %s", filename, data)
+		}
+	}
+	// only the verbose sink logs the messages
+	data, err := os.ReadFile(SinkMessagesFile(jsonlFile))
+	if err != nil || !strings.Contains(string(data), "[WARN] Getting an unusual amount of 429 responses") || !strings.Contains(string(data), "[EVENT] Job 1/1 finished") {
+		t.Errorf("Unexpected messages of the verbose sink: %s, error: %v", data, err)
+	}
+	if _, err := os.Stat(SinkMessagesFile(csvFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no messages file for the sink without verbosity")
+	}
+}
+
+func TestFileOutputSharesConfig(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{Quiet: true, Url: "http://example.com/FUZZ"}
+	sink := NewFileOutput(conf, "json", filepath.Join(dir, "out.json"), false)
+	// the matchers are set up and the URL changes for every queued job after the outputs are created
+	conf.Url = "http://example.com/admin/FUZZ"
+	if sink.config != conf || sink.config.Url != "http://example.com/admin/FUZZ" {
+		t.Errorf("Expected the sink to use the shared config")
+	}
+	if conf.OutputFile != "" || sink.outputFile != filepath.Join(dir, "out.json") || sink.outputFormat != "json" {
+		t.Errorf("Expected the file of the sink to be kept on the sink, got %s %s", sink.outputFile, sink.outputFormat)
+	}
+}
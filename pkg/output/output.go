@@ -5,6 +5,19 @@ import (
 )
 
 func NewOutputProviderByName(name string, conf *ffuf.Config) ffuf.OutputProvider {
-	//We have only one outputprovider at the moment
-	return NewStdoutput(conf)
+	//We have only one terminal outputprovider at the moment
+	stdout := NewStdoutput(conf)
+	if len(conf.OutputSinks) == 0 && conf.Webhook == "" {
+		return stdout
+	}
+	sinks := make([]ffuf.OutputProvider, 0, len(conf.OutputSinks)+1)
+	for _, sink := range conf.OutputSinks {
+		// the sinks have been validated when parsing the config
+		s, _ := ffuf.ParseOutputSink(sink)
+		sinks = append(sinks, NewFileOutput(conf, s.Format, s.Path, s.Verbose))
+	}
+	if conf.Webhook != "" {
+		sinks = append(sinks, NewWebhookOutput(conf))
+	}
+	return NewMultiOutput(stdout, sinks...)
 }
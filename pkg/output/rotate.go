@@ -0,0 +1,179 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// AuditLogOptions configures the rotation, compression and redaction of the audit log
+type AuditLogOptions struct {
+	// MaxSize is the size in bytes after which the log is rotated, or 0 for no size based rotation
+	MaxSize int64
+	// MaxAge is the age after which the log is rotated, or 0 for no time based rotation
+	MaxAge time.Duration
+	// Compress gzips the rotated segments
+	Compress bool
+	// Redaction masks the secrets of the audited data before it is written, if set
+	Redaction *ffuf.RedactionPolicy
+}
+
+// AuditLogOptionsFromConfig returns the audit log options of the config
+func AuditLogOptionsFromConfig(conf *ffuf.Config) AuditLogOptions {
+	return AuditLogOptions{
+		MaxSize:   int64(conf.AuditLogMaxSize) * 1024 * 1024,
+		MaxAge:    conf.AuditLogMaxAge,
+		Compress:  conf.AuditLogCompress,
+		Redaction: conf.Redaction,
+	}
+}
+
+// rotatingFile is an append-only log file that is rotated when it would grow over the maximum size or gets older
+// than the maximum age. The rotated segments are renamed with a timestamp suffix and gzipped in the background.
+// A record passed to a single Write is never split between segments.
+type rotatingFile struct {
+	filename string
+	options  AuditLogOptions
+	// header writes the beginning of every segment, like the warcinfo record of WARC files
+	header      func(w io.Writer) error
+	file        *os.File
+	size        int64
+	opened      time.Time
+	compressing sync.WaitGroup
+	errLock     sync.Mutex
+	err         error
+}
+
+func newRotatingFile(filename string, options AuditLogOptions, header func(w io.Writer) error) (*rotatingFile, error) {
+	r := &rotatingFile{filename: filename, options: options, header: header}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file for appending, writing the header to it
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// the header is not counted in the size, so that it alone never triggers a rotation
+	r.file, r.size, r.opened = f, stat.Size(), time.Now()
+	if r.header != nil {
+		if err = r.header(f); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// Write appends the record to the log, rotating it first if needed. Errors of compressing the earlier segments are
+// returned by the next Write.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.needsRotation(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, fmt.Errorf("could not rotate the audit log: %s", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	r.errLock.Lock()
+	defer r.errLock.Unlock()
+	err, r.err = r.err, nil
+	return n, err
+}
+
+func (r *rotatingFile) needsRotation(next int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.options.MaxSize > 0 && r.size+next > r.options.MaxSize {
+		return true
+	}
+	return r.options.MaxAge > 0 && time.Since(r.opened) >= r.options.MaxAge
+}
+
+// rotate renames the current segment, compresses it in the background and opens a new one
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	segment := r.segmentName()
+	if err := os.Rename(r.filename, segment); err != nil {
+		return err
+	}
+	if r.options.Compress {
+		r.compressing.Add(1)
+		go func() {
+			defer r.compressing.Done()
+			if err := gzipFile(segment); err != nil {
+				r.errLock.Lock()
+				r.err = fmt.Errorf("could not compress the audit log segment %s: %s", segment, err)
+				r.errLock.Unlock()
+			}
+		}()
+	}
+	return r.open()
+}
+
+// segmentName returns an unused name for a rotated segment, suffixed with the current time
+func (r *rotatingFile) segmentName() string {
+	base := r.filename + "." + time.Now().Format("20060102-150405")
+	name := base
+	for i := 1; ; i++ {
+		_, err := os.Stat(name)
+		_, gzErr := os.Stat(name + ".gz")
+		if os.IsNotExist(err) && os.IsNotExist(gzErr) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// Close closes the log file and waits for the segments to be compressed
+func (r *rotatingFile) Close() error {
+	err := r.file.Close()
+	r.compressing.Wait()
+	return err
+}
+
+// gzipFile compresses the file to file.gz and removes the original
+func gzipFile(filename string) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(filename+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename + ".gz")
+		return err
+	}
+	in.Close()
+	return os.Remove(filename)
+}
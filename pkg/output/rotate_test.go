@@ -0,0 +1,80 @@
+package output
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestAuditLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "audit.jsonl")
+	redaction, _ := ffuf.NewRedactionPolicy(true, nil, nil, nil)
+	logger, err := NewAuditLoggerWithOptions(filename, AuditLogOptions{MaxSize: 300, Compress: true, Redaction: redaction})
+	if err != nil {
+		t.Fatalf("Could not create the audit logger: %s", err)
+	}
+	for i := 0; i < 5; i++ {
+		req := &ffuf.Request{Url: "http://example.com/", Headers: map[string]string{"Authorization": "Bearer secret"}}
+		if err := logger.Write(req); err != nil {
+			t.Fatalf("Could not write to the audit log: %s", err)
+		}
+	}
+	logger.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "audit.jsonl*"))
+	segments, _ := filepath.Glob(filepath.Join(dir, "audit.jsonl.*.gz"))
+	if len(segments) == 0 || len(segments) != len(files)-1 {
+		t.Fatalf("Expected the rotated segments to be compressed, got %v", files)
+	}
+	records := 0
+	for _, file := range files {
+		f, _ := os.Open(file)
+		var r io.Reader = f
+		if strings.HasSuffix(file, ".gz") {
+			if r, err = gzip.NewReader(f); err != nil {
+				t.Fatalf("Could not read the compressed segment: %s", err)
+			}
+		}
+		content, _ := io.ReadAll(r)
+		f.Close()
+		if strings.Contains(string(content), "secret") {
+			t.Errorf("Expected the Authorization header to be redacted in %s", file)
+		}
+		records += strings.Count(string(content), "\n")
+	}
+	if records != 5 {
+		t.Errorf("Expected 5 records in the segments, got %d", records)
+	}
+}
+
+func TestWARCLogRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.warc")
+	logger, err := NewWARCLoggerWithOptions(filename, AuditLogOptions{MaxSize: 100})
+	if err != nil {
+		t.Fatalf("Could not create the WARC logger: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		req := ffuf.NewRequest(&ffuf.Config{Method: "GET"})
+		req.Url = "http://example.com/"
+		if err := logger.Write(&req); err != nil {
+			t.Fatalf("Could not write to the WARC log: %s", err)
+		}
+	}
+	logger.Close()
+	files, _ := filepath.Glob(filename + "*")
+	if len(files) != 2 {
+		t.Fatalf("Expected a rotated segment, got %v", files)
+	}
+	for _, file := range files {
+		content, _ := os.ReadFile(file)
+		if !strings.Contains(string(content), "WARC-Type: warcinfo") || !strings.Contains(string(content), "WARC-Type: request") {
+			t.Errorf("Expected every segment to start with warcinfo and contain a request: %s", file)
+		}
+	}
+}
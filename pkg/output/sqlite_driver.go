@@ -0,0 +1,9 @@
+//go:build cgo
+// +build cgo
+
+package output
+
+import (
+	// the SQLite driver needs cgo, the sqlite output format is rejected when parsing the options without it
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -1,14 +1,13 @@
 package output
 
 import (
-	"crypto/md5"
 	"encoding/json"
 	"fmt"
 	"os"
-	"path"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Mascol9/fuffa/pkg/ffuf"
@@ -30,14 +29,48 @@ type Stdoutput struct {
 	fuzzkeywords   []string
 	Results        []ffuf.Result
 	CurrentResults []ffuf.Result
+	sqlite         *sqliteWriter
+	sqliteNewJob   bool
+	sqliteMutex    sync.Mutex
+	stream         *streamWriter
+	streamMutex    sync.Mutex
+	harEntries     []harEntry
+	warcEntries    []warcEntry
+	archiveMutex   sync.Mutex
+	diff           *DiffReport
+	resultFormat   *ffuf.ResultFormat
+	// outputFile, outputFormat and verbose are the file and verbosity of this output, the config being shared by
+	// the main output and the sinks
+	outputFile   string
+	outputFormat string
+	verbose      bool
+	// sink is true for the additional file outputs, which write the results but print nothing. The verbose sinks
+	// log the messages and the job events to the messages file next to the output file.
+	sink          bool
+	messages      *os.File
+	messagesMutex sync.Mutex
+	// evidence is the store of the requests and responses of the output directory, opened on the first result
+	evidence      *EvidenceStore
+	evidenceMutex sync.Mutex
+	// reportJobs maps the results to the URL of the queued job that produced them, for the report format
+	reportJobs map[string]string
 }
 
 func NewStdoutput(conf *ffuf.Config) *Stdoutput {
 	var outp Stdoutput
 	outp.config = conf
+	outp.outputFile = conf.OutputFile
+	outp.outputFormat = conf.OutputFormat
+	outp.verbose = conf.Verbose
 	outp.Results = make([]ffuf.Result, 0)
 	outp.CurrentResults = make([]ffuf.Result, 0)
 	outp.fuzzkeywords = make([]string, 0)
+	outp.sqliteNewJob = true
+	outp.reportJobs = make(map[string]string)
+	if conf.ResultFormat != "" {
+		// the format has been validated when parsing the config
+		outp.resultFormat, _ = ffuf.ParseResultFormat(conf.ResultFormat)
+	}
 	for _, ip := range conf.InputProviders {
 		outp.fuzzkeywords = append(outp.fuzzkeywords, ip.Keyword)
 	}
@@ -45,7 +78,58 @@ func NewStdoutput(conf *ffuf.Config) *Stdoutput {
 	return &outp
 }
 
+// NewFileOutput returns an output sink writing the results to a file in the given format, in addition to the
+// main output. The sink does not print anything to the terminal, a verbose sink logs the messages to the
+// messages file instead. The evidence store of -od is written by the main output only.
+func NewFileOutput(conf *ffuf.Config, format, filename string, verbose bool) *Stdoutput {
+	outp := NewStdoutput(conf)
+	outp.outputFile = filename
+	outp.outputFormat = format
+	outp.verbose = verbose
+	outp.sink = true
+	return outp
+}
+
+// SinkMessagesFile returns the name of the messages file of a verbose output sink
+func SinkMessagesFile(filename string) string {
+	return filename + ".log"
+}
+
+// logMessage appends a message to the messages file of a verbose sink, opening the file on the first message
+func (s *Stdoutput) logMessage(level, message string) {
+	if !s.verbose {
+		return
+	}
+	s.messagesMutex.Lock()
+	defer s.messagesMutex.Unlock()
+	if s.messages == nil {
+		f, err := os.OpenFile(SinkMessagesFile(s.outputFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			s.verbose = false
+			s.fileError(fmt.Sprintf("Could not open the messages file: %s", err))
+			return
+		}
+		s.messages = f
+	}
+	fmt.Fprintf(s.messages, "%s [%s] %s\n", time.Now().Format(time.RFC3339), level, strings.TrimSpace(message))
+}
+
+// closeMessages closes the messages file of a verbose sink
+func (s *Stdoutput) closeMessages() error {
+	s.messagesMutex.Lock()
+	defer s.messagesMutex.Unlock()
+	if s.messages == nil {
+		return nil
+	}
+	err := s.messages.Close()
+	s.messages = nil
+	return err
+}
+
 func (s *Stdoutput) Banner() {
+	if s.sink {
+		return
+	}
 	version := strings.ReplaceAll(ffuf.Version(), "<3", fmt.Sprintf("%s<3%s", ANSI_RED, ANSI_CLEAR))
 	fmt.Fprintf(os.Stderr, "%s v%s\n%s\n\n", BANNER_HEADER, version, BANNER_SEP)
 	printOption([]byte("Method"), []byte(s.config.Method))
@@ -79,18 +163,52 @@ func (s *Stdoutput) Banner() {
 	}
 
 	// Output file info
-	if len(s.config.OutputFile) > 0 {
+	if len(s.outputFile) > 0 {
 
 		// Use filename as specified by user
-		OutputFile := s.config.OutputFile
+		OutputFile := s.outputFile
 
-		if s.config.OutputFormat == "all" {
+		if s.outputFormat == "all" {
 			// Actually... append all extensions
-			OutputFile += ".{json,ejson,html,md,csv,ecsv}"
+			if ffuf.SQLiteSupported {
+				OutputFile += ".{json,ejson,html,md,csv,ecsv,jsonl,sqlite}"
+			} else {
+				OutputFile += ".{json,ejson,html,md,csv,ecsv,jsonl}"
+			}
 		}
 
 		printOption([]byte("Output file"), []byte(OutputFile))
-		printOption([]byte("File format"), []byte(s.config.OutputFormat))
+		printOption([]byte("File format"), []byte(s.outputFormat))
+	}
+	for _, sink := range s.config.OutputSinks {
+		printOption([]byte("Output sink"), []byte(sink))
+	}
+	if s.config.Webhook != "" {
+		printOption([]byte("Webhook"), []byte(fmt.Sprintf("%s (severity: %s)", s.config.Webhook, s.config.WebhookSeverity)))
+	}
+	if s.config.SessionFile != "" {
+		printOption([]byte("Session"), []byte(s.config.SessionFile))
+	}
+	if s.config.PreflightFile != "" {
+		printOption([]byte("Pre-flight"), []byte(fmt.Sprintf("%s (%s)", s.config.PreflightFile, s.config.PreflightExtract)))
+	}
+	if s.config.SignAWS != "" {
+		printOption([]byte("Signing"), []byte("AWS SigV4 "+s.config.SignAWS))
+	}
+	if s.config.SignHMAC != "" {
+		printOption([]byte("Signing"), []byte(fmt.Sprintf("HMAC-%s %s", strings.ToUpper(s.config.SignHMAC), s.config.SignHMACHeader)))
+	}
+	if s.config.CorrelationHeader != "" {
+		printOption([]byte("Correlation"), []byte("header "+s.config.CorrelationHeader))
+	}
+	if s.config.CorrelationParam != "" {
+		printOption([]byte("Correlation"), []byte("query parameter "+s.config.CorrelationParam))
+	}
+	if s.config.OOBHTTP != "" {
+		printOption([]byte("OOB HTTP"), []byte(s.config.OOBHTTP))
+	}
+	if s.config.OOBDNS != "" {
+		printOption([]byte("OOB DNS"), []byte(fmt.Sprintf("%s (%s)", s.config.OOBDNS, s.config.OOBDomain)))
 	}
 
 	// Follow redirects?
@@ -148,6 +266,9 @@ func (s *Stdoutput) Reset() {
 func (s *Stdoutput) Cycle() {
 	s.Results = append(s.Results, s.CurrentResults...)
 	s.Reset()
+	s.sqliteMutex.Lock()
+	s.sqliteNewJob = true
+	s.sqliteMutex.Unlock()
 }
 
 // GetResults returns the result slice
@@ -161,7 +282,7 @@ func (s *Stdoutput) SetCurrentResults(results []ffuf.Result) {
 }
 
 func (s *Stdoutput) Progress(status ffuf.Progress) {
-	if s.config.Quiet {
+	if s.config.Quiet || s.sink {
 		// No progress for quiet mode
 		return
 	}
@@ -185,6 +306,10 @@ func (s *Stdoutput) Progress(status ffuf.Progress) {
 }
 
 func (s *Stdoutput) Info(infostring string) {
+	if s.sink {
+		s.logMessage("INFO", infostring)
+		return
+	}
 	if s.config.Quiet {
 		fmt.Fprintf(os.Stderr, "%s", infostring)
 	} else {
@@ -197,6 +322,23 @@ func (s *Stdoutput) Info(infostring string) {
 }
 
 func (s *Stdoutput) Error(errstring string) {
+	if s.sink {
+		s.logMessage("ERR", errstring)
+		return
+	}
+	s.printError(errstring)
+}
+
+// fileError reports an error of writing the output file. Unlike the other messages, it is printed by the
+// output sinks too.
+func (s *Stdoutput) fileError(errstring string) {
+	if s.sink {
+		errstring = fmt.Sprintf("Output sink %s: %s", s.outputFile, errstring)
+	}
+	s.printError(errstring)
+}
+
+func (s *Stdoutput) printError(errstring string) {
 	if s.config.Quiet {
 		fmt.Fprintf(os.Stderr, "%s", errstring)
 	} else {
@@ -209,6 +351,10 @@ func (s *Stdoutput) Error(errstring string) {
 }
 
 func (s *Stdoutput) Warning(warnstring string) {
+	if s.sink {
+		s.logMessage("WARN", warnstring)
+		return
+	}
 	if s.config.Quiet {
 		fmt.Fprintf(os.Stderr, "%s", warnstring)
 	} else {
@@ -221,54 +367,37 @@ func (s *Stdoutput) Warning(warnstring string) {
 }
 
 func (s *Stdoutput) Raw(output string) {
+	if s.sink {
+		return
+	}
 	fmt.Fprintf(os.Stderr, "%s%s", TERMINAL_CLEAR_LINE, output)
 }
 
 func (s *Stdoutput) writeToAll(filename string, config *ffuf.Config, res []ffuf.Result) error {
-	var err error
-	var BaseFilename string = s.config.OutputFile
-
 	// Go through each type of write, adding
 	// the suffix to each output file.
-
-	s.config.OutputFile = BaseFilename + ".json"
-	err = writeJSON(s.config.OutputFile, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".ejson"
-	err = writeEJSON(s.config.OutputFile, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".html"
-	err = writeHTML(s.config.OutputFile, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".md"
-	err = writeMarkdown(s.config.OutputFile, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".csv"
-	err = writeCSV(s.config.OutputFile, s.config, res, false)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".ecsv"
-	err = writeCSV(s.config.OutputFile, s.config, res, true)
-	if err != nil {
-		s.Error(err.Error())
+	writers := []struct {
+		suffix string
+		write  func(string) error
+	}{
+		{".json", func(f string) error { return writeJSON(f, config, res, s.diff) }},
+		{".ejson", func(f string) error { return writeEJSON(f, config, res) }},
+		{".html", func(f string) error { return writeHTML(f, config, res, s.diff) }},
+		{".md", func(f string) error { return writeMarkdown(f, config, res, s.diff) }},
+		{".csv", func(f string) error { return writeCSV(f, config, res, false) }},
+		{".ecsv", func(f string) error { return writeCSV(f, config, res, true) }},
+		{".jsonl", func(f string) error { return writeJSONL(f, config, res) }},
+		{".sqlite", func(f string) error { return writeSQLite(f, config, res) }},
+	}
+	for _, w := range writers {
+		if w.suffix == ".sqlite" && !ffuf.SQLiteSupported {
+			continue
+		}
+		if err := w.write(filename + w.suffix); err != nil {
+			s.fileError(err.Error())
+		}
 	}
-
 	return nil
-
 }
 
 // SaveFile saves the current results to a file of a given type
@@ -282,17 +411,35 @@ func (s *Stdoutput) SaveFile(filename, format string) error {
 	case "all":
 		err = s.writeToAll(filename, s.config, append(s.Results, s.CurrentResults...))
 	case "json":
-		err = writeJSON(filename, s.config, append(s.Results, s.CurrentResults...))
+		err = writeJSON(filename, s.config, append(s.Results, s.CurrentResults...), s.diff)
 	case "ejson":
 		err = writeEJSON(filename, s.config, append(s.Results, s.CurrentResults...))
 	case "html":
-		err = writeHTML(filename, s.config, append(s.Results, s.CurrentResults...))
+		err = writeHTML(filename, s.config, append(s.Results, s.CurrentResults...), s.diff)
 	case "md":
-		err = writeMarkdown(filename, s.config, append(s.Results, s.CurrentResults...))
+		err = writeMarkdown(filename, s.config, append(s.Results, s.CurrentResults...), s.diff)
 	case "csv":
 		err = writeCSV(filename, s.config, append(s.Results, s.CurrentResults...), false)
 	case "ecsv":
 		err = writeCSV(filename, s.config, append(s.Results, s.CurrentResults...), true)
+	case "sqlite":
+		err = writeSQLite(filename, s.config, append(s.Results, s.CurrentResults...))
+	case "jsonl":
+		err = writeJSONL(filename, s.config, append(s.Results, s.CurrentResults...))
+	case "har":
+		s.archiveMutex.Lock()
+		err = writeHAR(filename, s.harEntries, append(s.Results, s.CurrentResults...))
+		s.archiveMutex.Unlock()
+	case "warc":
+		s.archiveMutex.Lock()
+		err = writeWARC(filename, s.config, s.warcEntries, append(s.Results, s.CurrentResults...))
+		s.archiveMutex.Unlock()
+	case "report":
+		err = writeReport(filename, s.config, append(s.Results, s.CurrentResults...), s.reportJobs)
+	default:
+		if strings.HasPrefix(format, TEMPLATE_FORMAT_PREFIX) {
+			err = writeTemplate(filename, strings.TrimPrefix(format, TEMPLATE_FORMAT_PREFIX), s.config, append(s.Results, s.CurrentResults...))
+		}
 	}
 	return err
 }
@@ -300,13 +447,33 @@ func (s *Stdoutput) SaveFile(filename, format string) error {
 // Finalize gets run after all the fuffa jobs are completed
 func (s *Stdoutput) Finalize() error {
 	var err error
-	if s.config.OutputFile != "" {
-		err = s.SaveFile(s.config.OutputFile, s.config.OutputFormat)
+	if s.config.Baseline != "" {
+		s.printBaselineDiff()
+	}
+	if err = s.closeEvidence(); err != nil {
+		s.Error(err.Error())
+	}
+	if s.outputFile != "" && s.outputFormat == "sqlite" {
+		// the results were written as they arrived
+		err = s.closeSQLite()
 		if err != nil {
-			s.Error(err.Error())
+			s.fileError(err.Error())
+		}
+	} else if s.outputFile != "" {
+		// the streamed file is replaced with the complete document
+		err = s.closeStream()
+		if err != nil {
+			s.fileError(err.Error())
+		}
+		err = s.SaveFile(s.outputFile, s.outputFormat)
+		if err != nil {
+			s.fileError(err.Error())
 		}
 	}
-	if !s.config.Quiet {
+	if err = s.closeMessages(); err != nil {
+		s.fileError(err.Error())
+	}
+	if !s.config.Quiet && !s.sink {
 		fmt.Fprintf(os.Stderr, "\n")
 	}
 	return nil
@@ -315,7 +482,11 @@ func (s *Stdoutput) Finalize() error {
 func (s *Stdoutput) Result(resp ffuf.Response) {
 	// Do we want to write request and response to a file
 	if len(s.config.OutputDirectory) > 0 {
-		resp.ResultFile = s.writeResultToFile(resp)
+		if s.sink {
+			resp.ResultFile = evidenceID(&resp)
+		} else {
+			resp.ResultFile = s.writeResultToFile(resp)
+		}
 	}
 
 	inputs := make(map[string][]byte, len(resp.Request.Input))
@@ -332,6 +503,7 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
 		ContentType:      resp.ContentType,
 		RedirectLocation: resp.GetRedirectLocation(false),
 		ScraperData:      resp.ScraperData,
+		Seed:             resp.Seed,
 		Url:              resp.Request.Url,
 		Duration:         resp.Duration,
 		ResultFile:       resp.ResultFile,
@@ -340,42 +512,178 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
 		VhostDomain:      s.config.VhostDomain,
 	}
 	s.CurrentResults = append(s.CurrentResults, sResult)
+	if s.outputFile != "" && s.outputFormat == "sqlite" {
+		s.writeSQLiteResult(sResult, s.config.Redaction.Redact(&resp).(*ffuf.Response))
+	}
+	if s.outputFile != "" && isStreamFormat(s.outputFormat) {
+		s.writeStreamResult(sResult)
+	}
+	if s.outputFile != "" && s.outputFormat == "har" {
+		entry := newHAREntry(s.config.Redaction.Redact(&resp).(*ffuf.Response))
+		s.archiveMutex.Lock()
+		s.harEntries = append(s.harEntries, entry)
+		s.archiveMutex.Unlock()
+	}
+	if s.outputFile != "" && s.outputFormat == "warc" {
+		entry, err := newWARCEntry(s.config.Redaction.Redact(&resp).(*ffuf.Response))
+		if err != nil {
+			s.fileError(fmt.Sprintf("Could not create the WARC records: %s", err))
+		} else {
+			s.archiveMutex.Lock()
+			s.warcEntries = append(s.warcEntries, entry)
+			s.archiveMutex.Unlock()
+		}
+	}
 	// Output the result
 	s.PrintResult(sResult)
 }
 
-func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
-	var fileContent, fileName, filePath string
-	// Create directory if needed
-	if s.config.OutputDirectory != "" {
-		err := os.MkdirAll(s.config.OutputDirectory, 0750)
+// Event records the queued job the current results belong to for the report format. The job prints the reasons
+// of aborting it as warnings.
+func (s *Stdoutput) Event(event ffuf.JobEvent) {
+	if s.sink {
+		s.logMessage("EVENT", eventMessage(event))
+	}
+	for _, r := range s.CurrentResults {
+		key := resultKey(r.Position, r.Url)
+		if _, ok := s.reportJobs[key]; !ok {
+			s.reportJobs[key] = event.Url
+		}
+	}
+}
+
+// printBaselineDiff compares the results to the baseline output file and prints the differences
+func (s *Stdoutput) printBaselineDiff() {
+	baseline, err := LoadResultsFile(s.config.Baseline)
+	if err != nil {
+		s.Error(fmt.Sprintf("Could not read the baseline results: %s", err))
+		return
+	}
+	s.diff = DiffResults(s.config.Baseline, baseline, append(s.Results, s.CurrentResults...))
+	if s.sink {
+		// the diff is only written to the output file of the sink
+		return
+	}
+	if !s.config.Quiet {
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+	fmt.Print(s.diff.String())
+}
+
+// writeSQLiteResult writes the result to the SQLite output database, opening it and adding a job when needed
+func (s *Stdoutput) writeSQLiteResult(res ffuf.Result, resp *ffuf.Response) {
+	s.sqliteMutex.Lock()
+	defer s.sqliteMutex.Unlock()
+	var err error
+	if s.sqlite == nil {
+		s.sqlite, err = openSQLite(s.outputFile, s.config)
 		if err != nil {
-			if !os.IsExist(err) {
-				s.Error(err.Error())
-				return ""
-			}
+			s.fileError(fmt.Sprintf("Could not open the SQLite output database: %s", err))
+			return
+		}
+	}
+	if s.sqliteNewJob {
+		if err = s.sqlite.startJob(s.config.Url); err != nil {
+			s.fileError(fmt.Sprintf("Could not write job to the SQLite output database: %s", err))
+			return
+		}
+		s.sqliteNewJob = false
+	}
+	if err = s.sqlite.writeResult(res, resp); err != nil {
+		s.fileError(fmt.Sprintf("Could not write result to the SQLite output database: %s", err))
+	}
+}
+
+// writeStreamResult appends the result to the streamed output file, creating the file on the first result
+func (s *Stdoutput) writeStreamResult(res ffuf.Result) {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	var err error
+	if s.stream == nil {
+		s.stream, err = openStream(s.outputFile, s.outputFormat, s.config)
+		if err != nil {
+			s.fileError(fmt.Sprintf("Could not open the output file for streaming: %s", err))
+			return
 		}
 	}
-	fileContent = fmt.Sprintf("%s\n---- ↑ Request ---- Response ↓ ----\n\n%s", resp.Request.Raw, resp.Raw)
+	if err = s.stream.write(res); err != nil {
+		s.fileError(fmt.Sprintf("Could not write result to the output file: %s", err))
+	}
+}
+
+// closeStream closes the streamed output file
+func (s *Stdoutput) closeStream() error {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	if s.stream == nil {
+		return nil
+	}
+	err := s.stream.close()
+	s.stream = nil
+	return err
+}
 
-	// Create file name
-	fileName = fmt.Sprintf("%x", md5.Sum([]byte(fileContent)))
+// closeSQLite closes the SQLite output database, creating it first unless there were no results and -or was defined
+func (s *Stdoutput) closeSQLite() error {
+	s.sqliteMutex.Lock()
+	defer s.sqliteMutex.Unlock()
+	if s.sqlite == nil {
+		if s.config.OutputSkipEmptyFile {
+			s.Info("No results and -or defined, output file not written.")
+			return nil
+		}
+		return writeSQLite(s.outputFile, s.config, []ffuf.Result{})
+	}
+	err := s.sqlite.close()
+	s.sqlite = nil
+	return err
+}
 
-	filePath = path.Join(s.config.OutputDirectory, fileName)
-	err := os.WriteFile(filePath, []byte(fileContent), 0640)
+// writeResultToFile stores the request and response of the result in the evidence store of the output
+// directory, and returns the id of its entry in the manifest
+func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
+	s.evidenceMutex.Lock()
+	defer s.evidenceMutex.Unlock()
+	if s.evidence == nil {
+		evidence, err := NewEvidenceStore(s.config.OutputDirectory)
+		if err != nil {
+			s.Error(err.Error())
+			return ""
+		}
+		s.evidence = evidence
+	}
+	// the store is added to under the lock, as the late results of the OOB wait window can race closeEvidence
+	entry, err := s.evidence.Add(&resp)
 	if err != nil {
 		s.Error(err.Error())
+		return ""
 	}
-	return fileName
+	return entry.Id
+}
+
+// closeEvidence closes the manifest of the output directory
+func (s *Stdoutput) closeEvidence() error {
+	s.evidenceMutex.Lock()
+	defer s.evidenceMutex.Unlock()
+	if s.evidence == nil {
+		return nil
+	}
+	err := s.evidence.Close()
+	s.evidence = nil
+	return err
 }
 
 func (s *Stdoutput) PrintResult(res ffuf.Result) {
 	switch {
+	case s.sink:
+		return
+	case s.resultFormat != nil:
+		s.resultFormatted(res)
 	case s.config.Json:
 		s.resultJson(res)
 	case s.config.Quiet:
 		s.resultQuiet(res)
-	case len(s.fuzzkeywords) > 1 || s.config.Verbose || len(s.config.OutputDirectory) > 0 || len(res.ScraperData) > 0:
+	case len(s.fuzzkeywords) > 1 || s.verbose || len(s.config.OutputDirectory) > 0 || len(res.ScraperData) > 0:
 		// Print a multi-line result (when using multiple input keywords and wordlists)
 		s.resultMultiline(res)
 	default:
@@ -411,13 +719,18 @@ func (s *Stdoutput) resultQuiet(res ffuf.Result) {
 	fmt.Println(res.Url)
 }
 
+func (s *Stdoutput) resultFormatted(res ffuf.Result) {
+	fmt.Fprint(os.Stderr, TERMINAL_CLEAR_LINE)
+	fmt.Println(s.resultFormat.Render(res))
+}
+
 func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 	var res_hdr, res_str string
 	res_str = "%s%s    * %s: %s\n"
 	statusColor := s.colorizeStatusCode(res.StatusCode)
 	res_hdr = fmt.Sprintf("%s %s%s%s  [%sStatus%s: %s%d%s, Size: %d]", TERMINAL_CLEAR_LINE, statusColor, BULLET_CHAR, ANSI_CLEAR, statusColor, ANSI_CLEAR, statusColor, res.StatusCode, ANSI_CLEAR, res.ContentLength)
 	reslines := ""
-	if s.config.Verbose {
+	if s.verbose {
 		reslines = fmt.Sprintf("%s%s| URL | %s\n", reslines, TERMINAL_CLEAR_LINE, res.Url)
 		redirectLocation := res.RedirectLocation
 		if redirectLocation != "" {
@@ -427,6 +740,9 @@ func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 	if res.ResultFile != "" {
 		reslines = fmt.Sprintf("%s%s| RES | %s\n", reslines, TERMINAL_CLEAR_LINE, res.ResultFile)
 	}
+	if res.Seed != "" {
+		reslines = fmt.Sprintf("%s%s| SED | %s\n", reslines, TERMINAL_CLEAR_LINE, res.Seed)
+	}
 	for _, k := range s.fuzzkeywords {
 		if ffuf.StrInSlice(k, s.config.CommandKeywords) {
 			// If we're using external command for input, display the position instead of input
@@ -463,6 +779,9 @@ func (s *Stdoutput) resultNormal(res ffuf.Result) {
 			leftPart = res.Url
 		}
 	}
+	if res.Seed != "" {
+		leftPart = fmt.Sprintf("%s (seed: %s)", leftPart, res.Seed)
+	}
 	
 	// Gestione allineamento intelligente
 	const maxLeftWidth = 80  // Larghezza massima per la parte sinistra
@@ -0,0 +1,338 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+const (
+	WEBHOOK_SEVERITY_INFO   = "info"
+	WEBHOOK_SEVERITY_LOW    = "low"
+	WEBHOOK_SEVERITY_MEDIUM = "medium"
+	WEBHOOK_SEVERITY_HIGH   = "high"
+
+	WEBHOOK_MATCH = "match"
+)
+
+var (
+	// webhookFlushInterval is the longest time a notification waits for its batch to fill up
+	webhookFlushInterval = 5 * time.Second
+	// webhookRetryDelay is the delay before the first retry of a failed delivery, doubled for each retry
+	webhookRetryDelay = time.Second
+)
+
+// WebhookNotification is a match or a job event sent to the webhook
+type WebhookNotification struct {
+	Type     string         `json:"type"`
+	Severity string         `json:"severity"`
+	Time     time.Time      `json:"time"`
+	Message  string         `json:"message"`
+	Result   *JsonResult    `json:"result,omitempty"`
+	Event    *ffuf.JobEvent `json:"event,omitempty"`
+}
+
+// webhookBody is the default JSON body of the webhook requests, and the data the body templates are executed with
+type webhookBody struct {
+	CommandLine   string                `json:"commandline"`
+	Version       string                `json:"version"`
+	Notifications []WebhookNotification `json:"notifications"`
+}
+
+// WebhookOutput sends the matches and the job events to an HTTP webhook. The notifications below the configured
+// severity are dropped, and the rest are sent in batches by a background goroutine, retrying failed deliveries.
+type WebhookOutput struct {
+	config      *ffuf.Config
+	client      *http.Client
+	template    *texttemplate.Template
+	minSeverity int
+	queue       chan WebhookNotification
+	done        chan struct{}
+	lock        sync.Mutex
+	closed      bool
+	dropped     int
+	// the delivery errors have their own lock, the deliveries don't wait for the notifications being queued
+	errLock sync.Mutex
+	errs    ffuf.Multierror
+}
+
+// NewWebhookOutput returns an output provider sending the notifications to the webhook URL of the config, and
+// starts the goroutine delivering them
+func NewWebhookOutput(conf *ffuf.Config) *WebhookOutput {
+	w := &WebhookOutput{
+		config:      conf,
+		client:      &http.Client{Timeout: time.Duration(conf.Timeout) * time.Second},
+		minSeverity: webhookSeverityLevel(conf.WebhookSeverity),
+		queue:       make(chan WebhookNotification, 1024),
+		done:        make(chan struct{}),
+	}
+	if conf.WebhookTemplate != "" {
+		// the template has been validated before the job is started
+		w.template, _ = loadWebhookTemplate(conf.WebhookTemplate)
+	}
+	go w.deliver()
+	return w
+}
+
+// loadWebhookTemplate reads and parses a webhook body template. The body templates are always text templates,
+// with the same helper functions as the output file templates.
+func loadWebhookTemplate(templatePath string) (*texttemplate.Template, error) {
+	tmpl, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	return texttemplate.New(filepath.Base(templatePath)).Funcs(templateFuncs).Parse(string(tmpl))
+}
+
+// ValidateWebhookTemplate checks that the webhook body template can be read and parsed
+func ValidateWebhookTemplate(templatePath string) error {
+	_, err := loadWebhookTemplate(templatePath)
+	return err
+}
+
+// webhookSeverityLevel returns the rank of the severity, higher being more severe
+func webhookSeverityLevel(severity string) int {
+	for i, s := range ffuf.WebhookSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return 0
+}
+
+// resultSeverity rates a match: successful responses with scraper hits are high, other successful responses
+// medium, and the rest low
+func resultSeverity(r ffuf.Result) string {
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return WEBHOOK_SEVERITY_LOW
+	}
+	for _, v := range r.ScraperData {
+		if len(v) > 0 {
+			return WEBHOOK_SEVERITY_HIGH
+		}
+	}
+	return WEBHOOK_SEVERITY_MEDIUM
+}
+
+// eventSeverity rates a job event: aborting the scan is high, aborting a single queued job medium, and
+// finishing one info
+func eventSeverity(event ffuf.JobEvent) string {
+	switch event.Type {
+	case ffuf.EVENT_SCAN_ABORTED:
+		return WEBHOOK_SEVERITY_HIGH
+	case ffuf.EVENT_JOB_ABORTED:
+		return WEBHOOK_SEVERITY_MEDIUM
+	}
+	return WEBHOOK_SEVERITY_INFO
+}
+
+func eventMessage(event ffuf.JobEvent) string {
+	switch event.Type {
+	case ffuf.EVENT_JOB_FINISHED:
+		return fmt.Sprintf("Job %d/%d finished: %s", event.QueuePos, event.QueueTotal, event.Url)
+	case ffuf.EVENT_JOB_ABORTED:
+		return fmt.Sprintf("Job %d/%d aborted: %s: %s", event.QueuePos, event.QueueTotal, event.Url, event.Reason)
+	case ffuf.EVENT_SCAN_FINISHED:
+		return fmt.Sprintf("Scan finished after %d requests", event.Requests)
+	case ffuf.EVENT_SCAN_ABORTED:
+		return fmt.Sprintf("Scan aborted after %d requests: %s", event.Requests, event.Reason)
+	}
+	return event.Type
+}
+
+// notify queues the notification for delivery if it is severe enough. The notification is dropped if the queue
+// is full, a failing webhook must not stall the scan.
+func (w *WebhookOutput) notify(n WebhookNotification) {
+	if webhookSeverityLevel(n.Severity) < w.minSeverity {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- n:
+	default:
+		w.dropped++
+	}
+}
+
+// deliver sends the queued notifications in batches until the queue is closed
+func (w *WebhookOutput) deliver() {
+	defer close(w.done)
+	batchSize := w.config.WebhookBatch
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	ticker := time.NewTicker(webhookFlushInterval)
+	defer ticker.Stop()
+	batch := make([]WebhookNotification, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.send(batch); err != nil {
+			w.errLock.Lock()
+			w.errs.Add(err)
+			w.errLock.Unlock()
+		}
+		batch = make([]WebhookNotification, 0, batchSize)
+	}
+	for {
+		select {
+		case n, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, n)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send posts a batch of notifications to the webhook, retrying with an exponential backoff
+func (w *WebhookOutput) send(batch []WebhookNotification) error {
+	body := webhookBody{
+		CommandLine:   w.config.CommandLine,
+		Version:       ffuf.Version(),
+		Notifications: batch,
+	}
+	var payload []byte
+	if w.template != nil {
+		var buf bytes.Buffer
+		if err := w.template.Execute(&buf, body); err != nil {
+			return fmt.Errorf("Could not render the webhook body: %s", err)
+		}
+		payload = buf.Bytes()
+	} else {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("Could not encode the webhook body: %s", err)
+		}
+	}
+	var err error
+	delay := webhookRetryDelay
+	for attempt := 0; attempt <= w.config.WebhookRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = w.post(payload); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("Could not deliver %d notifications to the webhook after %d attempts: %s", len(batch), w.config.WebhookRetries+1, err)
+}
+
+func (w *WebhookOutput) post(payload []byte) error {
+	req, err := http.NewRequest("POST", w.config.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Fuffa/"+ffuf.Version())
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *WebhookOutput) Banner() {}
+
+// Finalize sends the remaining notifications and waits for the deliveries to finish
+func (w *WebhookOutput) Finalize() error {
+	w.lock.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	dropped := w.dropped
+	w.lock.Unlock()
+	<-w.done
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	if dropped > 0 {
+		w.errs.Add(fmt.Errorf("Dropped %d webhook notifications, the delivery queue was full", dropped))
+	}
+	return w.errs.ErrorOrNil()
+}
+
+func (w *WebhookOutput) Progress(status ffuf.Progress) {}
+func (w *WebhookOutput) Info(infostring string)        {}
+func (w *WebhookOutput) Error(errstring string)        {}
+func (w *WebhookOutput) Raw(output string)             {}
+func (w *WebhookOutput) Warning(warnstring string)     {}
+
+// Result notifies the webhook of a match
+func (w *WebhookOutput) Result(resp ffuf.Response) {
+	r := ffuf.Result{
+		Input:            resp.Request.Input,
+		Position:         resp.Request.Position,
+		StatusCode:       resp.StatusCode,
+		ContentLength:    resp.ContentLength,
+		ContentWords:     resp.ContentWords,
+		ContentLines:     resp.ContentLines,
+		ContentType:      resp.ContentType,
+		RedirectLocation: resp.GetRedirectLocation(false),
+		ScraperData:      resp.ScraperData,
+		Seed:             resp.Seed,
+		Url:              resp.Request.Url,
+		Duration:         resp.Duration,
+		Host:             resp.Request.Host,
+	}
+	jr := toJsonResult(r)
+	w.notify(WebhookNotification{
+		Type:     WEBHOOK_MATCH,
+		Severity: resultSeverity(r),
+		Time:     time.Now(),
+		Message:  fmt.Sprintf("[Status: %d, Size: %d, Words: %d, Lines: %d] %s", r.StatusCode, r.ContentLength, r.ContentWords, r.ContentLines, r.Url),
+		Result:   &jr,
+	})
+}
+
+func (w *WebhookOutput) PrintResult(res ffuf.Result) {}
+
+// Event notifies the webhook of a job finishing or getting aborted
+func (w *WebhookOutput) Event(event ffuf.JobEvent) {
+	w.notify(WebhookNotification{
+		Type:     event.Type,
+		Severity: eventSeverity(event),
+		Time:     event.Time,
+		Message:  eventMessage(event),
+		Event:    &event,
+	})
+}
+
+// SaveFile is a no-op, the files are saved by the primary output
+func (w *WebhookOutput) SaveFile(filename, format string) error {
+	return nil
+}
+
+func (w *WebhookOutput) GetCurrentResults() []ffuf.Result {
+	return []ffuf.Result{}
+}
+
+func (w *WebhookOutput) SetCurrentResults(results []ffuf.Result) {}
+func (w *WebhookOutput) Reset()                                  {}
+func (w *WebhookOutput) Cycle()                                  {}
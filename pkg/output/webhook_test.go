@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// webhookListener records the bodies of the webhook requests, failing the first failures requests
+type webhookListener struct {
+	lock     sync.Mutex
+	failures int
+	bodies   []string
+}
+
+func (l *webhookListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.failures > 0 {
+		l.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	l.bodies = append(l.bodies, string(body))
+}
+
+func webhookTestResponse(url string, status int64, scraper map[string][]string) ffuf.Response {
+	req := ffuf.NewRequest(&ffuf.Config{})
+	req.Url = url
+	req.Input = map[string][]byte{"FUZZ": []byte(url)}
+	return ffuf.Response{StatusCode: status, ContentLength: 10, ScraperData: scraper, Request: &req}
+}
+
+func TestWebhookOutput(t *testing.T) {
+	webhookRetryDelay = time.Millisecond
+	listener := &webhookListener{failures: 1}
+	srv := httptest.NewServer(listener)
+	defer srv.Close()
+
+	conf := ffuf.NewConfig(nil, nil)
+	conf.Webhook = srv.URL
+	conf.WebhookBatch = 2
+	conf.WebhookSeverity = WEBHOOK_SEVERITY_MEDIUM
+	w := NewWebhookOutput(&conf)
+	w.Result(webhookTestResponse("http://example.com/notfound", 404, nil))
+	w.Result(webhookTestResponse("http://example.com/admin", 200, map[string][]string{"title": {"Admin"}}))
+	w.Event(ffuf.JobEvent{Type: ffuf.EVENT_JOB_FINISHED, Url: "http://example.com/FUZZ"})
+	w.Event(ffuf.JobEvent{Type: ffuf.EVENT_SCAN_ABORTED, Reason: "Getting an unusual amount of 403 responses, exiting."})
+	if err := w.Finalize(); err != nil {
+		t.Fatalf("Unexpected webhook error: %s", err)
+	}
+	if len(listener.bodies) != 1 {
+		t.Fatalf("Expected a single batch to be delivered, got %d", len(listener.bodies))
+	}
+	var body webhookBody
+	if err := json.Unmarshal([]byte(listener.bodies[0]), &body); err != nil {
+		t.Fatalf("Could not parse the webhook body: %s", err)
+	}
+	if len(body.Notifications) != 2 {
+		t.Fatalf("Expected the notifications below the severity to be dropped, got %+v", body.Notifications)
+	}
+	if n := body.Notifications[0]; n.Type != WEBHOOK_MATCH || n.Severity != WEBHOOK_SEVERITY_HIGH || n.Result.Url != "http://example.com/admin" {
+		t.Errorf("Unexpected match notification: %+v", n)
+	}
+	if n := body.Notifications[1]; n.Type != ffuf.EVENT_SCAN_ABORTED || n.Event == nil || !strings.Contains(n.Message, "unusual amount of 403") {
+		t.Errorf("Unexpected event notification: %+v", n)
+	}
+}
+
+func TestWebhookOutputTemplate(t *testing.T) {
+	listener := &webhookListener{}
+	srv := httptest.NewServer(listener)
+	defer srv.Close()
+
+	tmpl := filepath.Join(t.TempDir(), "slack.tmpl")
+	os.WriteFile(tmpl, []byte(`{"text": {{ range .Notifications }}{{ json .Message }}{{ end }}}`), 0644)
+	if err := ValidateWebhookTemplate(tmpl); err != nil {
+		t.Fatalf("Could not parse the webhook template: %s", err)
+	}
+	conf := ffuf.NewConfig(nil, nil)
+	conf.Webhook = srv.URL
+	conf.WebhookTemplate = tmpl
+	w := NewWebhookOutput(&conf)
+	w.Event(ffuf.JobEvent{Type: ffuf.EVENT_SCAN_FINISHED, Requests: 42})
+	if err := w.Finalize(); err != nil {
+		t.Fatalf("Unexpected webhook error: %s", err)
+	}
+	if len(listener.bodies) != 1 || listener.bodies[0] != `{"text": "Scan finished after 42 requests"}` {
+		t.Errorf("Unexpected webhook bodies: %v", listener.bodies)
+	}
+}
+
+func TestWebhookOutputRetries(t *testing.T) {
+	webhookRetryDelay = time.Millisecond
+	listener := &webhookListener{failures: 10}
+	srv := httptest.NewServer(listener)
+	defer srv.Close()
+
+	conf := ffuf.NewConfig(nil, nil)
+	conf.Webhook = srv.URL
+	conf.WebhookRetries = 2
+	w := NewWebhookOutput(&conf)
+	w.Event(ffuf.JobEvent{Type: ffuf.EVENT_SCAN_FINISHED})
+	err := w.Finalize()
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("Expected the delivery to fail after 3 attempts, got: %v", err)
+	}
+	if listener.failures != 7 {
+		t.Errorf("Expected 3 requests to the webhook, got %d", 10-listener.failures)
+	}
+}
+
+func TestWebhookOutputFullQueue(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	conf := ffuf.NewConfig(nil, nil)
+	conf.Webhook = srv.URL
+	conf.WebhookRetries = 0
+	w := NewWebhookOutput(&conf)
+	// more notifications than the queue holds while the webhook hangs, the overflow is dropped instead of
+	// blocking the caller
+	queued := make(chan struct{})
+	go func() {
+		for i := 0; i < cap(w.queue)+100; i++ {
+			w.Event(ffuf.JobEvent{Type: ffuf.EVENT_JOB_FINISHED})
+		}
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("The webhook output blocks the caller with a full queue and a hanging webhook")
+	}
+	close(release)
+	err := w.Finalize()
+	if err == nil || !strings.Contains(err.Error(), "status 500") || !strings.Contains(err.Error(), "Dropped") {
+		t.Errorf("Expected the delivery errors and the dropped notifications, got: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// AuditReplayRunner serves the responses recorded in an audit log instead of sending the requests, to re-evaluate
+// the matchers, filters, scrapers and outputs of a scan offline
+type AuditReplayRunner struct {
+	prepare *SimpleRunner
+	// the correlation header and query parameter change between scans, and are left out of the keys
+	correlationHeader string
+	correlationParam  string
+	// responses are indexed by the method, URL, headers and body of their requests, and loosely without the
+	// headers, which may have changed or been redacted
+	responses      map[string]ffuf.Response
+	looseResponses map[string]ffuf.Response
+}
+
+// NewAuditReplayRunner returns a runner serving the recorded responses
+func NewAuditReplayRunner(conf *ffuf.Config, responses []ffuf.Response) ffuf.RunnerProvider {
+	r := &AuditReplayRunner{
+		prepare:           NewSimpleRunner(conf, false).(*SimpleRunner),
+		correlationHeader: conf.CorrelationHeader,
+		correlationParam:  conf.CorrelationParam,
+		responses:         make(map[string]ffuf.Response),
+		looseResponses:    make(map[string]ffuf.Response),
+	}
+	for _, resp := range responses {
+		if resp.Request == nil {
+			continue
+		}
+		recorded := resp.Request
+		if token := string(recorded.Input[ffuf.CSRF_KEYWORD]); token != "" {
+			// the pre-flight tokens are not fetched again, the replayed requests have the keyword in their place
+			reverted := revertKeyword(recorded, ffuf.CSRF_KEYWORD, token)
+			recorded = &reverted
+		}
+		// the first response of a request wins, like the first attempt that succeeded during the scan
+		if _, ok := r.responses[r.requestKey(recorded, true)]; !ok {
+			r.responses[r.requestKey(recorded, true)] = resp
+		}
+		if _, ok := r.looseResponses[r.requestKey(recorded, false)]; !ok {
+			r.looseResponses[r.requestKey(recorded, false)] = resp
+		}
+	}
+	return r
+}
+
+func (r *AuditReplayRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	return r.prepare.Prepare(input, basereq)
+}
+
+func (r *AuditReplayRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	resp, ok := r.responses[r.requestKey(req, true)]
+	if !ok {
+		resp, ok = r.looseResponses[r.requestKey(req, false)]
+	}
+	if !ok {
+		return ffuf.Response{}, fmt.Errorf("request not found in the audit log: %s %s", req.Method, req.Url)
+	}
+	// keep the recorded raw request and host for the outputs, the rest of the request is the one of this scan
+	req.Raw = resp.Request.Raw
+	req.Host = resp.Request.Host
+	req.Timestamp = resp.Request.Timestamp
+	resp.Request = req
+	return resp, nil
+}
+
+func (r *AuditReplayRunner) Dump(req *ffuf.Request) ([]byte, error) {
+	return r.prepare.Dump(req)
+}
+
+// revertKeyword returns a copy of a request with the keyword in place of its value
+func revertKeyword(req *ffuf.Request, keyword, value string) ffuf.Request {
+	reverted := ffuf.CopyRequest(req)
+	reverted.Url = strings.ReplaceAll(reverted.Url, value, keyword)
+	for name, v := range reverted.Headers {
+		reverted.Headers[name] = strings.ReplaceAll(v, value, keyword)
+	}
+	reverted.Data = []byte(strings.ReplaceAll(string(reverted.Data), value, keyword))
+	return reverted
+}
+
+// requestKey returns the key of a request in the recorded responses
+func (r *AuditReplayRunner) requestKey(req *ffuf.Request, withHeaders bool) string {
+	var b strings.Builder
+	reqUrl := req.Url
+	if r.correlationParam != "" {
+		reqUrl = removeQueryParam(reqUrl, r.correlationParam)
+	}
+	b.WriteString(req.Method + " " + reqUrl + "\n")
+	if withHeaders {
+		names := make([]string, 0, len(req.Headers))
+		for name := range req.Headers {
+			if name == r.correlationHeader {
+				continue
+			}
+			// the default User-Agent is added while executing the request, and contains the version
+			if name != "User-Agent" || !strings.HasPrefix(req.Headers[name], "FUFFA") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b.WriteString(name + ": " + req.Headers[name] + "\n")
+		}
+	}
+	b.WriteString("\n")
+	b.Write(req.Data)
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestAuditReplayRunner(t *testing.T) {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.Url = "http://example.com/FUZZ"
+	conf.Method = "GET"
+	recorded := &ffuf.Request{Method: "GET", Url: "http://example.com/admin", Raw: "GET /admin HTTP/1.1\r\n\r\n",
+		Headers: map[string]string{"User-Agent": "FUFFA v0", "X-Token": "[REDACTED]"}}
+	r := NewAuditReplayRunner(&conf, []ffuf.Response{{StatusCode: 200, Data: []byte("admin"), Request: recorded}})
+
+	base := ffuf.BaseRequest(&conf)
+	base.Headers = map[string]string{"X-Token": "secret"}
+	req, err := r.Prepare(map[string][]byte{"FUZZ": []byte("admin")}, &base)
+	if err != nil {
+		t.Fatalf("Error preparing the request: %s", err)
+	}
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("Expected the recorded response, got error: %s", err)
+	}
+	if resp.StatusCode != 200 || string(resp.Data) != "admin" {
+		t.Errorf("Expected the recorded response, got %d %q", resp.StatusCode, resp.Data)
+	}
+	if resp.Request != &req || string(resp.Request.Input["FUZZ"]) != "admin" || resp.Request.Raw != recorded.Raw {
+		t.Errorf("Expected the response of the replayed request with the recorded raw request, got %+v", resp.Request)
+	}
+
+	req, _ = r.Prepare(map[string][]byte{"FUZZ": []byte("missing")}, &base)
+	if _, err := r.Execute(&req); err == nil {
+		t.Errorf("Expected an error for a request missing from the audit log")
+	}
+}
+
+func TestAuditReplayRunnerCorrelation(t *testing.T) {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.Url = "http://example.com/FUZZ?a=1"
+	conf.Method = "GET"
+	conf.CorrelationHeader = "X-Fuffa-Id"
+	conf.CorrelationParam = "fuffa_id"
+	// the FUFFAHASHes of the recorded scan differ from the ones of the replay
+	recorded := &ffuf.Request{Method: "GET", Url: "http://example.com/admin?a=1&fuffa_id=v2old",
+		Headers: map[string]string{"X-Fuffa-Id": "v2old"}}
+	r := NewAuditReplayRunner(&conf, []ffuf.Response{{StatusCode: 200, Request: recorded}})
+
+	base := ffuf.BaseRequest(&conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte("v2new")}, &base)
+	if req.Url != "http://example.com/admin?a=1&fuffa_id=v2new" || req.Headers["X-Fuffa-Id"] != "v2new" {
+		t.Fatalf("Expected the correlation header and query parameter, got %s %v", req.Url, req.Headers)
+	}
+	if _, err := r.Execute(&req); err != nil {
+		t.Errorf("Expected the recorded response regardless of the correlation values, got error: %s", err)
+	}
+}
+
+func TestAuditReplayRunnerPreflightToken(t *testing.T) {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.Url = "http://example.com/login"
+	conf.Method = "POST"
+	conf.Data = "csrf=CSRFTOKEN&pass=FUZZ"
+	recorded := &ffuf.Request{Method: "POST", Url: "http://example.com/login", Data: []byte("csrf=a1b2&pass=admin"),
+		Input: map[string][]byte{"FUZZ": []byte("admin"), ffuf.CSRF_KEYWORD: []byte("a1b2")}}
+	r := NewAuditReplayRunner(&conf, []ffuf.Response{{StatusCode: 302, Request: recorded}})
+
+	base := ffuf.BaseRequest(&conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("admin")}, &base)
+	resp, err := r.Execute(&req)
+	if err != nil || resp.StatusCode != 302 {
+		t.Errorf("Expected the recorded response of the request with a pre-flight token, got %d, error: %v", resp.StatusCode, err)
+	}
+}
@@ -100,10 +100,64 @@ func (r *SimpleRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (
 		req.Data = []byte(strings.ReplaceAll(string(req.Data), keyword, string(inputitem)))
 	}
 
+	if hash, ok := input["FUFFAHASH"]; ok {
+		r.addCorrelation(&req, string(hash))
+	}
 	req.Input = input
 	return req, nil
 }
 
+// addCorrelation adds the FUFFAHASH of the request to the correlation header and query parameter, to find the
+// request back from the server logs with -search
+func (r *SimpleRunner) addCorrelation(req *ffuf.Request, hash string) {
+	if r.config.CorrelationHeader != "" {
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[r.config.CorrelationHeader] = hash
+	}
+	if r.config.CorrelationParam != "" {
+		req.Url = addQueryParam(req.Url, r.config.CorrelationParam, hash)
+	}
+}
+
+// addQueryParam appends a query parameter to an URL, keeping the rest of the URL as is
+func addQueryParam(rawurl, name, value string) string {
+	fragment := ""
+	if i := strings.Index(rawurl, "#"); i >= 0 {
+		rawurl, fragment = rawurl[:i], rawurl[i:]
+	}
+	sep := "&"
+	if !strings.Contains(rawurl, "?") {
+		sep = "?"
+	} else if strings.HasSuffix(rawurl, "?") || strings.HasSuffix(rawurl, "&") {
+		sep = ""
+	}
+	return rawurl + sep + url.QueryEscape(name) + "=" + url.QueryEscape(value) + fragment
+}
+
+// removeQueryParam removes a query parameter added by addQueryParam from an URL
+func removeQueryParam(rawurl, name string) string {
+	fragment := ""
+	if i := strings.Index(rawurl, "#"); i >= 0 {
+		rawurl, fragment = rawurl[:i], rawurl[i:]
+	}
+	i := strings.Index(rawurl, "?")
+	if i < 0 {
+		return rawurl + fragment
+	}
+	params := make([]string, 0)
+	for _, param := range strings.Split(rawurl[i+1:], "&") {
+		if !strings.HasPrefix(param, url.QueryEscape(name)+"=") {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return rawurl[:i] + fragment
+	}
+	return rawurl[:i+1] + strings.Join(params, "&") + fragment
+}
+
 // replaceKeywordInURL replaces keyword in URL while avoiding double slashes
 func (r *SimpleRunner) replaceKeywordInURL(url, keyword, replacement string) string {
 	result := strings.ReplaceAll(url, keyword, replacement)
@@ -111,6 +165,11 @@ func (r *SimpleRunner) replaceKeywordInURL(url, keyword, replacement string) str
 	return regexp.MustCompile(`([^:])/+`).ReplaceAllString(result, "$1/")
 }
 
+// retainRaw returns true if the raw request and response are needed by the output directory, audit log or HAR / WARC output
+func (r *SimpleRunner) retainRaw() bool {
+	return len(r.config.OutputDirectory) > 0 || len(r.config.AuditLog) > 0 || r.config.RawOutputNeeded()
+}
+
 func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
 	var httpreq *http.Request
 	var err error
@@ -156,7 +215,7 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
 		httpreq.Header.Set(k, v)
 	}
 
-	if len(r.config.OutputDirectory) > 0 || len(r.config.AuditLog) > 0 {
+	if r.retainRaw() {
 		rawreq, _ = httputil.DumpRequestOut(httpreq, true)
 		req.Raw = string(rawreq)
 	}
@@ -189,7 +248,7 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
 		}
 	}
 
-	if len(r.config.OutputDirectory) > 0 || len(r.config.AuditLog) > 0 {
+	if r.retainRaw() {
 		rawresp, _ := httputil.DumpResponse(httpresp, true)
 		resp.Request.Raw = string(rawreq)
 		resp.Raw = string(rawresp)
@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestPrepareCorrelation(t *testing.T) {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.Method = "GET"
+	conf.CorrelationHeader = "X-Fuffa-Id"
+	conf.CorrelationParam = "fuffa_id"
+	r := NewSimpleRunner(&conf, false)
+
+	tests := []struct {
+		url      string
+		expected string
+		removed  string
+	}{
+		{"http://example.com/FUZZ", "http://example.com/admin?fuffa_id=v2hash", "http://example.com/admin"},
+		{"http://example.com/?q=FUZZ", "http://example.com/?q=admin&fuffa_id=v2hash", "http://example.com/?q=admin"},
+		{"http://example.com/FUZZ?", "http://example.com/admin?fuffa_id=v2hash", "http://example.com/admin"},
+		{"http://example.com/FUZZ#top", "http://example.com/admin?fuffa_id=v2hash#top", "http://example.com/admin#top"},
+	}
+	for _, test := range tests {
+		base := ffuf.Request{Method: "GET", Url: test.url, Headers: map[string]string{}}
+		req, err := r.Prepare(map[string][]byte{"FUZZ": []byte("admin"), "FUFFAHASH": []byte("v2hash")}, &base)
+		if err != nil {
+			t.Fatalf("Error preparing the request: %s", err)
+		}
+		if req.Url != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, req.Url)
+		}
+		if req.Headers["X-Fuffa-Id"] != "v2hash" {
+			t.Errorf("Expected the correlation header, got %v", req.Headers)
+		}
+		if removed := removeQueryParam(req.Url, "fuffa_id"); removed != test.removed {
+			t.Errorf("Expected %s without the correlation parameter, got %s", test.removed, removed)
+		}
+	}
+
+	// requests without a FUFFAHASH, like the crawler ones, are left as is
+	base := ffuf.Request{Method: "GET", Url: "http://example.com/", Headers: map[string]string{}}
+	req, _ := r.Prepare(map[string][]byte{}, &base)
+	if req.Url != base.Url || req.Headers["X-Fuffa-Id"] != "" {
+		t.Errorf("Expected no correlation without a FUFFAHASH, got %s %v", req.Url, req.Headers)
+	}
+}
@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// RuleFilter is a FilterProvider that matches the responses where a scraper rule finds something
+type RuleFilter struct {
+	rule *ScraperRule
+}
+
+func NewRuleFilter(rule *ScraperRule) ffuf.FilterProvider {
+	return &RuleFilter{rule: rule}
+}
+
+func (f *RuleFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.rule.Name,
+	})
+}
+
+func (f *RuleFilter) Filter(response *ffuf.Response) (bool, error) {
+	return len(f.rule.results(response)) > 0, nil
+}
+
+func (f *RuleFilter) Repr() string {
+	return f.rule.Name
+}
+
+func (f *RuleFilter) ReprVerbose() string {
+	return fmt.Sprintf("Scraper rule: %s (%s)", f.rule.Name, f.rule.Type)
+}
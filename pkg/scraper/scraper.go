@@ -1,36 +1,61 @@
 package scraper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/Mascol9/fuffa/pkg/ffuf"
 
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
 	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
 )
 
+// ScraperRule is a single rule of a scraper group. Type is one of:
+//   - "regexp": Rule is a regular expression, all (sub)matches are returned. This is the default type.
+//   - "query": Rule is a CSS selector, text content of the matching elements is returned
+//   - "jsonpath": Rule is a JSONPath expression evaluated against a JSON response body
+//   - "xpath": Rule is an XPath expression evaluated against a HTML / XML response body
+//   - "header": Rule is a response header name, all values of the header are returned
+//
+// When MinEntropy is set, the values with Shannon entropy below it are discarded. For regexp rules the
+// entropy is calculated from the last submatch of each match, so the secret part can be captured in a group.
+//
+// Rules with "match" or "filter" actions take part in the matching like the -m* and -f* options. Such rules
+// are not executed as output rules unless they have other actions too.
 type ScraperRule struct {
-	Name         string `json:"name"`
-	Rule         string `json:"rule"`
-	Target       string `json:"target"`
-	compiledRule *regexp.Regexp
-	Type         string   `json:"type"`
-	OnlyMatched  bool     `json:"onlymatched"`
-	Action       []string `json:"action"`
+	Name             string `json:"name"`
+	Rule             string `json:"rule"`
+	Target           string `json:"target"`
+	compiledRule     *regexp.Regexp
+	compiledJSONPath gval.Evaluable
+	compiledXPath    *xpath.Expr
+	xpathMutex       sync.Mutex
+	Type             string   `json:"type"`
+	OnlyMatched      bool     `json:"onlymatched"`
+	Action           []string `json:"action"`
+	MinEntropy       float64  `json:"minentropy"`
 }
 
 type ScraperGroup struct {
-	Rules  []*ScraperRule `json:"rules"`
-	Name   string         `json:"groupname"`
-	Active bool           `json:"active"`
+	Rules   []*ScraperRule `json:"rules"`
+	Name    string         `json:"groupname"`
+	Active  bool           `json:"active"`
+	Version int            `json:"version"`
 }
 
 type Scraper struct {
-	Rules []*ScraperRule
+	Rules    []*ScraperRule
+	warnings []string
 }
 
 func readGroupFromFile(filename string) (ScraperGroup, error) {
@@ -62,13 +87,11 @@ func FromDir(dirname string, activestr string) (ffuf.Scraper, ffuf.Multierror) {
 			}
 			if (sg.Active && isActive("all", activegrps)) || isActive(sg.Name, activegrps) {
 				for _, r := range sg.Rules {
-					err = r.init()
+					err = scr.addRule(r, filepath.Join(dirname, filename.Name()))
 					if err != nil {
 						cerr := fmt.Errorf("%s : %s", filepath.Join(dirname, filename.Name()), err)
 						errs.Add(cerr)
-						continue
 					}
-					scr.Rules = append(scr.Rules, r)
 				}
 			}
 		}
@@ -83,33 +106,47 @@ func (s *Scraper) AppendFromFile(path string) error {
 		return err
 	}
 
+	errs := ffuf.NewMultierror()
 	for _, r := range sg.Rules {
-		err = r.init()
-		if err != nil {
-			continue
+		if err := s.addRule(r, path); err != nil {
+			errs.Add(err)
 		}
-		s.Rules = append(s.Rules, r)
 	}
 
-	return err
+	return errs.ErrorOrNil()
+}
+
+// addRule initializes a rule and adds it to the scraper. The rules of unknown types, which earlier versions loaded
+// without ever matching, are skipped with a warning instead of failing the whole scraper.
+func (s *Scraper) addRule(r *ScraperRule, source string) error {
+	if !validType(r.Type) {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s : rule \"%s\": unknown rule type \"%s\", the rule is skipped", source, r.Name, r.Type))
+		return nil
+	}
+	if err := r.init(); err != nil {
+		return err
+	}
+	s.Rules = append(s.Rules, r)
+	return nil
+}
+
+// Warnings returns the problems found while loading the rules that did not prevent loading the scraper
+func (s *Scraper) Warnings() []string {
+	return s.warnings
 }
 
 func (s *Scraper) Execute(resp *ffuf.Response, matched bool) []ffuf.ScraperResult {
 	res := make([]ffuf.ScraperResult, 0)
 	for _, rule := range s.Rules {
+		if !rule.hasOutputActions() {
+			// evaluated through the matcher manager only
+			continue
+		}
 		if !matched && rule.OnlyMatched {
 			// pass this rule as there was no match
 			continue
 		}
-		sourceData := ""
-		if rule.Target == "body" {
-			sourceData = string(resp.Data)
-		} else if rule.Target == "headers" {
-			sourceData = headerString(resp.Headers)
-		} else {
-			sourceData = headerString(resp.Headers) + string(resp.Data)
-		}
-		val := rule.Check(sourceData)
+		val := rule.results(resp)
 		if len(val) > 0 {
 			res = append(res, ffuf.ScraperResult{
 				Name:    rule.Name,
@@ -125,20 +162,135 @@ func (s *Scraper) Execute(resp *ffuf.Response, matched bool) []ffuf.ScraperResul
 // init initializes the scraper rule, and returns an error in case there's an error in the syntax
 func (r *ScraperRule) init() error {
 	var err error
-	if r.Type == "regexp" {
+	if r.Type == "" {
+		r.Type = "regexp"
+	}
+	switch r.Type {
+	case "regexp":
 		r.compiledRule, err = regexp.Compile(r.Rule)
-		if err != nil {
-			return err
+	case "query":
+		// goquery does not expose selector validation, invalid selectors simply match nothing
+	case "jsonpath":
+		if r.Target != "" && r.Target != "body" {
+			return fmt.Errorf("rule \"%s\": jsonpath rules can only target the response body", r.Name)
+		}
+		r.compiledJSONPath, err = jsonpath.New(r.Rule)
+	case "xpath":
+		if r.Target != "" && r.Target != "body" {
+			return fmt.Errorf("rule \"%s\": xpath rules can only target the response body", r.Name)
+		}
+		r.compiledXPath, err = xpath.Compile(r.Rule)
+	case "header":
+		if strings.TrimSpace(r.Rule) == "" {
+			return fmt.Errorf("rule \"%s\": header rules need a header name", r.Name)
+		}
+	default:
+		return fmt.Errorf("rule \"%s\": unknown rule type \"%s\"", r.Name, r.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("rule \"%s\": %s", r.Name, err)
+	}
+	for _, a := range r.Action {
+		if !validAction(a) {
+			return fmt.Errorf("rule \"%s\": unknown action \"%s\"", r.Name, a)
 		}
 	}
-	return err
+	return nil
+}
+
+// Matchers returns the rules with the match action as FilterProviders, keyed by "scraper:RULENAME"
+func (s *Scraper) Matchers() map[string]ffuf.FilterProvider {
+	return s.filterProviders("match")
+}
+
+// Filters returns the rules with the filter action as FilterProviders, keyed by "scraper:RULENAME"
+func (s *Scraper) Filters() map[string]ffuf.FilterProvider {
+	return s.filterProviders("filter")
+}
+
+func (s *Scraper) filterProviders(action string) map[string]ffuf.FilterProvider {
+	providers := make(map[string]ffuf.FilterProvider)
+	for _, rule := range s.Rules {
+		if ffuf.StrInSlice(action, rule.Action) {
+			providers["scraper:"+rule.Name] = NewRuleFilter(rule)
+		}
+	}
+	return providers
+}
+
+// hasOutputActions returns true if the rule has actions other than match and filter, or no actions at all
+func (r *ScraperRule) hasOutputActions() bool {
+	if len(r.Action) == 0 {
+		return true
+	}
+	for _, a := range r.Action {
+		if a != "match" && a != "filter" {
+			return true
+		}
+	}
+	return false
+}
+
+// validType checks that the rule type is either empty, "regexp", "query", "jsonpath", "xpath" or "header"
+func validType(ruleType string) bool {
+	switch ruleType {
+	case "", "regexp", "query", "jsonpath", "xpath", "header":
+		return true
+	}
+	return false
+}
+
+// validAction checks that the action is either "output", "summary", "match", "filter" or "feed:KEYWORD"
+func validAction(action string) bool {
+	switch action {
+	case "output", "summary", "match", "filter":
+		return true
+	}
+	if strings.HasPrefix(action, "feed:") {
+		return len(strings.TrimSpace(strings.TrimPrefix(action, "feed:"))) > 0
+	}
+	return false
+}
+
+// results returns the values the rule finds from the response, with the low entropy values discarded
+func (r *ScraperRule) results(resp *ffuf.Response) []string {
+	val := r.checkResponse(resp)
+	if r.Type != "regexp" {
+		// regexp rules score the entropy per match in checkRegexp
+		val = r.filterEntropy(val)
+	}
+	return val
+}
+
+// checkResponse picks the part of the response the rule is targeting and runs the check against it
+func (r *ScraperRule) checkResponse(resp *ffuf.Response) []string {
+	switch r.Type {
+	case "header":
+		return r.checkHeader(resp.Headers)
+	case "jsonpath", "xpath":
+		return r.Check(string(resp.Data))
+	}
+	sourceData := ""
+	if r.Target == "body" {
+		sourceData = string(resp.Data)
+	} else if r.Target == "headers" {
+		sourceData = headerString(resp.Headers)
+	} else {
+		sourceData = headerString(resp.Headers) + string(resp.Data)
+	}
+	return r.Check(sourceData)
 }
 
 func (r *ScraperRule) Check(data string) []string {
-	if r.Type == "regexp" {
+	switch r.Type {
+	case "regexp":
 		return r.checkRegexp(data)
-	} else if r.Type == "query" {
+	case "query":
 		return r.checkQuery(data)
+	case "jsonpath":
+		return r.checkJSONPath(data)
+	case "xpath":
+		return r.checkXPath(data)
 	}
 	return []string{}
 }
@@ -160,9 +312,114 @@ func (r *ScraperRule) checkRegexp(data string) []string {
 	if r.compiledRule != nil {
 		res := r.compiledRule.FindAllStringSubmatch(data, -1)
 		for _, grp := range res {
-			val = append(val, grp...)
+			// the rules with capture groups report the captured value, and the rest the whole match
+			found := grp[len(grp)-1]
+			if r.MinEntropy > 0 && shannonEntropy(found) < r.MinEntropy {
+				continue
+			}
+			val = append(val, found)
 		}
 		return val
 	}
 	return []string{}
 }
+
+func (r *ScraperRule) checkJSONPath(data string) []string {
+	if r.compiledJSONPath == nil {
+		return []string{}
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return []string{}
+	}
+	res, err := r.compiledJSONPath(context.Background(), doc)
+	if err != nil {
+		return []string{}
+	}
+	val := make([]string, 0)
+	// wildcards, recursive descent and filters return a slice of matches
+	if resslice, ok := res.([]interface{}); ok {
+		for _, v := range resslice {
+			val = appendJSONValue(val, v)
+		}
+		return val
+	}
+	return appendJSONValue(val, res)
+}
+
+// appendJSONValue appends a JSONPath result to the slice, strings are added as-is and other values as JSON.
+// Like with XPath, boolean results are only added when true, so the rule finds nothing for a false value.
+func appendJSONValue(val []string, v interface{}) []string {
+	switch tv := v.(type) {
+	case nil:
+		return val
+	case string:
+		return append(val, tv)
+	case bool:
+		if tv {
+			return append(val, "true")
+		}
+		return val
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return val
+	}
+	return append(val, string(j))
+}
+
+func (r *ScraperRule) checkXPath(data string) []string {
+	val := make([]string, 0)
+	if r.compiledXPath == nil {
+		return val
+	}
+	doc, err := htmlquery.Parse(strings.NewReader(data))
+	if err != nil {
+		return val
+	}
+	// compiled xpath expressions hold iterator state and are not safe for concurrent use
+	r.xpathMutex.Lock()
+	defer r.xpathMutex.Unlock()
+	switch res := r.compiledXPath.Evaluate(htmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		for res.MoveNext() {
+			val = append(val, res.Current().Value())
+		}
+	case string:
+		if res != "" {
+			val = append(val, res)
+		}
+	case float64:
+		val = append(val, strconv.FormatFloat(res, 'f', -1, 64))
+	case bool:
+		// only a true boolean result counts as a hit
+		if res {
+			val = append(val, "true")
+		}
+	}
+	return val
+}
+
+func (r *ScraperRule) checkHeader(headers map[string][]string) []string {
+	val := make([]string, 0)
+	for k, vslice := range headers {
+		if strings.EqualFold(k, strings.TrimSpace(r.Rule)) {
+			val = append(val, vslice...)
+		}
+	}
+	return val
+}
+
+// filterEntropy returns the values with Shannon entropy of at least MinEntropy
+func (r *ScraperRule) filterEntropy(values []string) []string {
+	if r.MinEntropy <= 0 {
+		return values
+	}
+	val := make([]string, 0, len(values))
+	for _, v := range values {
+		if shannonEntropy(v) >= r.MinEntropy {
+			val = append(val, v)
+		}
+	}
+	return val
+}
@@ -0,0 +1,199 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestScraperRuleInit(t *testing.T) {
+	for i, test := range []struct {
+		rule    *ScraperRule
+		wantErr bool
+	}{
+		{&ScraperRule{Name: "re", Type: "regexp", Rule: "a(b"}, true},
+		{&ScraperRule{Name: "jp", Type: "jsonpath", Rule: "$.users[*].name"}, false},
+		{&ScraperRule{Name: "jp", Type: "jsonpath", Rule: "$.users[*"}, true},
+		{&ScraperRule{Name: "jp", Type: "jsonpath", Rule: "$.a", Target: "headers"}, true},
+		{&ScraperRule{Name: "xp", Type: "xpath", Rule: "//a/@href"}, false},
+		{&ScraperRule{Name: "xp", Type: "xpath", Rule: "//a[@href"}, true},
+		{&ScraperRule{Name: "hdr", Type: "header", Rule: "Server"}, false},
+		{&ScraperRule{Name: "hdr", Type: "header", Rule: " "}, true},
+		{&ScraperRule{Name: "unknown", Type: "nope", Rule: "x"}, true},
+		{&ScraperRule{Name: "default", Rule: "x"}, false},
+		{&ScraperRule{Name: "feed", Type: "regexp", Rule: "x", Action: []string{"output", "feed:PARAM"}}, false},
+		{&ScraperRule{Name: "feed", Type: "regexp", Rule: "x", Action: []string{"feed:"}}, true},
+		{&ScraperRule{Name: "action", Type: "regexp", Rule: "x", Action: []string{"explode"}}, true},
+		{&ScraperRule{Name: "summary", Type: "regexp", Rule: "x", Action: []string{"output", "summary"}}, false},
+	} {
+		err := test.rule.init()
+		if (err != nil) != test.wantErr {
+			t.Errorf("Rule init test %d: expected error %t but got: %v", i, test.wantErr, err)
+		}
+	}
+}
+
+func TestScraperAppendFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	os.WriteFile(path, []byte(`{"groupname": "old", "active": true, "rules": [
+		{"name": "untyped", "rule": "token=([a-z]+)"},
+		{"name": "future", "type": "wasm", "rule": "x"}
+	]}`), 0644)
+	s := &Scraper{Rules: make([]*ScraperRule, 0)}
+	if err := s.AppendFromFile(path); err != nil {
+		t.Fatalf("Expected the scraper group of an earlier version to load, got: %s", err)
+	}
+	if len(s.Rules) != 1 || s.Rules[0].Type != "regexp" {
+		t.Errorf("Expected the rule without a type to default to regexp, got %+v", s.Rules)
+	}
+	if len(s.Warnings()) != 1 || !strings.Contains(s.Warnings()[0], `unknown rule type "wasm"`) {
+		t.Errorf("Expected a warning about the unknown rule type, got %v", s.Warnings())
+	}
+
+	// an invalid rule is reported even if it is followed by valid ones
+	os.WriteFile(path, []byte(`{"groupname": "broken", "active": true, "rules": [
+		{"name": "broken", "type": "jsonpath", "rule": "$.users[*"},
+		{"name": "valid", "type": "regexp", "rule": "token=([a-z]+)"}
+	]}`), 0644)
+	if err := s.AppendFromFile(path); err == nil {
+		t.Errorf("Expected an error for the invalid jsonpath rule")
+	}
+}
+
+func TestScraperRuleTypes(t *testing.T) {
+	resp := &ffuf.Response{
+		Headers: map[string][]string{"Server": {"nginx"}, "X-Powered-By": {"PHP/8.1"}},
+	}
+	for i, test := range []struct {
+		rule     *ScraperRule
+		data     string
+		expected []string
+	}{
+		{
+			&ScraperRule{Type: "jsonpath", Rule: "$.users[*].name"},
+			`{"users":[{"name":"alice"},{"name":"bob"}]}`,
+			[]string{"alice", "bob"},
+		},
+		{
+			&ScraperRule{Type: "jsonpath", Rule: "$.users[?(@.isAdmin == true)].id"},
+			`{"users":[{"id":1,"isAdmin":false},{"id":2,"isAdmin":true}]}`,
+			[]string{"2"},
+		},
+		{
+			&ScraperRule{Type: "jsonpath", Rule: "$.config"},
+			`{"config":{"debug":true}}`,
+			[]string{`{"debug":true}`},
+		},
+		{
+			&ScraperRule{Type: "jsonpath", Rule: "$.missing"},
+			`not json`,
+			[]string{},
+		},
+		{
+			&ScraperRule{Type: "xpath", Rule: "//a/@href"},
+			`<html><body><a href="/admin">x</a><a href="/login">y</a></body></html>`,
+			[]string{"/admin", "/login"},
+		},
+		{
+			&ScraperRule{Type: "xpath", Rule: "count(//input)"},
+			`<form><input name="a"><input name="b"></form>`,
+			[]string{"2"},
+		},
+		{
+			&ScraperRule{Type: "xpath", Rule: "boolean(//script)"},
+			`<p>no scripts here</p>`,
+			[]string{},
+		},
+		{
+			&ScraperRule{Type: "header", Rule: "x-powered-by"},
+			``,
+			[]string{"PHP/8.1"},
+		},
+	} {
+		if err := test.rule.init(); err != nil {
+			t.Fatalf("Rule type test %d: unexpected init error: %s", i, err)
+		}
+		resp.Data = []byte(test.data)
+		val := test.rule.checkResponse(resp)
+		if !reflect.DeepEqual(val, test.expected) {
+			t.Errorf("Rule type test %d: expected %v but got %v", i, test.expected, val)
+		}
+	}
+}
+
+func TestScraperMinEntropy(t *testing.T) {
+	data := `api_key = "aaaaaaaaaaaaaaaaaaaa"; api_key = "Zx9Qp2Lm7Vt4Rb8Kc1Nw"`
+	rule := &ScraperRule{Name: "key", Type: "regexp", Rule: `api_key = "([A-Za-z0-9]{20})"`, MinEntropy: 3.5}
+	if err := rule.init(); err != nil {
+		t.Fatalf("Rule init failed: %s", err)
+	}
+	res := rule.Check(data)
+	if !reflect.DeepEqual(res, []string{"Zx9Qp2Lm7Vt4Rb8Kc1Nw"}) {
+		t.Errorf("Expected only the high entropy match, got %v", res)
+	}
+
+	hdr := &ScraperRule{Name: "hdr", Type: "header", Rule: "X-Token", MinEntropy: 3.0}
+	if err := hdr.init(); err != nil {
+		t.Fatalf("Rule init failed: %s", err)
+	}
+	s := Scraper{Rules: []*ScraperRule{hdr}}
+	resp := &ffuf.Response{Headers: map[string][]string{"X-Token": {"aaaaaaaa", "f3Kz91QwXe"}}}
+	sres := s.Execute(resp, true)
+	if len(sres) != 1 || !reflect.DeepEqual(sres[0].Results, []string{"f3Kz91QwXe"}) {
+		t.Errorf("Expected the low entropy header value to be filtered, got %v", sres)
+	}
+}
+
+func TestShannonEntropy(t *testing.T) {
+	if e := shannonEntropy(""); e != 0 {
+		t.Errorf("Expected zero entropy for empty string, got %f", e)
+	}
+	if e := shannonEntropy("aaaa"); e != 0 {
+		t.Errorf("Expected zero entropy for a single repeated character, got %f", e)
+	}
+	if e := shannonEntropy("abcd"); e != 2 {
+		t.Errorf("Expected entropy of 2 for four distinct characters, got %f", e)
+	}
+}
+
+func TestScraperMatchFilterActions(t *testing.T) {
+	trace := &ScraperRule{Name: "trace", Type: "regexp", Rule: `Traceback \(most recent call last\)`, Action: []string{"match"}}
+	admin := &ScraperRule{Name: "admin", Type: "jsonpath", Rule: "$.isAdmin", Action: []string{"filter"}}
+	both := &ScraperRule{Name: "both", Type: "regexp", Rule: "x", Action: []string{"output", "match"}}
+	for _, r := range []*ScraperRule{trace, admin, both} {
+		if err := r.init(); err != nil {
+			t.Fatalf("Rule init failed: %s", err)
+		}
+	}
+	s := Scraper{Rules: []*ScraperRule{trace, admin, both}}
+
+	matchers := s.Matchers()
+	if len(matchers) != 2 || matchers["scraper:trace"] == nil || matchers["scraper:both"] == nil {
+		t.Errorf("Unexpected scraper matchers: %v", matchers)
+	}
+	filters := s.Filters()
+	if len(filters) != 1 || filters["scraper:admin"] == nil {
+		t.Fatalf("Unexpected scraper filters: %v", filters)
+	}
+
+	resp := &ffuf.Response{Data: []byte("Traceback (most recent call last):\n  x")}
+	if m, _ := matchers["scraper:trace"].Filter(resp); !m {
+		t.Errorf("Expected the trace rule to match")
+	}
+	if f, _ := filters["scraper:admin"].Filter(&ffuf.Response{Data: []byte(`{"isAdmin": true}`)}); !f {
+		t.Errorf("Expected the admin rule to filter a response with isAdmin true")
+	}
+	if f, _ := filters["scraper:admin"].Filter(&ffuf.Response{Data: []byte(`{"isAdmin": false}`)}); f {
+		t.Errorf("Expected the admin rule not to filter a response with isAdmin false")
+	}
+
+	// Rules with only match / filter actions are not executed as output rules
+	sres := s.Execute(resp, true)
+	if len(sres) != 1 || sres[0].Name != "both" {
+		t.Errorf("Expected only the rule with an output action to be executed, got %v", sres)
+	}
+}
@@ -3,6 +3,7 @@ package scraper
 import (
 	"fmt"
 	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"math"
 	"strings"
 )
 
@@ -27,3 +28,22 @@ func parseActiveGroups(activestr string) []string {
 	}
 	return retslice
 }
+
+// shannonEntropy returns the Shannon entropy of the string in bits per character
+func shannonEntropy(data string) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, c := range data {
+		counts[c]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"fmt"
+	"net/http/cookiejar"
+	"strings"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"github.com/Mascol9/fuffa/pkg/runner"
+)
+
+// Preflight fetches a fresh token, like the anti-CSRF token of a form, before each request. Every worker has its
+// own cookie jar, so the token is sent with the cookies it was issued with. A worker is held by a single request
+// from the pre-flight request until its response, which makes the pool safe for any number of threads.
+type Preflight struct {
+	req       ffuf.Request
+	extractor *Extractor
+	runner    ffuf.RunnerProvider
+	session   ffuf.SessionProvider
+	jars      []*cookiejar.Jar
+	free      chan int
+}
+
+// NewPreflight reads the pre-flight request of the config, with a worker for each thread. The session, if any,
+// is applied to the pre-flight requests too.
+func NewPreflight(conf *ffuf.Config, session ffuf.SessionProvider) (*Preflight, error) {
+	req, err := ffuf.ReadRawRequest(conf.PreflightFile, conf.RequestProto)
+	if err != nil {
+		return nil, fmt.Errorf("pre-flight request: %s", err)
+	}
+	extractor, err := ParseExtractor(ffuf.CSRF_KEYWORD, conf.PreflightExtract)
+	if err != nil {
+		return nil, fmt.Errorf("pre-flight extractor: %s", err)
+	}
+	// the pre-flight requests follow the redirects themselves to keep the cookies set on the way
+	preflightConf := *conf
+	preflightConf.FollowRedirects = false
+	preflightConf.IgnoreBody = false
+	preflightConf.DebugFirstRequest = false
+	workers := conf.Threads
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Preflight{
+		req:       req,
+		extractor: extractor,
+		runner:    runner.NewSimpleRunner(&preflightConf, false),
+		session:   session,
+		jars:      make([]*cookiejar.Jar, workers),
+		free:      make(chan int, workers),
+	}
+	for i := range p.jars {
+		p.jars[i], _ = cookiejar.New(nil)
+		p.free <- i
+	}
+	return p, nil
+}
+
+// ParseExtractor parses a TYPE:RULE extractor definition, the definitions without a known type being regexps
+func ParseExtractor(name, definition string) (*Extractor, error) {
+	e := &Extractor{Name: name, Type: "regexp", Rule: definition}
+	if parts := strings.SplitN(definition, ":", 2); len(parts) == 2 {
+		switch parts[0] {
+		case "regexp", "jsonpath", "header", "cookie":
+			e.Type, e.Rule = parts[0], parts[1]
+		}
+	}
+	if e.Rule == "" {
+		return nil, fmt.Errorf("no rule to extract %s with", name)
+	}
+	return e, e.init()
+}
+
+// Fetch waits for a free worker, sends the pre-flight request with the cookies of the worker and returns the
+// extracted token. The input keywords are replaced in the pre-flight request too. The worker must be released
+// with Release.
+func (p *Preflight) Fetch(input map[string][]byte) (int, []byte, error) {
+	worker := <-p.free
+	req, err := p.runner.Prepare(input, &p.req)
+	if err != nil {
+		p.free <- worker
+		return 0, nil, err
+	}
+	if p.session != nil {
+		p.session.Apply(&req)
+	}
+	resp, err := send(p.runner, req, p.jars[worker])
+	if err != nil {
+		p.free <- worker
+		return 0, nil, fmt.Errorf("pre-flight request failed: %s", err)
+	}
+	token, ok := p.extractor.extract(&resp)
+	if !ok {
+		p.free <- worker
+		return 0, nil, fmt.Errorf("could not extract %s from the pre-flight response (status %d)", ffuf.CSRF_KEYWORD, resp.StatusCode)
+	}
+	return worker, []byte(token), nil
+}
+
+// Apply adds the cookies of a worker to a prepared request
+func (p *Preflight) Apply(worker int, req *ffuf.Request) {
+	addCookies(p.jars[worker], req)
+}
+
+// Release stores the cookies set by the response of the request, if any, and frees the worker
+func (p *Preflight) Release(worker int, resp *ffuf.Response) {
+	if resp != nil {
+		storeCookies(p.jars[worker], resp)
+	}
+	p.free <- worker
+}
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// csrfServer issues a single-use token bound to the cookie of the client on each form page
+type csrfServer struct {
+	mutex   sync.Mutex
+	clients int
+	tokens  map[string]string
+}
+
+func (c *csrfServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cookie, err := r.Cookie("client")
+	client := ""
+	if err == nil {
+		client = cookie.Value
+	}
+	if r.Method == "GET" {
+		if client == "" {
+			c.clients++
+			client = fmt.Sprintf("c%d", c.clients)
+			http.SetCookie(w, &http.Cookie{Name: "client", Value: client})
+		}
+		token := fmt.Sprintf("%s-%d", client, len(c.tokens))
+		c.tokens[client] = token
+		fmt.Fprintf(w, `<form><input type="hidden" name="csrf" value="%s"></form>`, token)
+		return
+	}
+	r.ParseForm()
+	if client == "" || r.Form.Get("csrf") != c.tokens[client] {
+		w.WriteHeader(403)
+		return
+	}
+	delete(c.tokens, client)
+	fmt.Fprint(w, "ok")
+}
+
+func TestPreflight(t *testing.T) {
+	server := &csrfServer{tokens: make(map[string]string)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	dir := t.TempDir()
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.RequestProto = "http"
+	conf.Threads = 4
+	conf.PreflightFile = filepath.Join(dir, "form.txt")
+	conf.PreflightExtract = `name="csrf" value="([^"]+)"`
+	os.WriteFile(conf.PreflightFile, []byte("GET /form?user=FUZZ HTTP/1.1\nHost: "+strings.TrimPrefix(ts.URL, "http://")+"\n\n"), 0644)
+	p, err := NewPreflight(&conf, nil)
+	if err != nil {
+		t.Fatalf("Could not read the pre-flight request: %s", err)
+	}
+	r := p.runner
+
+	var wg sync.WaitGroup
+	var failures sync.Map
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := map[string][]byte{"FUZZ": []byte(fmt.Sprintf("u%d", i))}
+			worker, token, err := p.Fetch(input)
+			if err != nil {
+				failures.Store(i, err.Error())
+				return
+			}
+			input[ffuf.CSRF_KEYWORD] = token
+			base := ffuf.Request{Method: "POST", Url: ts.URL + "/login", Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, Data: []byte("csrf=CSRFTOKEN&user=FUZZ")}
+			req, _ := r.Prepare(input, &base)
+			p.Apply(worker, &req)
+			resp, err := r.Execute(&req)
+			p.Release(worker, &resp)
+			if err != nil || resp.StatusCode != 200 {
+				failures.Store(i, fmt.Sprintf("status %d, error %v", resp.StatusCode, err))
+			}
+		}(i)
+	}
+	wg.Wait()
+	failures.Range(func(k, v interface{}) bool {
+		t.Errorf("Request %v failed: %v", k, v)
+		return true
+	})
+	// every worker keeps its own cookie jar during the whole scan
+	if server.clients == 0 || server.clients > conf.Threads {
+		t.Errorf("Expected at most a client cookie for each of the %d workers, got %d", conf.Threads, server.clients)
+	}
+}
+
+func TestParseExtractor(t *testing.T) {
+	tests := []struct {
+		definition string
+		kind       string
+		rule       string
+		err        bool
+	}{
+		{`name="csrf" value="([^"]+)"`, "regexp", `name="csrf" value="([^"]+)"`, false},
+		{`jsonpath:$.token`, "jsonpath", "$.token", false},
+		{`header:X-Csrf-Token`, "header", "X-Csrf-Token", false},
+		{`cookie:csrftoken`, "cookie", "csrftoken", false},
+		{`token:([a-f0-9]+)`, "regexp", "token:([a-f0-9]+)", false},
+		{`regexp:(`, "", "", true},
+		{`header:`, "", "", true},
+	}
+	for _, test := range tests {
+		e, err := ParseExtractor("X", test.definition)
+		if test.err {
+			if err == nil {
+				t.Errorf("Expected an error for %s", test.definition)
+			}
+			continue
+		}
+		if err != nil || e.Type != test.kind || e.Rule != test.rule {
+			t.Errorf("Expected %s rule %s for %s, got %+v, error: %v", test.kind, test.rule, test.definition, e, err)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"github.com/Mascol9/fuffa/pkg/runner"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// maxMacroRedirects is the number of the redirects followed by a login macro step
+const maxMacroRedirects = 10
+
+// variablePattern matches the {{NAME}} references to the extracted session variables
+var variablePattern = regexp.MustCompile(`{{([A-Za-z0-9_]+)}}`)
+
+// Macro is the login sequence of a session file. The extracted variables are referenced as {{NAME}} in the later
+// steps, in the session headers and in the fuzzed request.
+type Macro struct {
+	Steps   []*Step           `json:"steps"`
+	Headers map[string]string `json:"headers"`
+	Logout  Logout            `json:"logout"`
+}
+
+// Step is a request of the login sequence, read from a raw request file relative to the session file
+type Step struct {
+	Request string       `json:"request"`
+	Proto   string       `json:"proto"`
+	Extract []*Extractor `json:"extract"`
+	req     ffuf.Request
+}
+
+// Extractor extracts a session variable from the response of a step. Type is one of:
+//   - "regexp": Rule is a regular expression matched against the response body, the first submatch is extracted
+//     if the expression has one, and the whole match otherwise
+//   - "jsonpath": Rule is a JSONPath expression evaluated against a JSON response body
+//   - "header": Rule is a response header name
+//   - "cookie": Rule is the name of a cookie set by the response
+type Extractor struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Rule         string `json:"rule"`
+	compiledRule *regexp.Regexp
+	compiledPath gval.Evaluable
+}
+
+// Logout tells the responses of an expired session apart: a status code of the list, a redirect to a location
+// matching the Location expression, or a body matching the Regexp expression. A 401 or a redirect to a login page
+// by default.
+type Logout struct {
+	Status           []int64 `json:"status"`
+	Location         string  `json:"location"`
+	Regexp           string  `json:"regexp"`
+	compiledLocation *regexp.Regexp
+	compiledRegexp   *regexp.Regexp
+}
+
+// Session keeps the scan authenticated. The cookies of the responses are kept in a cookie jar, and added to every
+// request together with the session headers. The methods are safe for concurrent use.
+type Session struct {
+	macro  Macro
+	runner ffuf.RunnerProvider
+	// mutex protects the cookie jar pointer, the variables and the generation, loginMutex serializes the logins
+	mutex      sync.RWMutex
+	loginMutex sync.Mutex
+	jar        *cookiejar.Jar
+	variables  map[string]string
+	generation int
+}
+
+// NewSession reads the session file of the config
+func NewSession(conf *ffuf.Config) (*Session, error) {
+	data, err := os.ReadFile(conf.SessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the session file: %s", err)
+	}
+	var macro Macro
+	if err := json.Unmarshal(data, &macro); err != nil {
+		return nil, fmt.Errorf("could not parse the session file %s: %s", conf.SessionFile, err)
+	}
+	if len(macro.Steps) == 0 {
+		return nil, fmt.Errorf("the session file %s has no login steps", conf.SessionFile)
+	}
+	dir := filepath.Dir(conf.SessionFile)
+	for i, step := range macro.Steps {
+		proto := step.Proto
+		if proto == "" {
+			proto = conf.RequestProto
+		}
+		filename := step.Request
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filename)
+		}
+		step.req, err = ffuf.ReadRawRequest(filename, proto)
+		if err != nil {
+			return nil, fmt.Errorf("session login step %d: %s", i+1, err)
+		}
+		for _, e := range step.Extract {
+			if err := e.init(); err != nil {
+				return nil, fmt.Errorf("session login step %d: %s", i+1, err)
+			}
+		}
+	}
+	if err := macro.Logout.init(); err != nil {
+		return nil, err
+	}
+	// the login requests follow the redirects themselves to keep the cookies set on the way
+	macroConf := *conf
+	macroConf.FollowRedirects = false
+	macroConf.IgnoreBody = false
+	macroConf.DebugFirstRequest = false
+	jar, _ := cookiejar.New(nil)
+	return &Session{
+		macro:     macro,
+		runner:    runner.NewSimpleRunner(&macroConf, false),
+		jar:       jar,
+		variables: make(map[string]string),
+	}, nil
+}
+
+func (e *Extractor) init() error {
+	if e.Name == "" {
+		return fmt.Errorf("an extractor has no name")
+	}
+	var err error
+	switch e.Type {
+	case "regexp":
+		e.compiledRule, err = regexp.Compile(e.Rule)
+	case "jsonpath":
+		e.compiledPath, err = jsonpath.New(e.Rule)
+	case "header", "cookie":
+		if e.Rule == "" {
+			err = fmt.Errorf("no %s name", e.Type)
+		}
+	default:
+		err = fmt.Errorf("unknown type \"%s\"", e.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("bad extractor %s: %s", e.Name, err)
+	}
+	return nil
+}
+
+func (l *Logout) init() error {
+	var err error
+	if len(l.Status) == 0 && l.Location == "" && l.Regexp == "" {
+		l.Status = []int64{401}
+		l.Location = `(?i)login`
+	}
+	if l.Location != "" {
+		if l.compiledLocation, err = regexp.Compile(l.Location); err != nil {
+			return fmt.Errorf("bad session logout location: %s", err)
+		}
+	}
+	if l.Regexp != "" {
+		if l.compiledRegexp, err = regexp.Compile(l.Regexp); err != nil {
+			return fmt.Errorf("bad session logout regexp: %s", err)
+		}
+	}
+	return nil
+}
+
+// Login runs the login macro with a new cookie jar. A login of an older generation than the current one is skipped,
+// as the session has already been renewed by another worker since. The requests keep using the previous session
+// until the macro has succeeded, the cookie jar and the variables being replaced together.
+func (s *Session) Login(generation int) error {
+	s.loginMutex.Lock()
+	defer s.loginMutex.Unlock()
+	s.mutex.RLock()
+	current := s.generation
+	s.mutex.RUnlock()
+	if generation != current {
+		return nil
+	}
+	jar, _ := cookiejar.New(nil)
+	variables := make(map[string]string)
+	for i, step := range s.macro.Steps {
+		resp, err := s.runStep(step, jar, variables)
+		if err != nil {
+			return fmt.Errorf("session login step %d: %s", i+1, err)
+		}
+		for _, e := range step.Extract {
+			value, ok := e.extract(&resp)
+			if !ok {
+				return fmt.Errorf("session login step %d: could not extract %s from the response", i+1, e.Name)
+			}
+			variables[e.Name] = value
+		}
+	}
+	s.mutex.Lock()
+	s.jar = jar
+	s.variables = variables
+	s.generation++
+	s.mutex.Unlock()
+	return nil
+}
+
+// runStep sends the request of a login step with the cookie jar of the login, following the redirects
+func (s *Session) runStep(step *Step, jar *cookiejar.Jar, variables map[string]string) (ffuf.Response, error) {
+	req := ffuf.CopyRequest(&step.req)
+	expandRequest(&req, variables)
+	return send(s.runner, req, jar)
+}
+
+// send sends a request with the cookies of a jar, following the redirects and storing the cookies set on the way
+func send(r ffuf.RunnerProvider, req ffuf.Request, jar *cookiejar.Jar) (ffuf.Response, error) {
+	for redirects := 0; ; redirects++ {
+		addCookies(jar, &req)
+		resp, err := r.Execute(&req)
+		if err != nil {
+			return resp, err
+		}
+		storeCookies(jar, &resp)
+		if resp.GetRedirectLocation(false) == "" || redirects == maxMacroRedirects {
+			return resp, nil
+		}
+		location := resp.GetRedirectLocation(true)
+		next := ffuf.Request{Method: "GET", Url: location, Headers: make(map[string]string)}
+		if resp.StatusCode == 307 || resp.StatusCode == 308 {
+			next.Method = req.Method
+			next.Data = req.Data
+		}
+		for name, value := range req.Headers {
+			if name != "Cookie" && name != "Content-Length" && (name != "Content-Type" || len(next.Data) > 0) {
+				next.Headers[name] = value
+			}
+		}
+		if u, err := url.Parse(location); err == nil {
+			next.Headers["Host"] = u.Host
+		}
+		req = next
+	}
+}
+
+// Apply adds the session cookies and headers to a prepared request and expands the session variables in it. It
+// returns the generation of the session, for the login after a logout.
+func (s *Session) Apply(req *ffuf.Request) int {
+	s.mutex.RLock()
+	jar := s.jar
+	variables := s.variables
+	generation := s.generation
+	s.mutex.RUnlock()
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	for name, value := range s.macro.Headers {
+		req.Headers[name] = value
+	}
+	expandRequest(req, variables)
+	addCookies(jar, req)
+	return generation
+}
+
+// Check stores the cookies set by a response to a request of the generation, unless the session has been renewed
+// since, and returns true if the response shows that the session has expired
+func (s *Session) Check(resp *ffuf.Response, generation int) bool {
+	s.mutex.RLock()
+	if generation == s.generation {
+		storeCookies(s.jar, resp)
+	}
+	s.mutex.RUnlock()
+	l := s.macro.Logout
+	for _, status := range l.Status {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	if l.compiledLocation != nil {
+		if location := resp.GetRedirectLocation(false); location != "" && l.compiledLocation.MatchString(location) {
+			return true
+		}
+	}
+	return l.compiledRegexp != nil && l.compiledRegexp.Match(resp.Data)
+}
+
+// addCookies adds the cookies of a jar to the Cookie header of a request, replacing the ones with the same name
+func addCookies(jar *cookiejar.Jar, req *ffuf.Request) {
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return
+	}
+	cookies := jar.Cookies(u)
+	if len(cookies) == 0 {
+		return
+	}
+	names := make(map[string]bool)
+	parts := make([]string, 0)
+	for _, c := range cookies {
+		names[c.Name] = true
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	if existing, ok := req.Headers["Cookie"]; ok {
+		kept := make([]string, 0)
+		for _, part := range strings.Split(existing, ";") {
+			part = strings.TrimSpace(part)
+			if part != "" && !names[strings.SplitN(part, "=", 2)[0]] {
+				kept = append(kept, part)
+			}
+		}
+		parts = append(kept, parts...)
+	}
+	req.Headers["Cookie"] = strings.Join(parts, "; ")
+}
+
+// storeCookies stores the cookies set by a response in a jar
+func storeCookies(jar *cookiejar.Jar, resp *ffuf.Response) {
+	if resp.Request == nil || len(resp.Headers["Set-Cookie"]) == 0 {
+		return
+	}
+	u, err := url.Parse(resp.Request.Url)
+	if err != nil {
+		return
+	}
+	cookies := (&http.Response{Header: http.Header{"Set-Cookie": resp.Headers["Set-Cookie"]}}).Cookies()
+	jar.SetCookies(u, cookies)
+}
+
+// extract returns the value of the extractor in a response
+func (e *Extractor) extract(resp *ffuf.Response) (string, bool) {
+	switch e.Type {
+	case "regexp":
+		m := e.compiledRule.FindSubmatch(resp.Data)
+		if m == nil {
+			return "", false
+		}
+		return string(m[len(m)-1]), true
+	case "jsonpath":
+		var data interface{}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return "", false
+		}
+		value, err := e.compiledPath(context.Background(), data)
+		if err != nil || value == nil {
+			return "", false
+		}
+		if s, ok := value.(string); ok {
+			return s, true
+		}
+		b, err := json.Marshal(value)
+		return string(b), err == nil
+	case "header":
+		values := http.Header(resp.Headers).Values(e.Rule)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	case "cookie":
+		for _, c := range (&http.Response{Header: http.Header{"Set-Cookie": resp.Headers["Set-Cookie"]}}).Cookies() {
+			if c.Name == e.Rule {
+				return c.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// expandRequest replaces the {{NAME}} references to the session variables in a request
+func expandRequest(req *ffuf.Request, variables map[string]string) {
+	expand := func(s string) string {
+		if !strings.Contains(s, "{{") {
+			return s
+		}
+		return variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			if value, ok := variables[ref[2:len(ref)-2]]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+	req.Url = expand(req.Url)
+	for name, value := range req.Headers {
+		req.Headers[name] = expand(value)
+	}
+	req.Data = []byte(expand(string(req.Data)))
+}
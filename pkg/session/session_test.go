@@ -0,0 +1,208 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// loginServer issues a session cookie and a token on login, and expires the session after the given requests
+type loginServer struct {
+	mutex    sync.Mutex
+	logins   int
+	requests int
+	expireAt int
+	// loginStarted and loginBlock hold the logins until the test lets them through, if set
+	loginStarted chan struct{}
+	loginBlock   chan struct{}
+}
+
+func (l *loginServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	switch r.URL.Path {
+	case "/login":
+		if r.Method == "GET" {
+			fmt.Fprint(w, `<input name="csrf" value="c5rf">`)
+			return
+		}
+		if l.loginBlock != nil {
+			l.loginStarted <- struct{}{}
+			<-l.loginBlock
+		}
+		r.ParseForm()
+		if r.Form.Get("csrf") != "c5rf" {
+			w.WriteHeader(403)
+			return
+		}
+		l.logins++
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: fmt.Sprintf("s%d", l.logins)})
+		http.Redirect(w, r, "/welcome", http.StatusFound)
+	case "/welcome":
+		fmt.Fprintf(w, `{"token": "t%d"}`, l.logins)
+	default:
+		l.requests++
+		c, err := r.Cookie("sid")
+		valid := err == nil && c.Value == fmt.Sprintf("s%d", l.logins) && r.Header.Get("Authorization") == fmt.Sprintf("Bearer t%d", l.logins)
+		if !valid || l.requests == l.expireAt {
+			l.logins++
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "secret")
+	}
+}
+
+func writeSessionFile(t *testing.T, host string) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"page.txt":  "GET /login HTTP/1.1\nHost: " + host + "\n\n",
+		"login.txt": "POST /login HTTP/1.1\nHost: " + host + "\nContent-Type: application/x-www-form-urlencoded\n\ncsrf={{CSRF}}&user=admin\n",
+		"session.json": `{
+			"steps": [
+				{"request": "page.txt", "extract": [{"name": "CSRF", "type": "regexp", "rule": "name=\"csrf\" value=\"([^\"]+)\""}]},
+				{"request": "login.txt", "extract": [{"name": "TOKEN", "type": "jsonpath", "rule": "$.token"}]}
+			],
+			"headers": {"Authorization": "Bearer {{TOKEN}}"}
+		}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "session.json")
+}
+
+func TestSession(t *testing.T) {
+	server := &loginServer{expireAt: 2}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.RequestProto = "http"
+	conf.SessionFile = writeSessionFile(t, strings.TrimPrefix(ts.URL, "http://"))
+	s, err := NewSession(&conf)
+	if err != nil {
+		t.Fatalf("Could not read the session file: %s", err)
+	}
+	if err := s.Login(0); err != nil {
+		t.Fatalf("Could not log in: %s", err)
+	}
+
+	send := func() (ffuf.Response, int) {
+		req := ffuf.Request{Method: "GET", Url: ts.URL + "/admin", Headers: map[string]string{"Cookie": "lang=en"}}
+		generation := s.Apply(&req)
+		resp, err := s.runner.Execute(&req)
+		if err != nil {
+			t.Fatalf("Request failed: %s", err)
+		}
+		return resp, generation
+	}
+	resp, generation := send()
+	if s.Check(&resp, generation) || string(resp.Data) != "secret" {
+		t.Fatalf("Expected an authenticated response, got %d %q", resp.StatusCode, resp.Data)
+	}
+	if cookie := resp.Request.Headers["Cookie"]; cookie != "lang=en; sid=s1" {
+		t.Errorf("Expected the session cookie after the cookies of the request, got %q", cookie)
+	}
+
+	// the server expires the session on the second request
+	resp, generation = send()
+	if !s.Check(&resp, generation) {
+		t.Fatalf("Expected the redirect to the login page to be detected as a logout, got %d", resp.StatusCode)
+	}
+	if err := s.Login(generation); err != nil {
+		t.Fatalf("Could not log in again: %s", err)
+	}
+	// a worker that saw the same expired session does not log in again
+	if err := s.Login(generation); err != nil || server.logins != 3 {
+		t.Errorf("Expected a single login for the expired session, got %d logins, error: %v", server.logins, err)
+	}
+	resp, generation = send()
+	if s.Check(&resp, generation) || string(resp.Data) != "secret" {
+		t.Errorf("Expected an authenticated response after the login, got %d %q", resp.StatusCode, resp.Data)
+	}
+}
+
+func TestSessionRenewal(t *testing.T) {
+	server := &loginServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.RequestProto = "http"
+	conf.SessionFile = writeSessionFile(t, strings.TrimPrefix(ts.URL, "http://"))
+	s, err := NewSession(&conf)
+	if err != nil {
+		t.Fatalf("Could not read the session file: %s", err)
+	}
+	if err := s.Login(0); err != nil {
+		t.Fatalf("Could not log in: %s", err)
+	}
+
+	server.mutex.Lock()
+	server.loginStarted = make(chan struct{})
+	server.loginBlock = make(chan struct{})
+	server.mutex.Unlock()
+	done := make(chan error)
+	go func() {
+		done <- s.Login(1)
+	}()
+	<-server.loginStarted
+	// the requests keep the previous session while the macro runs
+	req := ffuf.Request{Method: "GET", Url: ts.URL + "/admin", Headers: map[string]string{}}
+	generation := s.Apply(&req)
+	if generation != 1 || req.Headers["Cookie"] != "sid=s1" || req.Headers["Authorization"] != "Bearer t1" {
+		t.Errorf("Expected the previous session during the login, got generation %d and headers %v", generation, req.Headers)
+	}
+	close(server.loginBlock)
+	if err := <-done; err != nil {
+		t.Fatalf("Could not log in again: %s", err)
+	}
+	// a late response of the previous session does not change the cookies of the new one
+	late := ffuf.Response{StatusCode: 401, Headers: map[string][]string{"Set-Cookie": {"sid=deleted"}}, Request: &req}
+	if !s.Check(&late, generation) {
+		t.Errorf("Expected the late response to be detected as a logout")
+	}
+	req = ffuf.Request{Method: "GET", Url: ts.URL + "/admin", Headers: map[string]string{}}
+	if generation := s.Apply(&req); generation != 2 || req.Headers["Cookie"] != "sid=s2" || req.Headers["Authorization"] != "Bearer t2" {
+		t.Errorf("Expected the new session after the login, got generation %d and headers %v", generation, req.Headers)
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	ts := httptest.NewServer(&loginServer{})
+	defer ts.Close()
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.RequestProto = "http"
+	conf.SessionFile = writeSessionFile(t, strings.TrimPrefix(ts.URL, "http://"))
+
+	for _, content := range []string{
+		`{"steps": []}`,
+		`{"steps": [{"request": "missing.txt"}]}`,
+		`{"steps": [{"request": "page.txt", "extract": [{"name": "X", "type": "unknown"}]}]}`,
+		`{"steps": [{"request": "page.txt"}], "logout": {"regexp": "("}}`,
+	} {
+		os.WriteFile(conf.SessionFile, []byte(content), 0644)
+		if _, err := NewSession(&conf); err == nil {
+			t.Errorf("Expected an error for the session file %s", content)
+		}
+	}
+
+	// the extraction fails if the login page has no token
+	os.WriteFile(conf.SessionFile, []byte(`{"steps": [{"request": "page.txt", "extract": [{"name": "X", "type": "regexp", "rule": "missing"}]}]}`), 0644)
+	s, err := NewSession(&conf)
+	if err != nil {
+		t.Fatalf("Could not read the session file: %s", err)
+	}
+	if err := s.Login(0); err == nil || !strings.Contains(err.Error(), "could not extract X") {
+		t.Errorf("Expected an extraction error, got %v", err)
+	}
+}
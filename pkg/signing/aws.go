@@ -0,0 +1,155 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// AWSSigner signs the requests with AWS Signature Version 4, with the credentials of the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables
+type AWSSigner struct {
+	region       string
+	service      string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	now          func() time.Time
+}
+
+// NewAWSSigner returns a SigV4 signer of a REGION/SERVICE scope, the service being execute-api of API Gateway
+// by default
+func NewAWSSigner(scope string) (*AWSSigner, error) {
+	parts := strings.SplitN(scope, "/", 2)
+	s := &AWSSigner{
+		region:       parts[0],
+		service:      "execute-api",
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		now:          time.Now,
+	}
+	if len(parts) == 2 {
+		s.service = parts[1]
+	}
+	if s.region == "" || s.service == "" {
+		return nil, fmt.Errorf("the AWS signing scope %s is not REGION/SERVICE", scope)
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("AWS signing needs the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	}
+	return s, nil
+}
+
+// Sign adds the X-Amz-Date, the session token if any, and the Authorization headers to the request. The host,
+// the Content-Type and the X-Amz-* headers are signed.
+func (s *AWSSigner) Sign(req *ffuf.Request) error {
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return err
+	}
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(req.Data)
+	setHeader(req, "X-Amz-Date", amzDate)
+	if s.sessionToken != "" {
+		setHeader(req, "X-Amz-Security-Token", s.sessionToken)
+	}
+	if s.service == "s3" {
+		setHeader(req, "X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := map[string]string{"host": requestHost(req, u)}
+	for name, value := range req.Headers {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.Join(strings.Fields(value), " ")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := requestPath(req.Url)
+	if s.service != "s3" {
+		// the paths are escaped twice, except for S3
+		path = awsEscape(path, false)
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(u),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	credentialScope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + credentialScope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{date, s.region, s.service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	setHeader(req, "Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, credentialScope, signedHeaders, signature))
+	return nil
+}
+
+// canonicalQuery returns the query parameters sorted by name and value, escaped the AWS way
+func canonicalQuery(u *url.URL) string {
+	params := make([][2]string, 0)
+	for name, values := range u.Query() {
+		for _, value := range values {
+			params = append(params, [2]string{awsEscape(name, true), awsEscape(value, true)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+	encoded := make([]string, 0, len(params))
+	for _, param := range params {
+		encoded = append(encoded, param[0]+"="+param[1])
+	}
+	return strings.Join(encoded, "&")
+}
+
+// awsEscape percent-encodes everything but the unreserved characters, and the slashes unless escapeSlash is true
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
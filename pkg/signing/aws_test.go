@@ -0,0 +1,74 @@
+package signing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func newTestAWSSigner(t *testing.T, scope string) *AWSSigner {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	s, err := NewAWSSigner(scope)
+	if err != nil {
+		t.Fatalf("Could not create the signer: %s", err)
+	}
+	s.now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+	return s
+}
+
+func TestAWSSigner(t *testing.T) {
+	// the requests of the AWS SigV4 test suite
+	s := newTestAWSSigner(t, "us-east-1/service")
+	tests := []struct {
+		url       string
+		signature string
+	}{
+		{"https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+	for _, test := range tests {
+		req := ffuf.Request{Method: "GET", Url: test.url, Headers: map[string]string{}}
+		if err := s.Sign(&req); err != nil {
+			t.Fatalf("Could not sign the request: %s", err)
+		}
+		expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=" + test.signature
+		if req.Headers["Authorization"] != expected {
+			t.Errorf("Unexpected signature of %s: %s", test.url, req.Headers["Authorization"])
+		}
+		if req.Headers["X-Amz-Date"] != "20150830T123600Z" {
+			t.Errorf("Unexpected X-Amz-Date header: %s", req.Headers["X-Amz-Date"])
+		}
+	}
+}
+
+func TestAWSSignerHeaders(t *testing.T) {
+	s := newTestAWSSigner(t, "eu-west-1")
+	s.sessionToken = "token"
+	req := ffuf.Request{Method: "POST", Url: "https://api.example.org/prod/items", Data: []byte(`{"id":1}`), Headers: map[string]string{
+		"Content-Type":  "application/json",
+		"authorization": "Bearer old",
+		"X-Custom":      "not signed",
+	}}
+	if err := s.Sign(&req); err != nil {
+		t.Fatalf("Could not sign the request: %s", err)
+	}
+	if _, ok := req.Headers["authorization"]; ok {
+		t.Errorf("Expected the previous authorization header to be replaced")
+	}
+	auth := req.Headers["Authorization"]
+	if !strings.Contains(auth, "/eu-west-1/execute-api/aws4_request") || !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Unexpected authorization header: %s", auth)
+	}
+	if req.Headers["X-Amz-Security-Token"] != "token" {
+		t.Errorf("Expected the session token header, got %v", req.Headers)
+	}
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := NewAWSSigner("us-east-1"); err == nil {
+		t.Errorf("Expected an error without the AWS credentials")
+	}
+}
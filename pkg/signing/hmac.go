@@ -0,0 +1,140 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// hmacAlgorithms are the hash functions of the HMAC signatures
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// signingVariable matches the {{NAME}} and {{HEADER:Name}} variables of the templates
+var signingVariable = regexp.MustCompile(`\{\{([A-Z0-9_]+|HEADER:[^{}]+)\}\}`)
+
+// HMACSigner signs the requests with the HMAC of a canonical string, built from a template with the {{METHOD}},
+// {{URL}}, {{HOST}}, {{PATH}}, {{QUERY}}, {{BODY}}, {{BODY_SHA256}} and {{HEADER:Name}} variables of the request.
+// The {{TIMESTAMP}}, {{TIMESTAMP_MS}}, {{DATE}} and {{NONCE}} variables are generated for each request, and are
+// replaced in the request too, to send them along.
+type HMACSigner struct {
+	hash     func() hash.Hash
+	key      []byte
+	template string
+	// the signature is set to the header with the value template, {{SIGNATURE}} by default
+	header string
+	value  string
+	now    func() time.Time
+	nonce  func() string
+}
+
+// NewHMACSigner returns a HMAC signer. The header is either a header name, or NAME: VALUE with a value template
+// containing {{SIGNATURE}} or {{SIGNATURE_BASE64}}.
+func NewHMACSigner(algorithm, key, template, header string) (*HMACSigner, error) {
+	h, ok := hmacAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return nil, fmt.Errorf("unknown HMAC algorithm %s, expected sha1, sha256, sha384 or sha512", algorithm)
+	}
+	k, err := parseKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("HMAC key: %s", err)
+	}
+	s := &HMACSigner{
+		hash: h,
+		key:  k,
+		// the line breaks are escaped on the command line
+		template: strings.ReplaceAll(template, `\n`, "\n"),
+		header:   header,
+		value:    "{{SIGNATURE}}",
+		now:      time.Now,
+		nonce:    randomNonce,
+	}
+	if parts := strings.SplitN(header, ":", 2); len(parts) == 2 {
+		s.header = strings.TrimSpace(parts[0])
+		s.value = strings.TrimSpace(parts[1])
+	}
+	return s, nil
+}
+
+// Sign replaces the generated variables in the request, and sets the signature header
+func (s *HMACSigner) Sign(req *ffuf.Request) error {
+	t := s.now()
+	vars := map[string]string{
+		"TIMESTAMP":    strconv.FormatInt(t.Unix(), 10),
+		"TIMESTAMP_MS": strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10),
+		"DATE":         t.UTC().Format(http.TimeFormat),
+		"NONCE":        s.nonce(),
+	}
+	req.Url = expandVariables(req.Url, vars, nil)
+	for name, value := range req.Headers {
+		req.Headers[name] = expandVariables(value, vars, nil)
+	}
+	req.Data = []byte(expandVariables(string(req.Data), vars, nil))
+
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return err
+	}
+	vars["METHOD"] = req.Method
+	vars["URL"] = req.Url
+	vars["HOST"] = requestHost(req, u)
+	vars["PATH"] = requestPath(req.Url)
+	vars["QUERY"] = u.RawQuery
+	vars["BODY"] = string(req.Data)
+	vars["BODY_SHA256"] = sha256Hex(req.Data)
+
+	mac := hmac.New(s.hash, s.key)
+	mac.Write([]byte(expandVariables(s.template, vars, req)))
+	sum := mac.Sum(nil)
+	vars["SIGNATURE"] = hex.EncodeToString(sum)
+	vars["SIGNATURE_BASE64"] = base64.StdEncoding.EncodeToString(sum)
+	setHeader(req, s.header, expandVariables(s.value, vars, req))
+	return nil
+}
+
+// expandVariables replaces the known variables of a template, and the header variables if the request is given.
+// The other variables are left as they are.
+func expandVariables(template string, vars map[string]string, req *ffuf.Request) string {
+	if !strings.Contains(template, "{{") {
+		return template
+	}
+	return signingVariable.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[2 : len(match)-2]
+		if strings.HasPrefix(name, "HEADER:") {
+			if req == nil {
+				return match
+			}
+			value, _ := getHeader(req, strings.TrimPrefix(name, "HEADER:"))
+			return value
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// randomNonce returns 16 random bytes, hex encoded
+func randomNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
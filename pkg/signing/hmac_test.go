@@ -0,0 +1,72 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+func TestHMACSigner(t *testing.T) {
+	s, err := NewHMACSigner("SHA256", "s3cr3t", `{{METHOD}}\n{{PATH}}\n{{QUERY}}\n{{HEADER:x-timestamp}}\n{{NONCE}}\n{{BODY_SHA256}}`, "Authorization: HMAC partner:{{SIGNATURE_BASE64}}")
+	if err != nil {
+		t.Fatalf("Could not create the signer: %s", err)
+	}
+	s.now = func() time.Time { return time.Unix(1700000000, 0) }
+	s.nonce = func() string { return "n0nce" }
+	req := ffuf.Request{Method: "POST", Url: "https://api.example.org/v1/users?id=FUZZ", Data: []byte("{}"), Headers: map[string]string{
+		"X-Timestamp": "{{TIMESTAMP}}",
+		"X-Nonce":     "{{NONCE}}",
+		"X-Other":     "{{UNKNOWN}}",
+	}}
+	if err := s.Sign(&req); err != nil {
+		t.Fatalf("Could not sign the request: %s", err)
+	}
+	if req.Headers["X-Timestamp"] != "1700000000" || req.Headers["X-Nonce"] != "n0nce" || req.Headers["X-Other"] != "{{UNKNOWN}}" {
+		t.Errorf("Unexpected variables in the request headers: %v", req.Headers)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte("POST\n/v1/users\nid=FUZZ\n1700000000\nn0nce\n" + sha256Hex([]byte("{}"))))
+	expected := "HMAC partner:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if req.Headers["Authorization"] != expected {
+		t.Errorf("Expected the signature header %s, got %s", expected, req.Headers["Authorization"])
+	}
+}
+
+func TestHMACSignerKeys(t *testing.T) {
+	t.Setenv("FUFFA_TEST_HMAC_KEY", "from-env")
+	for definition, key := range map[string]string{
+		"plain":                   "plain",
+		"env:FUFFA_TEST_HMAC_KEY": "from-env",
+		"hex:6b6579":              "key",
+		"base64:a2V5":             "key",
+	} {
+		s, err := NewHMACSigner("sha1", definition, "{{PATH}}", "X-Signature")
+		if err != nil {
+			t.Errorf("Could not create the signer of the key %s: %s", definition, err)
+			continue
+		}
+		if string(s.key) != key {
+			t.Errorf("Expected the key %s of %s, got %s", key, definition, s.key)
+		}
+		req := ffuf.Request{Method: "GET", Url: "http://example.org", Headers: map[string]string{}}
+		s.Sign(&req)
+		mac := hmac.New(hmacAlgorithms["sha1"], []byte(key))
+		mac.Write([]byte("/"))
+		if req.Headers["X-Signature"] != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Unexpected signature with the key %s: %s", definition, req.Headers["X-Signature"])
+		}
+	}
+	for _, definition := range []string{"env:FUFFA_TEST_MISSING_KEY", "hex:zz"} {
+		if _, err := NewHMACSigner("sha256", definition, "{{PATH}}", "X-Signature"); err == nil {
+			t.Errorf("Expected an error of the key %s", definition)
+		}
+	}
+	if _, err := NewHMACSigner("md4", "key", "{{PATH}}", "X-Signature"); err == nil {
+		t.Errorf("Expected an error of an unknown algorithm")
+	}
+}
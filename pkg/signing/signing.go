@@ -0,0 +1,94 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+)
+
+// NewSigner returns the request signer of the config, AWS SigV4 or HMAC
+func NewSigner(conf *ffuf.Config) (ffuf.SignerProvider, error) {
+	if conf.SignAWS != "" {
+		return NewAWSSigner(conf.SignAWS)
+	}
+	return NewHMACSigner(conf.SignHMAC, conf.SignHMACKey, conf.SignHMACTemplate, conf.SignHMACHeader)
+}
+
+// setHeader sets a header of the request, replacing the headers of the same name in any case
+func setHeader(req *ffuf.Request, name, value string) {
+	for k := range req.Headers {
+		if strings.EqualFold(k, name) {
+			delete(req.Headers, k)
+		}
+	}
+	req.Headers[name] = value
+}
+
+// getHeader returns the value of a header of the request, in any case
+func getHeader(req *ffuf.Request, name string) (string, bool) {
+	for k, v := range req.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// requestHost returns the host the request is sent to, the Host header overriding the host of the URL
+func requestHost(req *ffuf.Request, u *url.URL) string {
+	if host, ok := getHeader(req, "Host"); ok {
+		return host
+	}
+	return u.Host
+}
+
+// requestPath returns the path of the request URL as it is written, and sent
+func requestPath(rawurl string) string {
+	path := rawurl
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+		if j := strings.IndexAny(path, "/?#"); j >= 0 {
+			path = path[j:]
+		} else {
+			path = ""
+		}
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// sha256Hex returns the hex encoded SHA256 hash of the data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseKey returns the key of a key definition: env:NAME reads it from an environment variable, hex: and base64:
+// decode it, and other values are the key itself
+func parseKey(definition string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(definition, "env:"):
+		name := strings.TrimPrefix(definition, "env:")
+		key := os.Getenv(name)
+		if key == "" {
+			return nil, fmt.Errorf("the environment variable %s of the key is not set", name)
+		}
+		return []byte(key), nil
+	case strings.HasPrefix(definition, "hex:"):
+		return hex.DecodeString(strings.TrimPrefix(definition, "hex:"))
+	case strings.HasPrefix(definition, "base64:"):
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(definition, "base64:"))
+	}
+	return []byte(definition), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Mascol9/fuffa/pkg/ffuf"
+	"github.com/Mascol9/fuffa/pkg/filter"
+	"github.com/Mascol9/fuffa/pkg/output"
+)
+
+// auditLogArgs splits the arguments of the replay-audit mode into the audit log files preceding the flags and the
+// remaining arguments
+func auditLogArgs(args []string) ([]string, []string) {
+	i := 0
+	for i < len(args) && !strings.HasPrefix(args[i], "-") {
+		i++
+	}
+	return args[:i], args[i:]
+}
+
+// applyAuditLog overlays the HTTP and input options of the audited scan on the options, so that the same requests
+// are generated again. The wordlists are replaced with the inputs recorded in the log, written to a temporary
+// directory that is removed by the returned cleanup function.
+func applyAuditLog(opts *ffuf.ConfigOptions, log *output.AuditLog) (func(), error) {
+	conf := log.Config
+	conf.MatcherManager = filter.NewMatcherManager()
+	audited := conf.ToOptions()
+	opts.HTTP = audited.HTTP
+	// the replayed responses must not be sent anywhere, and the raw request was already parsed to the config
+	opts.HTTP.ReplayProxyURL = ""
+	opts.HTTP.ProxyURL = ""
+	// the recorded requests already have the session cookies and headers
+	opts.HTTP.Session = ""
+	// the pre-flight tokens are not fetched again, the runner matches the recorded requests by the CSRFTOKEN keyword
+	opts.HTTP.Preflight = ""
+	opts.HTTP.PreflightExtract = ""
+	// the recorded requests already have their signatures, the runner matches them without the headers
+	opts.HTTP.SignAWS = ""
+	opts.HTTP.SignHMAC = ""
+	opts.Input = audited.Input
+	opts.Input.Request = ""
+	opts.Input.Inputcommands = []string{}
+	// the recorded inputs already have the extensions, encoders and dirsearch substitutions applied
+	opts.Input.Extensions = ""
+	opts.Input.Encoders = []string{}
+	opts.Input.DirSearchCompat = false
+	opts.Input.IgnoreWordlistComments = false
+	// the recorded requests were made without calibration, which would not find its responses in the log
+	opts.General.AutoCalibration = false
+	opts.General.AutoCalibrationStrings = []string{}
+	opts.General.AutoCalibrationStrategies = []string{}
+
+	dir, err := os.MkdirTemp("", "fuffa-replay-audit")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	inputs := log.Inputs()
+	opts.Input.Wordlists = []string{}
+	for i, provider := range conf.InputProviders {
+		values, ok := inputs[provider.Keyword]
+		if !ok {
+			cleanup()
+			return nil, fmt.Errorf("the audit log has no recorded inputs for the keyword %s", provider.Keyword)
+		}
+		lines := make([]string, 0, len(values))
+		for _, v := range values {
+			lines = append(lines, string(v))
+		}
+		wordlist := filepath.Join(dir, fmt.Sprintf("wordlist-%d", i))
+		if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			cleanup()
+			return nil, err
+		}
+		if conf.InputMode == "sniper" {
+			opts.Input.Wordlists = append(opts.Input.Wordlists, wordlist)
+		} else {
+			opts.Input.Wordlists = append(opts.Input.Wordlists, wordlist+":"+provider.Keyword)
+		}
+	}
+	return cleanup, nil
+}
+
+// auditLogRedacted returns true if the audit log has requests with redacted URLs or bodies, which the replayed
+// requests may not be matched with
+func auditLogRedacted(log *output.AuditLog) bool {
+	requests := append([]ffuf.Request{}, log.Requests...)
+	for _, resp := range log.Responses {
+		if resp.Request != nil {
+			requests = append(requests, *resp.Request)
+		}
+	}
+	for _, req := range requests {
+		if strings.Contains(req.Url, ffuf.REDACTED) || bytes.Contains(req.Data, []byte(ffuf.REDACTED)) {
+			return true
+		}
+	}
+	return false
+}
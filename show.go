@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Mascol9/fuffa/pkg/output"
+)
+
+// runShow implements the show subcommand, printing an entry of the evidence store of an output directory
+func runShow(args []string) int {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	outputDirectory := fs.String("od", ".", "Output directory the results were stored to with -od")
+	jsonOutput := fs.Bool("json", false, "Print the manifest entries as JSON instead of the request and response")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: fuffa show [-od directory] [-json] HASH\n\n")
+		fmt.Fprintf(os.Stderr, "Prints the stored request and response of a result by its FUFFAHASH or the id of its manifest entry.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+	entries, err := output.FindEvidence(*outputDirectory, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No stored result found for %s in %s\n", fs.Arg(0), *outputDirectory)
+		return 1
+	}
+	for _, entry := range entries {
+		if *jsonOutput {
+			line, _ := json.Marshal(entry)
+			fmt.Println(string(line))
+			continue
+		}
+		request, response, body, err := output.ReadEvidence(*outputDirectory, entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return 1
+		}
+		fmt.Printf("Id:           %s\n", entry.Id)
+		fmt.Printf("FUFFAHASH:    %s\n", entry.FuffaHash)
+		fmt.Printf("URL:          %s %s\n", entry.Method, entry.Url)
+		fmt.Printf("Status:       %d, Size: %d, Words: %d, Lines: %d\n", entry.StatusCode, entry.ContentLength, entry.ContentWords, entry.ContentLines)
+		fmt.Printf("Content-Type: %s\n", entry.ContentType)
+		fmt.Printf("Time:         %s\n", entry.Time.Format("2006-01-02 15:04:05"))
+		keys := make([]string, 0, len(entry.Input))
+		for k := range entry.Input {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("Input %s: %s\n", k, entry.Input[k])
+		}
+		fmt.Printf("\n---- Request (%s) ----\n%s", entry.Request, request)
+		fmt.Printf("\n---- Response (%s, %s) ----\n%s", entry.Response, entry.Body, response)
+		os.Stdout.Write(body)
+		fmt.Printf("\n")
+	}
+	return 0
+}